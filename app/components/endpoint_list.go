@@ -1,20 +1,33 @@
 package components
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Brattlof/localpulse/monitor"
 	"github.com/Brattlof/localpulse/ui"
 	"github.com/charmbracelet/lipgloss"
 )
 
+const (
+	stripSlots  = 12
+	stripWindow = 6 * time.Minute
+)
+
 type EndpointList struct {
-	Endpoints  []*monitor.Endpoint
-	Selected   int
-	Width      int
-	Height     int
-	styles     *ui.Styles
-	scrollable bool
+	Endpoints     []*monitor.Endpoint
+	Selected      int
+	Width         int
+	Height        int
+	FlapThreshold int
+	FlapWindow    time.Duration
+	LoadRates     map[string]monitor.EndpointRate
+	StepStats     map[string][]monitor.StepStats
+	WSStats       map[string]monitor.Stats
+	CertWarning   time.Duration
+	styles        *ui.Styles
+	scrollable    bool
 }
 
 func NewEndpointList(styles *ui.Styles) *EndpointList {
@@ -62,6 +75,27 @@ func (l *EndpointList) SetSize(width, height int) {
 	l.Height = height
 }
 
+func (l *EndpointList) SetFlapDetection(threshold int, window time.Duration) {
+	l.FlapThreshold = threshold
+	l.FlapWindow = window
+}
+
+func (l *EndpointList) SetCertWarning(warn time.Duration) {
+	l.CertWarning = warn
+}
+
+func (l *EndpointList) SetLoadRates(rates map[string]monitor.EndpointRate) {
+	l.LoadRates = rates
+}
+
+func (l *EndpointList) SetStepStats(steps map[string][]monitor.StepStats) {
+	l.StepStats = steps
+}
+
+func (l *EndpointList) SetWebSocketStats(stats map[string]monitor.Stats) {
+	l.WSStats = stats
+}
+
 func (l *EndpointList) Up() {
 	if l.Selected > 0 {
 		l.Selected--
@@ -87,30 +121,80 @@ func (l *EndpointList) View() string {
 		return l.styles.Panel.Width(l.Width).Height(l.Height).Render(empty)
 	}
 
+	rows := l.Height
+	var details []string
+	if selected := l.SelectedEndpoint(); selected != nil {
+		details = l.detailLines(selected)
+	}
+	if rows-len(details) < 2 {
+		details = nil
+	}
+	rows -= len(details)
+
 	start := 0
 	end := len(l.Endpoints)
 
-	if l.Height > 0 && len(l.Endpoints) > l.Height {
+	if rows > 0 && len(l.Endpoints) > rows {
 		l.scrollable = true
-		start = l.Selected - l.Height/2
+		start = l.Selected - rows/2
 		if start < 0 {
 			start = 0
 		}
-		end = start + l.Height
+		end = start + rows
 		if end > len(l.Endpoints) {
 			end = len(l.Endpoints)
-			start = end - l.Height
+			start = end - rows
 		}
 	}
 
+	showStrip := l.Width-15-stripSlots-1 >= 10
+
 	var lines []string
 	for i := start; i < end; i++ {
 		ep := l.Endpoints[i]
 		selected := i == l.Selected
 
 		icon := ep.StatusIcon()
-		name := ui.Truncate(ep.Name, l.Width-15)
-		latency := ui.FormatLatency(ep.LastLatency.Nanoseconds())
+		if ui.NoColor() {
+			icon = ep.StatusLabel()
+		}
+		nameWidth := l.Width - 15
+		strip := ""
+		if showStrip && ep.Timeline != nil {
+			nameWidth -= stripSlots + 1
+			strip = l.renderStrip(ep.Timeline.Strip(stripSlots, stripWindow)) + " "
+		}
+		var badges []string
+		if l.isFlapping(ep) {
+			badges = append(badges, l.styles.Theme.ColorWarning("FLAP"))
+			nameWidth -= 5
+		}
+		if ep.TLSError != "" {
+			badges = append(badges, l.styles.Theme.ColorError("CERT"))
+			nameWidth -= 5
+		} else if ep.IsHTTPS && ep.TLS.Insecure {
+			badges = append(badges, l.styles.Theme.ColorWarning("INSECURE"))
+			nameWidth -= 9
+		}
+		if ep.Cert != nil {
+			switch ep.Cert.Issue(time.Now(), l.CertWarning) {
+			case monitor.CertExpired:
+				badges = append(badges, l.styles.Theme.ColorError("EXPIRED"))
+				nameWidth -= 8
+			case monitor.CertHostMismatch:
+				badges = append(badges, l.styles.Theme.ColorError("HOST"))
+				nameWidth -= 5
+			case monitor.CertExpiring:
+				badge := fmt.Sprintf("EXP %dd", ep.Cert.DaysLeft(time.Now()))
+				badges = append(badges, l.styles.Theme.ColorWarning(badge))
+				nameWidth -= len(badge) + 1
+			}
+		}
+		name := ui.Truncate(ep.Name, nameWidth)
+		latency := l.styles.Theme.ColorMuted(ui.FormatLatency(ep.LastLatency.Nanoseconds()))
+		if len(badges) > 0 {
+			latency += " " + strings.Join(badges, " ")
+		}
 
 		if selected {
 			line := lipgloss.JoinHorizontal(
@@ -118,16 +202,21 @@ func (l *EndpointList) View() string {
 				icon+" ",
 				l.styles.EndpointSelected.Render(name),
 				" ",
-				l.styles.Theme.ColorMuted(latency),
+				strip,
+				latency,
 			)
 			lines = append(lines, line)
+			for _, d := range details {
+				lines = append(lines, l.styles.Theme.ColorMuted("   "+d))
+			}
 		} else {
 			line := lipgloss.JoinHorizontal(
 				lipgloss.Top,
 				icon+" ",
 				name,
 				" ",
-				l.styles.Theme.ColorMuted(latency),
+				strip,
+				latency,
 			)
 			lines = append(lines, l.styles.Endpoint.Render(line))
 		}
@@ -137,6 +226,135 @@ func (l *EndpointList) View() string {
 	return l.styles.Panel.Width(l.Width).Height(l.Height).Render(content)
 }
 
+func (l *EndpointList) isFlapping(ep *monitor.Endpoint) bool {
+	return ep.Timeline != nil && ep.Timeline.Flapping(l.FlapThreshold, l.FlapWindow)
+}
+
+func (l *EndpointList) renderStrip(strip []monitor.EndpointStatus) string {
+	var b strings.Builder
+	for _, status := range strip {
+		if ui.NoColor() {
+			switch status {
+			case monitor.StatusHealthy:
+				b.WriteString("+")
+			case monitor.StatusSlow:
+				b.WriteString("~")
+			case monitor.StatusDown:
+				b.WriteString("x")
+			default:
+				b.WriteString(".")
+			}
+			continue
+		}
+		switch status {
+		case monitor.StatusHealthy:
+			b.WriteString(l.styles.Theme.ColorSuccess("█"))
+		case monitor.StatusSlow:
+			b.WriteString(l.styles.Theme.ColorWarning("█"))
+		case monitor.StatusDown:
+			b.WriteString(l.styles.Theme.ColorError("█"))
+		default:
+			b.WriteString(l.styles.Theme.ColorMuted("·"))
+		}
+	}
+	return b.String()
+}
+
+func (l *EndpointList) detailLines(ep *monitor.Endpoint) []string {
+	var lines []string
+	if summary := l.summaryLine(ep); summary != "" {
+		lines = append(lines, summary)
+	}
+	if c := ep.Cert; c != nil {
+		now := time.Now()
+		cert := "cert " + c.Subject + " by " + c.Issuer
+		if c.SelfSigned {
+			cert = "cert " + c.Subject + " (self-signed)"
+		}
+		if len(c.SANs) > 0 {
+			cert += ", SANs " + strings.Join(c.SANs, " ")
+		}
+		validity := fmt.Sprintf("valid until %s (%dd)", c.NotAfter.Format("2006-01-02"), c.DaysLeft(now))
+		switch c.Issue(now, l.CertWarning) {
+		case monitor.CertExpired:
+			validity = "expired " + c.NotAfter.Format("2006-01-02")
+		case monitor.CertHostMismatch:
+			validity += ", " + c.HostError
+		}
+		validity += ", " + c.KeyType + ", " + c.Version + " " + c.Cipher
+		if c.ALPN != "" {
+			validity += ", ALPN " + c.ALPN
+		}
+		lines = append(lines, ui.Truncate(cert, l.Width-5), ui.Truncate(validity, l.Width-5))
+	}
+	return lines
+}
+
+func (l *EndpointList) summaryLine(ep *monitor.Endpoint) string {
+	var parts []string
+	switch {
+	case ep.TLSError != "":
+		parts = append(parts, "untrusted cert: "+ep.TLSError)
+	case ep.IsHTTPS && ep.TLS.Insecure:
+		parts = append(parts, "TLS verification off")
+	}
+	if ep.Proto != "" {
+		proto := monitor.ProtoName(ep.Proto)
+		if ep.Connections > 0 {
+			proto += fmt.Sprintf(" x%d conns", ep.Connections)
+		}
+		if ep.Streams != nil && ep.Streams.Limited {
+			proto += fmt.Sprintf(", %d streams/conn", ep.Streams.MaxConcurrentStreams)
+		}
+		parts = append(parts, proto)
+	}
+	if ep.Timeline != nil && ep.Timeline.Current() != monitor.StatusUnknown {
+		s := ep.Timeline.Summary()
+		uptime := fmt.Sprintf("up 1h %.1f%% 24h %.1f%% all %.1f%%", s.Uptime1h, s.Uptime24h, s.UptimeSession)
+		if s.Recoveries > 0 {
+			mttr := s.MTTR.Round(time.Millisecond)
+			if mttr >= time.Second {
+				mttr = mttr.Round(time.Second)
+			}
+			uptime += ", MTTR " + mttr.String()
+		}
+		parts = append(parts, uptime)
+	}
+	if rate, ok := l.LoadRates[ep.URL]; ok {
+		load := fmt.Sprintf("load %.1f/%.1f req/s", rate.Achieved, rate.Target)
+		switch {
+		case rate.RPS > 0:
+			load += " fixed"
+		case rate.Weight > 0:
+			load += fmt.Sprintf(" w%g", rate.Weight)
+		}
+		parts = append(parts, load)
+	}
+	if ws, ok := l.WSStats[ep.URL]; ok && ws.TotalRequests > 0 {
+		part := fmt.Sprintf("ws %.1f msg/s, p95 %s, connect %s", ws.Throughput,
+			ui.FormatLatency(ws.P95.Nanoseconds()), ui.FormatLatency(ws.AvgConnect.Nanoseconds()))
+		switch ws.Disconnects {
+		case 0:
+		case 1:
+			part += ", 1 disconnect"
+		default:
+			part += fmt.Sprintf(", %d disconnects", ws.Disconnects)
+		}
+		parts = append(parts, part)
+	}
+	for _, step := range l.StepStats[ep.URL] {
+		if step.Stats.TotalRequests == 0 {
+			continue
+		}
+		part := step.Name + " " + ui.FormatLatency(step.Stats.AvgLatency.Nanoseconds())
+		if step.Stats.ErrorRate > 0 {
+			part += fmt.Sprintf(" %.0f%% err", step.Stats.ErrorRate)
+		}
+		parts = append(parts, part)
+	}
+	return ui.Truncate(strings.Join(parts, ", "), l.Width-5)
+}
+
 func (l *EndpointList) Count() int {
 	return len(l.Endpoints)
 }
@@ -1,52 +1,102 @@
 package components
 
 import (
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Brattlof/localpulse/monitor"
 	"github.com/Brattlof/localpulse/ui"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 type LogEntry struct {
-	Timestamp time.Time
-	Message   string
-	IsError   bool
-	IsSuccess bool
+	Timestamp  time.Time
+	Message    string
+	IsError    bool
+	IsSuccess  bool
+	Endpoint   string
+	StatusCode int
 }
 
+type LogFilter struct {
+	ErrorsOnly  bool
+	StatusClass int
+	Endpoint    string
+	Query       string
+}
+
+func (f LogFilter) IsEmpty() bool {
+	return !f.ErrorsOnly && f.StatusClass == 0 && f.Endpoint == "" && f.Query == ""
+}
+
+func (f LogFilter) Match(entry LogEntry) bool {
+	if f.ErrorsOnly && !entry.IsError {
+		return false
+	}
+	if f.StatusClass != 0 && entry.StatusCode/100 != f.StatusClass {
+		return false
+	}
+	if f.Endpoint != "" && entry.Endpoint != f.Endpoint {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(f.Query)) {
+		return false
+	}
+	return true
+}
+
+var statusClasses = []int{0, 2, 3, 4, 5}
+
 type LogPanel struct {
-	Entries  []LogEntry
-	MaxLines int
-	Width    int
-	Height   int
-	styles   *ui.Styles
+	Entries     []LogEntry
+	MaxLines    int
+	Width       int
+	Height      int
+	Filter      LogFilter
+	Title       string
+	Placeholder string
+	styles      *ui.Styles
+
+	paused    bool
+	pending   []LogEntry
+	scroll    int
+	endpoints []string
+
+	search    textinput.Model
+	searching bool
 }
 
 func NewLogPanel(maxLines int, styles *ui.Styles) *LogPanel {
 	if maxLines <= 0 {
 		maxLines = 100
 	}
+
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search"
+	ti.CharLimit = 128
+
 	return &LogPanel{
-		Entries:  make([]LogEntry, 0),
-		MaxLines: maxLines,
-		styles:   styles,
+		Entries:     make([]LogEntry, 0),
+		MaxLines:    maxLines,
+		Title:       "Log",
+		Placeholder: "Waiting for requests...",
+		styles:      styles,
+		search:      ti,
 	}
 }
 
 func (p *LogPanel) AddEntry(message string, isError, isSuccess bool) {
-	entry := LogEntry{
+	p.append(LogEntry{
 		Timestamp: time.Now(),
 		Message:   message,
 		IsError:   isError,
 		IsSuccess: isSuccess,
-	}
-
-	p.Entries = append(p.Entries, entry)
-
-	if len(p.Entries) > p.MaxLines {
-		p.Entries = p.Entries[len(p.Entries)-p.MaxLines:]
-	}
+	})
 }
 
 func (p *LogPanel) AddRequest(result monitor.RequestResult, endpoint string) {
@@ -64,44 +114,323 @@ func (p *LogPanel) AddRequest(result monitor.RequestResult, endpoint string) {
 		} else {
 			isSuccess = true
 		}
-		message = endpoint + " " + status + " " + ui.FormatLatency(result.Latency.Nanoseconds())
+		message = endpoint + " " + status + " " + strconv.Itoa(result.StatusCode) + " " + ui.FormatLatency(result.Latency.Nanoseconds())
+	}
+
+	timestamp := result.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
 	}
 
-	p.AddEntry(message, isError, isSuccess)
+	p.trackEndpoint(endpoint)
+	p.append(LogEntry{
+		Timestamp:  timestamp,
+		Message:    message,
+		IsError:    isError,
+		IsSuccess:  isSuccess,
+		Endpoint:   endpoint,
+		StatusCode: result.StatusCode,
+	})
+}
+
+func (p *LogPanel) AddOutput(line monitor.OutputLine) {
+	lower := strings.ToLower(line.Text)
+	isError := strings.Contains(lower, "error") || strings.Contains(lower, "panic") ||
+		strings.Contains(lower, "exception") || strings.Contains(lower, "fatal")
+
+	p.trackEndpoint(line.Stream)
+	p.append(LogEntry{
+		Timestamp: line.Time,
+		Message:   line.Text,
+		IsError:   isError,
+		Endpoint:  line.Stream,
+	})
+}
+
+func (p *LogPanel) append(entry LogEntry) {
+	if p.paused {
+		p.pending = append(p.pending, entry)
+		if len(p.pending) > p.MaxLines {
+			p.pending = p.pending[len(p.pending)-p.MaxLines:]
+		}
+		return
+	}
+
+	p.Entries = append(p.Entries, entry)
+	if len(p.Entries) > p.MaxLines {
+		p.Entries = p.Entries[len(p.Entries)-p.MaxLines:]
+	}
+}
+
+func (p *LogPanel) trackEndpoint(endpoint string) {
+	if endpoint == "" {
+		return
+	}
+	for _, e := range p.endpoints {
+		if e == endpoint {
+			return
+		}
+	}
+	p.endpoints = append(p.endpoints, endpoint)
 }
 
 func (p *LogPanel) SetSize(width, height int) {
 	p.Width = width
 	p.Height = height
+	p.search.Width = width - 10
 }
 
-func (p *LogPanel) View() string {
-	if len(p.Entries) == 0 {
-		empty := p.styles.Theme.ColorMuted("Waiting for requests...")
-		return p.styles.Panel.Width(p.Width).Height(p.Height).Render(empty)
+func (p *LogPanel) Pause() {
+	p.paused = true
+}
+
+func (p *LogPanel) Resume() {
+	p.paused = false
+	p.scroll = 0
+	pending := p.pending
+	p.pending = nil
+	for _, entry := range pending {
+		p.append(entry)
+	}
+}
+
+func (p *LogPanel) TogglePause() {
+	if p.paused {
+		p.Resume()
+	} else {
+		p.Pause()
 	}
+}
+
+func (p *LogPanel) IsPaused() bool {
+	return p.paused
+}
+
+func (p *LogPanel) visibleLines() int {
+	n := p.Height - 3
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (p *LogPanel) ScrollUp(n int) {
+	p.Pause()
+	p.scroll += n
+	if max := len(p.Visible()) - p.visibleLines(); p.scroll > max {
+		p.scroll = max
+	}
+	if p.scroll < 0 {
+		p.scroll = 0
+	}
+}
 
-	start := 0
-	if len(p.Entries) > p.Height-2 {
-		start = len(p.Entries) - (p.Height - 2)
+func (p *LogPanel) ScrollDown(n int) {
+	p.scroll -= n
+	if p.scroll < 0 {
+		p.scroll = 0
 	}
+}
+
+func (p *LogPanel) ScrollTop() {
+	p.ScrollUp(len(p.Entries))
+}
+
+func (p *LogPanel) ScrollBottom() {
+	p.Resume()
+}
 
+func (p *LogPanel) PageSize() int {
+	return p.visibleLines()
+}
+
+func (p *LogPanel) ToggleErrorsOnly() {
+	p.Filter.ErrorsOnly = !p.Filter.ErrorsOnly
+	p.scroll = 0
+}
+
+func (p *LogPanel) CycleStatusClass() {
+	for i, class := range statusClasses {
+		if class == p.Filter.StatusClass {
+			p.Filter.StatusClass = statusClasses[(i+1)%len(statusClasses)]
+			break
+		}
+	}
+	p.scroll = 0
+}
+
+func (p *LogPanel) CycleEndpoint() {
+	p.scroll = 0
+	if len(p.endpoints) == 0 {
+		p.Filter.Endpoint = ""
+		return
+	}
+	if p.Filter.Endpoint == "" {
+		p.Filter.Endpoint = p.endpoints[0]
+		return
+	}
+	for i, e := range p.endpoints {
+		if e == p.Filter.Endpoint {
+			if i+1 < len(p.endpoints) {
+				p.Filter.Endpoint = p.endpoints[i+1]
+			} else {
+				p.Filter.Endpoint = ""
+			}
+			return
+		}
+	}
+	p.Filter.Endpoint = ""
+}
+
+func (p *LogPanel) ClearFilters() {
+	p.Filter = LogFilter{}
+	p.search.SetValue("")
+	p.scroll = 0
+}
+
+func (p *LogPanel) StartSearch() tea.Cmd {
+	p.searching = true
+	p.search.SetValue(p.Filter.Query)
+	p.search.CursorEnd()
+	p.search.Focus()
+	return textinput.Blink
+}
+
+func (p *LogPanel) IsSearching() bool {
+	return p.searching
+}
+
+func (p *LogPanel) Update(msg tea.Msg) (*LogPanel, tea.Cmd) {
+	if !p.searching {
+		return p, nil
+	}
+
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "enter":
+			p.searching = false
+			p.search.Blur()
+			p.Filter.Query = strings.TrimSpace(p.search.Value())
+			p.scroll = 0
+			return p, nil
+		case "esc":
+			p.searching = false
+			p.search.Blur()
+			return p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	p.search, cmd = p.search.Update(msg)
+	p.Filter.Query = strings.TrimSpace(p.search.Value())
+	p.scroll = 0
+	return p, cmd
+}
+
+func (p *LogPanel) Visible() []LogEntry {
+	if p.Filter.IsEmpty() {
+		return p.Entries
+	}
+	var result []LogEntry
+	for _, entry := range p.Entries {
+		if p.Filter.Match(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func (p *LogPanel) SaveTo(path string) error {
+	var b strings.Builder
+	write := func(entry LogEntry) {
+		level := "INFO"
+		if entry.IsError {
+			level = "ERROR"
+		}
+		fmt.Fprintf(&b, "%s %-5s %s\n", entry.Timestamp.Format(time.RFC3339Nano), level, entry.Message)
+	}
+	for _, entry := range p.Entries {
+		write(entry)
+	}
+	for _, entry := range p.pending {
+		write(entry)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func (p *LogPanel) header() string {
+	var parts []string
+	if p.Filter.ErrorsOnly {
+		parts = append(parts, "errors")
+	}
+	if p.Filter.StatusClass != 0 {
+		parts = append(parts, strconv.Itoa(p.Filter.StatusClass)+"xx")
+	}
+	if p.Filter.Endpoint != "" {
+		parts = append(parts, p.Filter.Endpoint)
+	}
+	if p.Filter.Query != "" {
+		parts = append(parts, "\""+p.Filter.Query+"\"")
+	}
+
+	header := p.Title
+	if len(parts) > 0 {
+		header += " [" + strings.Join(parts, ", ") + "]"
+	}
+	if p.paused {
+		header += " PAUSED"
+		if len(p.pending) > 0 {
+			header += " (+" + strconv.Itoa(len(p.pending)) + " new)"
+		}
+	}
+	return header
+}
+
+func (p *LogPanel) View() string {
 	var lines []string
-	for i := start; i < len(p.Entries); i++ {
-		entry := p.Entries[i]
+
+	if p.searching {
+		lines = append(lines, p.search.View())
+	} else {
+		lines = append(lines, p.styles.CardTitle.Render(p.header()))
+	}
+
+	entries := p.Visible()
+	if len(entries) == 0 {
+		if len(p.Entries) == 0 {
+			lines = append(lines, p.styles.Theme.ColorMuted(p.Placeholder))
+		} else {
+			lines = append(lines, p.styles.Theme.ColorMuted("No entries match the current filter"))
+		}
+		return p.styles.Panel.Width(p.Width).Height(p.Height).Render(strings.Join(lines, "\n"))
+	}
+
+	end := len(entries) - p.scroll
+	if end < 0 {
+		end = 0
+	}
+	start := end - p.visibleLines()
+	if start < 0 {
+		start = 0
+	}
+
+	maxMessage := p.Width - 13
+	for i := start; i < end; i++ {
+		entry := entries[i]
 		timeStr := entry.Timestamp.Format("15:04:05")
 
+		message := entry.Message
+		if maxMessage > 3 {
+			message = ui.Truncate(message, maxMessage)
+		}
+
 		var line string
 		if entry.IsError {
-			line = p.styles.LogError.Render(timeStr+" ") + entry.Message
+			line = p.styles.LogError.Render(timeStr+" ") + message
 		} else if entry.IsSuccess {
-			line = p.styles.LogSuccess.Render(timeStr+" ") + entry.Message
+			line = p.styles.LogSuccess.Render(timeStr+" ") + message
 		} else {
-			line = p.styles.LogLine.Render(timeStr+" ") + entry.Message
-		}
-
-		if p.Width > 0 && len(line) > p.Width-4 {
-			line = line[:p.Width-7] + "..."
+			line = p.styles.LogLine.Render(timeStr+" ") + message
 		}
 
 		lines = append(lines, line)
@@ -113,4 +442,6 @@ func (p *LogPanel) View() string {
 
 func (p *LogPanel) Clear() {
 	p.Entries = p.Entries[:0]
+	p.pending = nil
+	p.scroll = 0
 }
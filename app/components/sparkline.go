@@ -1,16 +1,20 @@
 package components
 
 import (
+	"strconv"
+
+	"github.com/Brattlof/localpulse/monitor"
 	"github.com/Brattlof/localpulse/ui"
 	"github.com/charmbracelet/lipgloss"
 )
 
 type SparklineChart struct {
-	Data   []float64
-	Title  string
-	Width  int
-	Height int
-	styles *ui.Styles
+	Data     []float64
+	Title    string
+	Subtitle string
+	Width    int
+	Height   int
+	styles   *ui.Styles
 }
 
 func NewSparklineChart(title string, styles *ui.Styles) *SparklineChart {
@@ -43,6 +47,9 @@ func (c *SparklineChart) SetSize(width, height int) {
 
 func (c *SparklineChart) View() string {
 	titleLine := c.styles.CardTitle.Render(c.Title)
+	if c.Subtitle != "" {
+		titleLine += " " + c.styles.Theme.ColorMuted(c.Subtitle)
+	}
 	chartLine := ui.Sparkline(c.Data, c.Height, c.styles.Theme)
 
 	content := lipgloss.JoinVertical(
@@ -58,6 +65,9 @@ func (c *SparklineChart) View() string {
 type ChartPanel struct {
 	LatencyChart    *SparklineChart
 	ThroughputChart *SparklineChart
+	ProcessCPUChart *SparklineChart
+	ProcessRSSChart *SparklineChart
+	ShowProcess     bool
 	Width           int
 	Height          int
 	styles          *ui.Styles
@@ -67,6 +77,8 @@ func NewChartPanel(styles *ui.Styles) *ChartPanel {
 	return &ChartPanel{
 		LatencyChart:    NewSparklineChart("Latency (ms)", styles),
 		ThroughputChart: NewSparklineChart("Throughput (req/s)", styles),
+		ProcessCPUChart: NewSparklineChart("Process CPU (%)", styles),
+		ProcessRSSChart: NewSparklineChart("Process RSS (MB)", styles),
 		styles:          styles,
 	}
 }
@@ -75,13 +87,45 @@ func (p *ChartPanel) SetSize(width, height int) {
 	p.Width = width
 	p.Height = height
 
-	chartHeight := height/2 - 2
+	rows := 2
+	if p.ShowProcess {
+		rows = 3
+	}
+	chartHeight := height/rows - 2
 	if chartHeight < 4 {
 		chartHeight = 4
 	}
 
 	p.LatencyChart.SetSize(width, chartHeight)
 	p.ThroughputChart.SetSize(width, chartHeight)
+	p.ProcessCPUChart.SetSize(width/2, chartHeight)
+	p.ProcessRSSChart.SetSize(width-width/2-1, chartHeight)
+}
+
+func (p *ChartPanel) AttachProcess(name string, pid int32) {
+	p.ShowProcess = true
+	p.ProcessCPUChart.Title = name + " [" + strconv.Itoa(int(pid)) + "] CPU (%)"
+	p.ProcessCPUChart.SetData(nil)
+	p.ProcessRSSChart.SetData(nil)
+	p.ProcessCPUChart.Subtitle = ""
+	p.ProcessRSSChart.Subtitle = ""
+	p.SetSize(p.Width, p.Height)
+}
+
+func (p *ChartPanel) DetachProcess() {
+	p.ShowProcess = false
+	p.SetSize(p.Width, p.Height)
+}
+
+func (p *ChartPanel) AddProcessSample(m monitor.ProcessMetrics) {
+	p.ProcessCPUChart.AddPoint(m.CPUPercent)
+	p.ProcessRSSChart.AddPoint(float64(m.RSS) / (1024 * 1024))
+
+	p.ProcessCPUChart.Subtitle = ui.FormatPercent(m.CPUPercent) +
+		" · " + strconv.Itoa(m.Processes) + " proc · " + strconv.Itoa(int(m.Threads)) + " thr"
+	p.ProcessRSSChart.Subtitle = ui.FormatBytes(int64(m.RSS)) +
+		" · " + strconv.Itoa(int(m.FDs)) + " fd · io " +
+		ui.FormatBytes(int64(m.ReadRate)) + "/s r " + ui.FormatBytes(int64(m.WriteRate)) + "/s w"
 }
 
 func (p *ChartPanel) AddLatencyPoint(latencyMs float64) {
@@ -93,10 +137,19 @@ func (p *ChartPanel) AddThroughputPoint(rps float64) {
 }
 
 func (p *ChartPanel) View() string {
+	if !p.ShowProcess {
+		return lipgloss.JoinVertical(
+			lipgloss.Top,
+			p.LatencyChart.View(),
+			"",
+			p.ThroughputChart.View(),
+		)
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Top,
 		p.LatencyChart.View(),
-		"",
 		p.ThroughputChart.View(),
+		lipgloss.JoinHorizontal(lipgloss.Top, p.ProcessCPUChart.View(), " ", p.ProcessRSSChart.View()),
 	)
 }
@@ -0,0 +1,106 @@
+package components
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Brattlof/localpulse/monitor"
+	"github.com/Brattlof/localpulse/ui"
+)
+
+const coreCellWidth = 20
+
+type SystemPanel struct {
+	Expanded bool
+	Metrics  monitor.SystemMetrics
+	Width    int
+	styles   *ui.Styles
+}
+
+func NewSystemPanel(styles *ui.Styles) *SystemPanel {
+	return &SystemPanel{styles: styles}
+}
+
+func (p *SystemPanel) SetWidth(width int) {
+	p.Width = width
+}
+
+func (p *SystemPanel) Toggle() {
+	p.Expanded = !p.Expanded
+}
+
+func (p *SystemPanel) Update(metrics monitor.SystemMetrics) {
+	p.Metrics = metrics
+}
+
+func (p *SystemPanel) coresPerRow() int {
+	n := (p.Width - 4) / coreCellWidth
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func (p *SystemPanel) Height() int {
+	if !p.Expanded {
+		return 0
+	}
+	cores := len(p.Metrics.CPUPerCore)
+	rows := (cores + p.coresPerRow() - 1) / p.coresPerRow()
+	return rows + 3 + 2
+}
+
+func (p *SystemPanel) View() string {
+	if !p.Expanded {
+		return ""
+	}
+
+	m := p.Metrics
+	theme := p.styles.Theme
+	label := func(s string) string {
+		return p.styles.CardTitle.Render(s)
+	}
+
+	var lines []string
+
+	perRow := p.coresPerRow()
+	var row []string
+	for i, pct := range m.CPUPerCore {
+		cell := padRight(strconv.Itoa(i), 3) + ui.ProgressBar(pct/100, 10, theme) + " " + padLeft(ui.FormatPercent(pct), 4)
+		row = append(row, cell)
+		if len(row) == perRow || i == len(m.CPUPerCore)-1 {
+			lines = append(lines, strings.Join(row, "  "))
+			row = nil
+		}
+	}
+
+	lines = append(lines,
+		label("Load ")+" "+formatLoad(m.Load1)+" "+formatLoad(m.Load5)+" "+formatLoad(m.Load15)+
+			"    "+label("Swap ")+" "+ui.FormatBytes(int64(m.SwapUsed))+"/"+ui.FormatBytes(int64(m.SwapTotal)),
+		label("Loopback")+" rx "+ui.FormatBytes(int64(m.LoopbackRxRate))+"/s tx "+ui.FormatBytes(int64(m.LoopbackTxRate))+"/s"+
+			"    "+label("Disk")+" r "+ui.FormatBytes(int64(m.DiskReadRate))+"/s w "+ui.FormatBytes(int64(m.DiskWriteRate))+"/s",
+		label("LocalPulse")+" CPU "+ui.FormatPercent(m.SelfCPUPercent)+
+			"  RSS "+ui.FormatBytes(int64(m.SelfRSS))+
+			"  goroutines "+strconv.Itoa(m.Goroutines),
+	)
+
+	return p.styles.Panel.Width(p.Width).Render(strings.Join(lines, "\n"))
+}
+
+func formatLoad(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+func padLeft(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return strings.Repeat(" ", n-len(s)) + s
+}
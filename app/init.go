@@ -3,8 +3,18 @@ package app
 import tea "github.com/charmbracelet/bubbletea"
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		DoTick(),
 		DoScan(m.scanner),
-	)
+		DoConfigPoll(),
+		m.healthCheck(),
+		DoInspectCerts(m.endpoints, 0),
+	}
+	if m.server != nil {
+		cmds = append(cmds, DoWaitServer(m.server))
+	}
+	if m.config.Process != "" {
+		cmds = append(cmds, DoAttachProcess(m.config.Process))
+	}
+	return tea.Batch(cmds...)
 }
@@ -1,10 +1,14 @@
 package app
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/Brattlof/localpulse/app/components"
 	"github.com/Brattlof/localpulse/config"
 	"github.com/Brattlof/localpulse/monitor"
 	"github.com/Brattlof/localpulse/ui"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 type FocusPanel int
@@ -13,6 +17,7 @@ const (
 	FocusEndpoints FocusPanel = iota
 	FocusCharts
 	FocusLogs
+	FocusServerLog
 )
 
 type ModelState int
@@ -24,6 +29,8 @@ const (
 	StateAddingEndpoint
 )
 
+const certInterval = 10 * time.Minute
+
 type Model struct {
 	state    ModelState
 	focus    FocusPanel
@@ -31,13 +38,23 @@ type Model struct {
 	height   int
 	quitting bool
 
-	config *config.Config
-	theme  ui.Theme
-	styles *ui.Styles
+	config        *config.Config
+	configWatcher *config.Watcher
+	theme         ui.Theme
+	styles        *ui.Styles
 
 	scanner       *monitor.Scanner
 	loadGenerator *monitor.LoadGenerator
 	sysMonitor    *monitor.SystemMonitor
+	sampler       *monitor.RequestSampler
+	procMonitor   *monitor.ProcessMonitor
+	alerter       *monitor.Alerter
+	webhooks      *monitor.WebhookSender
+	flapping      map[string]bool
+	certIssues    map[string]monitor.CertIssue
+	scenarios     map[string]config.ScenarioConfig
+	server        *monitor.ServerRunner
+	serverURL     string
 
 	endpoints    []*monitor.Endpoint
 	endpointList *components.EndpointList
@@ -46,8 +63,10 @@ type Model struct {
 	metricsMap map[string]*monitor.Metrics
 
 	summaryPanel *components.SummaryPanel
+	systemPanel  *components.SystemPanel
 	chartPanel   *components.ChartPanel
 	logPanel     *components.LogPanel
+	serverLog    *components.LogPanel
 	inputForm    *components.InputForm
 
 	healthy int
@@ -57,33 +76,167 @@ type Model struct {
 	rps int
 }
 
-func NewModel(cfg *config.Config) Model {
-	theme := ui.DetectTheme()
+type ModelOption func(*Model)
+
+func WithServer(runner *monitor.ServerRunner, url string) ModelOption {
+	return func(m *Model) {
+		m.server = runner
+		m.serverURL = url
+		m.serverLog = components.NewLogPanel(2000, m.styles)
+		m.serverLog.Title = "Server"
+		m.serverLog.Placeholder = "Waiting for output from " + runner.Command() + "..."
+	}
+}
+
+func NewModel(cfg *config.Config, opts ...ModelOption) Model {
+	theme := ui.ThemeByName(cfg.Theme)
 	styles := ui.NewStyles(theme)
 
 	summaryPanel := components.NewSummaryPanel(styles)
 	chartPanel := components.NewChartPanel(styles)
-	logPanel := components.NewLogPanel(100, styles)
+	logPanel := components.NewLogPanel(1000, styles)
 	inputForm := components.NewInputForm(styles)
 	endpointList := components.NewEndpointList(styles)
+	endpointList.SetFlapDetection(cfg.FlapThreshold, time.Duration(cfg.FlapWindow)*time.Second)
+	endpointList.SetCertWarning(time.Duration(cfg.CertWarnDays) * 24 * time.Hour)
 
-	return Model{
+	sampler := monitor.NewRequestSampler(cfg.LogSampleRate, 500)
+	loadGenerator := monitor.NewLoadGenerator(
+		monitor.WithMaxConcurrency(cfg.MaxConcurrency),
+		monitor.WithClientTimeout(time.Duration(cfg.Timeout)*time.Second),
+	)
+	loadGenerator.SetSampler(sampler)
+
+	m := Model{
 		state:         StateIdle,
 		focus:         FocusEndpoints,
 		config:        cfg,
+		configWatcher: config.NewWatcher(cfg.WatchPaths()),
 		theme:         theme,
 		styles:        styles,
-		scanner:       monitor.NewScanner(monitor.WithPorts(cfg.DefaultPorts)),
-		loadGenerator: monitor.NewLoadGenerator(),
+		scanner:       monitor.NewScanner(monitor.WithPorts(cfg.DefaultPorts), monitor.WithHost(cfg.Host), monitor.WithPolicy(defaultPolicy(cfg)), monitor.WithReportUntrusted(cfg.ReportUntrusted), monitor.WithSocketDirs(cfg.SocketDirPaths())),
+		loadGenerator: loadGenerator,
 		sysMonitor:    monitor.NewSystemMonitor(),
+		alerter:       monitor.NewAlerter(alertRules(cfg.GetAlerts())),
+		flapping:      make(map[string]bool),
+		certIssues:    make(map[string]monitor.CertIssue),
+		scenarios:     make(map[string]config.ScenarioConfig),
+		sampler:       sampler,
 		endpointList:  endpointList,
 		metricsMap:    make(map[string]*monitor.Metrics),
 		summaryPanel:  summaryPanel,
+		systemPanel:   components.NewSystemPanel(styles),
 		chartPanel:    chartPanel,
 		logPanel:      logPanel,
 		inputForm:     inputForm,
 		rps:           cfg.LoadTestRPS,
 	}
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	if err := m.scanner.SetTLS(defaultTLS(cfg)); err != nil {
+		m.logPanel.AddEntry("Scanner TLS: "+err.Error(), true, false)
+	}
+	webhooks, err := newWebhookSender(cfg)
+	if err != nil {
+		m.logPanel.AddEntry("Webhooks disabled: "+err.Error(), true, false)
+	}
+	m.webhooks = webhooks
+	for _, epCfg := range cfg.GetEndpoints() {
+		if ep, err := monitor.NewEndpoint(epCfg.URL); err == nil {
+			ep.Name = epCfg.Name
+			m.addEndpoint(ep)
+		}
+	}
+	m.syncScenarios()
+	return m
+}
+
+func alertRules(alerts []config.AlertConfig) []monitor.AlertRule {
+	rules := make([]monitor.AlertRule, 0, len(alerts))
+	for _, a := range alerts {
+		rule := monitor.AlertRule{
+			Name:      a.Name,
+			Endpoint:  a.Endpoint,
+			When:      monitor.AlertCondition(a.When),
+			For:       a.For,
+			Threshold: a.Threshold,
+			Command:   a.Command,
+			Cooldown:  time.Duration(a.CooldownSeconds) * time.Second,
+		}
+		if a.CooldownSeconds == 0 {
+			rule.Cooldown = time.Minute
+		}
+		for _, action := range a.Actions {
+			rule.Actions = append(rule.Actions, monitor.AlertAction(action))
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func newWebhookSender(cfg *config.Config) (*monitor.WebhookSender, error) {
+	var hooks []monitor.Webhook
+	for _, w := range cfg.GetWebhooks() {
+		hook := monitor.Webhook{
+			URL:      w.URL,
+			Format:   w.Format,
+			Template: w.Template,
+			Headers:  w.Headers,
+			Retries:  w.Retries,
+		}
+		if w.Retries == 0 {
+			hook.Retries = 3
+		}
+		for _, e := range w.Events {
+			hook.Events = append(hook.Events, monitor.WebhookEventKind(e))
+		}
+		hooks = append(hooks, hook)
+	}
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	var opts []monitor.WebhookOption
+	if cfg.Timeout > 0 {
+		opts = append(opts, monitor.WithWebhookClient(&http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second}))
+	}
+	return monitor.NewWebhookSender(hooks, opts...)
+}
+
+func (m Model) Close() {
+	if m.webhooks != nil {
+		m.webhooks.Close(5 * time.Second)
+	}
+}
+
+func (m Model) healthCheck() tea.Cmd {
+	var endpoints []*monitor.Endpoint
+	for _, ep := range m.endpoints {
+		if ep.Scenario == nil {
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return DoHealthCheck(endpoints,
+		time.Duration(m.config.CheckInterval)*time.Second,
+		time.Duration(m.config.Timeout)*time.Second)
+}
+
+func (m Model) focusCount() FocusPanel {
+	if m.serverLog != nil {
+		return 4
+	}
+	return 3
+}
+
+func (m Model) focusedLog() *components.LogPanel {
+	switch m.focus {
+	case FocusLogs:
+		return m.logPanel
+	case FocusServerLog:
+		return m.serverLog
+	}
+	return nil
 }
 
 func (m Model) GetEndpoints() []*monitor.Endpoint {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/Brattlof/localpulse/config"
 	"github.com/Brattlof/localpulse/monitor"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,6 +17,38 @@ type MetricsUpdateMsg struct {
 	URL   string
 	Stats monitor.Stats
 }
+type ServerReadyMsg struct {
+	Elapsed time.Duration
+	Err     error
+}
+type ProcessAttachMsg struct {
+	Spec    string
+	Monitor *monitor.ProcessMonitor
+	Err     error
+}
+type ProcessSampleMsg struct {
+	Monitor *monitor.ProcessMonitor
+	Metrics monitor.ProcessMetrics
+	Err     error
+}
+type HealthCheckMsg struct {
+	Results []monitor.CheckResult
+}
+type AuthPreparedMsg struct {
+	Tokens int
+	Errors []string
+}
+type StreamsProbedMsg struct {
+	Limits map[string]monitor.StreamLimits
+}
+type CertsInspectedMsg struct {
+	Certs map[string]*monitor.CertInfo
+}
+type ConfigPollMsg time.Time
+type ConfigReloadMsg struct {
+	Config *config.Config
+	Err    error
+}
 
 func DoTick() tea.Cmd {
 	return tea.Tick(100*time.Millisecond, func(t time.Time) tea.Msg {
@@ -39,3 +72,103 @@ func DoMetricsUpdate(url string, metrics *monitor.Metrics) tea.Cmd {
 		return MetricsUpdateMsg{URL: url, Stats: stats}
 	}
 }
+
+func DoHealthCheck(endpoints []*monitor.Endpoint, interval, timeout time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout+time.Second)
+		defer cancel()
+
+		results := monitor.CheckEndpoints(ctx, endpoints, monitor.WithCheckTimeout(timeout))
+		return HealthCheckMsg{Results: results}
+	})
+}
+
+func DoInspectCerts(endpoints []*monitor.Endpoint, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return CertsInspectedMsg{Certs: monitor.InspectEndpoints(ctx, endpoints, 5*time.Second)}
+	})
+}
+
+func DoProbeStreams(endpoints []*monitor.Endpoint) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		msg := StreamsProbedMsg{Limits: make(map[string]monitor.StreamLimits)}
+		for _, ep := range endpoints {
+			if ep.Scenario != nil {
+				continue
+			}
+			if limits, err := monitor.ProbeStreams(ctx, ep); err == nil {
+				msg.Limits[ep.URL] = limits
+			}
+		}
+		return msg
+	}
+}
+
+func DoWaitServer(runner *monitor.ServerRunner) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		start := time.Now()
+		err := runner.WaitReady(ctx)
+		return ServerReadyMsg{Elapsed: time.Since(start), Err: err}
+	}
+}
+
+func DoAttachProcess(spec string) tea.Cmd {
+	return func() tea.Msg {
+		pid, err := monitor.ResolveProcess(spec)
+		if err != nil {
+			return ProcessAttachMsg{Spec: spec, Err: err}
+		}
+		pm, err := monitor.NewProcessMonitor(pid)
+		return ProcessAttachMsg{Spec: spec, Monitor: pm, Err: err}
+	}
+}
+
+func DoSampleProcess(pm *monitor.ProcessMonitor) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		metrics, err := pm.Sample()
+		return ProcessSampleMsg{Monitor: pm, Metrics: metrics, Err: err}
+	})
+}
+
+func DoConfigPoll() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return ConfigPollMsg(t)
+	})
+}
+
+func DoConfigReload(cfg *config.Config) tea.Cmd {
+	return func() tea.Msg {
+		next, err := cfg.Reload()
+		return ConfigReloadMsg{Config: next, Err: err}
+	}
+}
+
+func DoPrepareAuth(endpoints []*monitor.Endpoint) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		var msg AuthPreparedMsg
+		seen := make(map[*monitor.TokenSource]bool)
+		for _, ep := range endpoints {
+			if ep.Auth == nil || ep.Auth.OAuth2 == nil || seen[ep.Auth.OAuth2] {
+				continue
+			}
+			seen[ep.Auth.OAuth2] = true
+			if err := ep.Auth.Prepare(ctx); err != nil {
+				msg.Errors = append(msg.Errors, ep.Name+": "+err.Error())
+				continue
+			}
+			msg.Tokens++
+		}
+		return msg
+	}
+}
@@ -0,0 +1,659 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Brattlof/localpulse/config"
+	"github.com/Brattlof/localpulse/monitor"
+	"github.com/Brattlof/localpulse/ui"
+)
+
+type PlainFormat string
+
+const (
+	PlainText   PlainFormat = "text"
+	PlainNDJSON PlainFormat = "ndjson"
+)
+
+type PlainEvent struct {
+	Time      time.Time    `json:"time"`
+	Event     string       `json:"event"`
+	Rule      string       `json:"rule,omitempty"`
+	URL       string       `json:"url,omitempty"`
+	Name      string       `json:"name,omitempty"`
+	Status    string       `json:"status,omitempty"`
+	Previous  string       `json:"previous,omitempty"`
+	LatencyMs float64      `json:"latency_ms,omitempty"`
+	Requests  int64        `json:"requests,omitempty"`
+	Errors    int64        `json:"errors,omitempty"`
+	ErrorRate float64      `json:"error_rate,omitempty"`
+	AvgMs     float64      `json:"avg_ms,omitempty"`
+	P95Ms     float64      `json:"p95_ms,omitempty"`
+	MaxMs     float64      `json:"max_ms,omitempty"`
+	Uptime    float64      `json:"uptime_percent,omitempty"`
+	Uptime1h  float64      `json:"uptime_1h_percent,omitempty"`
+	Uptime24h float64      `json:"uptime_24h_percent,omitempty"`
+	MTTR      float64      `json:"mttr_seconds,omitempty"`
+	Changes   int          `json:"transitions,omitempty"`
+	Flapping  bool         `json:"flapping,omitempty"`
+	Duration  float64      `json:"duration_seconds,omitempty"`
+	Stream    string       `json:"stream,omitempty"`
+	PID       int          `json:"pid,omitempty"`
+	Protocol  string       `json:"protocol,omitempty"`
+	ConnectMs float64      `json:"connect_ms,omitempty"`
+	Drops     int64        `json:"disconnects,omitempty"`
+	System    *PlainSystem `json:"system,omitempty"`
+	Message   string       `json:"message,omitempty"`
+}
+
+type PlainSystem struct {
+	CPUPercent     float64   `json:"cpu_percent"`
+	CPUPerCore     []float64 `json:"cpu_per_core,omitempty"`
+	RAMUsed        uint64    `json:"ram_used_bytes"`
+	RAMPercent     float64   `json:"ram_percent"`
+	SwapUsed       uint64    `json:"swap_used_bytes"`
+	Load1          float64   `json:"load1"`
+	Load5          float64   `json:"load5"`
+	Load15         float64   `json:"load15"`
+	LoopbackRxRate float64   `json:"loopback_rx_bytes_per_second"`
+	LoopbackTxRate float64   `json:"loopback_tx_bytes_per_second"`
+	DiskReadRate   float64   `json:"disk_read_bytes_per_second"`
+	DiskWriteRate  float64   `json:"disk_write_bytes_per_second"`
+	SelfCPUPercent float64   `json:"self_cpu_percent"`
+	SelfRSS        uint64    `json:"self_rss_bytes"`
+	Goroutines     int       `json:"goroutines"`
+}
+
+type plainEndpoint struct {
+	ep       *monitor.Endpoint
+	metrics  *monitor.Metrics
+	status   monitor.EndpointStatus
+	flapping bool
+}
+
+type plainCheck struct {
+	endpoints []*plainEndpoint
+	results   []monitor.CheckResult
+}
+
+type Plain struct {
+	config        *config.Config
+	out           io.Writer
+	format        PlainFormat
+	color         bool
+	theme         ui.Theme
+	statsInterval time.Duration
+
+	scanner   *monitor.Scanner
+	system    *monitor.SystemMonitor
+	alerter   *monitor.Alerter
+	webhooks  *monitor.WebhookSender
+	server    *monitor.ServerRunner
+	serverURL string
+
+	endpoints  []*plainEndpoint
+	start      time.Time
+	systemPeak PlainSystem
+}
+
+type PlainOption func(*Plain)
+
+func WithPlainFormat(format PlainFormat) PlainOption {
+	return func(p *Plain) {
+		p.format = format
+	}
+}
+
+func WithColor(color bool) PlainOption {
+	return func(p *Plain) {
+		p.color = color
+	}
+}
+
+func WithStatsInterval(interval time.Duration) PlainOption {
+	return func(p *Plain) {
+		if interval > 0 {
+			p.statsInterval = interval
+		}
+	}
+}
+
+func WithPlainServer(runner *monitor.ServerRunner, url string) PlainOption {
+	return func(p *Plain) {
+		p.server = runner
+		p.serverURL = url
+	}
+}
+
+func NewPlain(cfg *config.Config, out io.Writer, opts ...PlainOption) *Plain {
+	p := &Plain{
+		config:        cfg,
+		out:           out,
+		format:        PlainText,
+		color:         true,
+		theme:         ui.ThemeByName(cfg.Theme),
+		statsInterval: 10 * time.Second,
+		scanner:       monitor.NewScanner(monitor.WithPorts(cfg.DefaultPorts), monitor.WithHost(cfg.Host), monitor.WithPolicy(defaultPolicy(cfg)), monitor.WithReportUntrusted(cfg.ReportUntrusted), monitor.WithSocketDirs(cfg.SocketDirPaths())),
+		system:        monitor.NewSystemMonitor(),
+		alerter:       monitor.NewAlerter(alertRules(cfg.GetAlerts())),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+func (p *Plain) Run(ctx context.Context) error {
+	p.start = time.Now()
+	checkInterval := time.Duration(p.config.CheckInterval) * time.Second
+
+	p.emit(PlainEvent{
+		Event:   "start",
+		Message: fmt.Sprintf("checking every %s, stats every %s", checkInterval, p.statsInterval),
+	})
+
+	var configured []*monitor.Endpoint
+	for _, epCfg := range p.config.GetEndpoints() {
+		ep, err := monitor.NewEndpoint(epCfg.URL)
+		if err != nil {
+			p.emit(PlainEvent{Event: "endpoint", URL: epCfg.URL, Status: "failed", Message: "invalid URL: " + err.Error()})
+			return fmt.Errorf("invalid endpoint URL %q in config: %w", epCfg.URL, err)
+		}
+		ep.Name = epCfg.Name
+		configured = append(configured, ep)
+	}
+
+	if err := p.scanner.SetTLS(defaultTLS(p.config)); err != nil {
+		p.emit(PlainEvent{Event: "scan", Status: "failed", Message: "tls: " + err.Error()})
+	}
+	webhooks, err := newWebhookSender(p.config)
+	if err != nil {
+		p.emit(PlainEvent{Event: "webhook", Status: "failed", Message: "webhooks disabled: " + err.Error()})
+	}
+	p.webhooks = webhooks
+
+	for _, ep := range configured {
+		p.addEndpoint(ep)
+	}
+
+	scanDone := make(chan []*monitor.Endpoint, 1)
+	go func() {
+		scanCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		scanDone <- p.scanner.DiscoverEndpoints(scanCtx)
+	}()
+
+	serverReady := make(chan ServerReadyMsg, 1)
+	if p.server != nil {
+		go func() {
+			serverReady <- DoWaitServer(p.server)().(ServerReadyMsg)
+		}()
+	}
+
+	checkTicker := time.NewTicker(checkInterval)
+	defer checkTicker.Stop()
+	statsTicker := time.NewTicker(p.statsInterval)
+	defer statsTicker.Stop()
+	drainTicker := time.NewTicker(100 * time.Millisecond)
+	defer drainTicker.Stop()
+
+	checkDone := make(chan plainCheck, 1)
+	checking := p.startCheck(ctx, checkDone)
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case found := <-scanDone:
+			for _, ep := range found {
+				ep.Name = ""
+				p.addEndpoint(ep)
+			}
+			p.emit(PlainEvent{Event: "scan", Message: fmt.Sprintf("found %d endpoints", len(found))})
+		case msg := <-serverReady:
+			p.handleServerReady(msg)
+		case <-checkTicker.C:
+			if !checking {
+				checking = p.startCheck(ctx, checkDone)
+			}
+		case c := <-checkDone:
+			checking = false
+			if ctx.Err() == nil {
+				p.applyCheck(c)
+			}
+		case <-statsTicker.C:
+			p.stats()
+		case <-drainTicker.C:
+			p.drainServer()
+		}
+	}
+
+	if checking {
+		<-checkDone
+	}
+	p.drainServer()
+	p.summary()
+
+	if p.webhooks != nil {
+		if err := p.webhooks.Close(5 * time.Second); err != nil {
+			p.emit(PlainEvent{Event: "webhook", Status: "failed", Message: err.Error()})
+		}
+		p.drainWebhookFailures()
+	}
+	return nil
+}
+
+func (p *Plain) drainWebhookFailures() {
+	if p.webhooks == nil {
+		return
+	}
+	for _, failure := range p.webhooks.DrainFailures() {
+		p.emit(PlainEvent{Event: "webhook", Status: "failed", Message: failure})
+	}
+}
+
+func (p *Plain) Down() int {
+	down := 0
+	for _, pe := range p.endpoints {
+		if pe.status == monitor.StatusDown {
+			down++
+		}
+	}
+	return down
+}
+
+func (p *Plain) addEndpoint(ep *monitor.Endpoint) {
+	for _, existing := range p.endpoints {
+		if existing.ep.URL == ep.URL {
+			return
+		}
+	}
+	for _, err := range ApplyEndpoint(p.config, ep) {
+		p.emit(PlainEvent{Event: "endpoint", URL: ep.URL, Status: "failed", Message: err.Error()})
+	}
+	p.endpoints = append(p.endpoints, &plainEndpoint{
+		ep:      ep,
+		metrics: monitor.NewMetrics(1000),
+		status:  monitor.StatusUnknown,
+	})
+	event := PlainEvent{Event: "endpoint", URL: ep.URL, Name: ep.Name}
+	switch {
+	case ep.TLSError != "":
+		event.Message = "untrusted cert: " + ep.TLSError
+	case ep.IsHTTPS && ep.TLS.Insecure:
+		event.Message = "TLS verification off"
+	}
+	p.emit(event)
+}
+
+func (p *Plain) startCheck(ctx context.Context, done chan<- plainCheck) bool {
+	if len(p.endpoints) == 0 {
+		return false
+	}
+
+	endpoints := slices.Clone(p.endpoints)
+	eps := make([]*monitor.Endpoint, len(endpoints))
+	for i, pe := range endpoints {
+		eps[i] = pe.ep
+	}
+	timeout := time.Duration(p.config.Timeout) * time.Second
+
+	go func() {
+		results := monitor.CheckEndpoints(ctx, eps, monitor.WithCheckTimeout(timeout))
+		done <- plainCheck{endpoints: endpoints, results: results}
+	}()
+	return true
+}
+
+func (p *Plain) applyCheck(c plainCheck) {
+	for i, r := range c.results {
+		if r.Requests == 0 {
+			continue
+		}
+		pe := c.endpoints[i]
+		pe.ep.Apply(r.Last)
+
+		result := monitor.RequestResult{Timestamp: time.Now(), Latency: r.AvgLatency, StatusCode: r.LastCode, IsError: r.Failed > 0}
+		if len(r.Failures) > 0 {
+			result.ErrorMessage = r.Failures[0]
+		}
+		pe.metrics.Record(result)
+		pe.ep.Timeline.Record(r.Status)
+
+		stats := pe.metrics.GetStats()
+		if r.Status != pe.status {
+			if pe.status != monitor.StatusUnknown && p.webhooks != nil {
+				p.webhooks.Send(monitor.StatusChangeEvent(pe.ep, pe.status, stats))
+			}
+			p.emit(PlainEvent{
+				Event:     "status",
+				URL:       pe.ep.URL,
+				Name:      pe.ep.Name,
+				Status:    string(r.Status),
+				Previous:  string(pe.status),
+				LatencyMs: millis(r.AvgLatency),
+				Message:   result.ErrorMessage,
+			})
+			pe.status = r.Status
+		}
+		p.checkFlapping(pe)
+
+		for _, event := range p.alerter.Observe(pe.ep, stats) {
+			if p.webhooks != nil {
+				p.webhooks.Send(monitor.AlertWebhookEvent(event, stats))
+			}
+			message := event.Message
+			if event.Suppressed > 0 {
+				message += fmt.Sprintf(" (%d repeats suppressed)", event.Suppressed)
+			}
+			p.emit(PlainEvent{
+				Event:   "alert",
+				Rule:    event.Rule,
+				URL:     event.URL,
+				Name:    pe.ep.Name,
+				Status:  event.State(),
+				Message: message,
+			})
+		}
+	}
+
+	for _, failure := range p.alerter.DrainFailures() {
+		p.emit(PlainEvent{Event: "alert", Status: "failed", Message: failure})
+	}
+	p.drainWebhookFailures()
+}
+
+func (p *Plain) checkFlapping(pe *plainEndpoint) {
+	window := time.Duration(p.config.FlapWindow) * time.Second
+	flapping := pe.ep.Timeline.Flapping(p.config.FlapThreshold, window)
+	if flapping == pe.flapping {
+		return
+	}
+	pe.flapping = flapping
+
+	status := "stopped"
+	message := "status is stable again"
+	if flapping {
+		status = "started"
+		message = fmt.Sprintf("%d status changes in %s", pe.ep.Timeline.Transitions(window), window)
+	}
+	p.emit(PlainEvent{
+		Event:    "flapping",
+		URL:      pe.ep.URL,
+		Name:     pe.ep.Name,
+		Status:   status,
+		Flapping: flapping,
+		Message:  message,
+	})
+}
+
+func (p *Plain) stats() {
+	for _, pe := range p.endpoints {
+		stats := pe.metrics.GetStats()
+		if stats.TotalRequests == 0 {
+			continue
+		}
+		p.emit(PlainEvent{
+			Event:     "stats",
+			URL:       pe.ep.URL,
+			Name:      pe.ep.Name,
+			Status:    string(pe.status),
+			Requests:  stats.TotalRequests,
+			Errors:    stats.TotalErrors,
+			ErrorRate: stats.ErrorRate,
+			AvgMs:     millis(stats.AvgLatency),
+			P95Ms:     millis(stats.P95),
+			MaxMs:     millis(stats.MaxLatency),
+			Protocol:  monitor.ProtoName(pe.ep.Proto),
+			ConnectMs: millis(stats.AvgConnect),
+			Drops:     stats.Disconnects,
+		})
+	}
+	p.emit(PlainEvent{Event: "system", System: p.sampleSystem()})
+}
+
+func (p *Plain) sampleSystem() *PlainSystem {
+	m := p.system.GetMetrics()
+	s := PlainSystem{
+		CPUPercent:     m.CPUPercent,
+		CPUPerCore:     m.CPUPerCore,
+		RAMUsed:        m.RAMUsed,
+		RAMPercent:     m.RAMPercent,
+		SwapUsed:       m.SwapUsed,
+		Load1:          m.Load1,
+		Load5:          m.Load5,
+		Load15:         m.Load15,
+		LoopbackRxRate: m.LoopbackRxRate,
+		LoopbackTxRate: m.LoopbackTxRate,
+		DiskReadRate:   m.DiskReadRate,
+		DiskWriteRate:  m.DiskWriteRate,
+		SelfCPUPercent: m.SelfCPUPercent,
+		SelfRSS:        m.SelfRSS,
+		Goroutines:     m.Goroutines,
+	}
+
+	peak := &p.systemPeak
+	peak.CPUPercent = max(peak.CPUPercent, s.CPUPercent)
+	peak.RAMUsed = max(peak.RAMUsed, s.RAMUsed)
+	peak.RAMPercent = max(peak.RAMPercent, s.RAMPercent)
+	peak.SwapUsed = max(peak.SwapUsed, s.SwapUsed)
+	peak.Load1 = max(peak.Load1, s.Load1)
+	peak.Load5 = max(peak.Load5, s.Load5)
+	peak.Load15 = max(peak.Load15, s.Load15)
+	peak.LoopbackRxRate = max(peak.LoopbackRxRate, s.LoopbackRxRate)
+	peak.LoopbackTxRate = max(peak.LoopbackTxRate, s.LoopbackTxRate)
+	peak.DiskReadRate = max(peak.DiskReadRate, s.DiskReadRate)
+	peak.DiskWriteRate = max(peak.DiskWriteRate, s.DiskWriteRate)
+	peak.SelfCPUPercent = max(peak.SelfCPUPercent, s.SelfCPUPercent)
+	peak.SelfRSS = max(peak.SelfRSS, s.SelfRSS)
+	peak.Goroutines = max(peak.Goroutines, s.Goroutines)
+	return &s
+}
+
+func (p *Plain) summary() {
+	p.emit(PlainEvent{
+		Event:    "summary",
+		Duration: time.Since(p.start).Seconds(),
+		Message:  fmt.Sprintf("%d of %d endpoints down", p.Down(), len(p.endpoints)),
+	})
+	for _, pe := range p.endpoints {
+		stats := pe.metrics.GetStats()
+		timeline := pe.ep.Timeline.Summary()
+		p.emit(PlainEvent{
+			Event:     "endpoint_summary",
+			URL:       pe.ep.URL,
+			Name:      pe.ep.Name,
+			Status:    string(pe.status),
+			Requests:  stats.TotalRequests,
+			Errors:    stats.TotalErrors,
+			ErrorRate: stats.ErrorRate,
+			AvgMs:     millis(stats.AvgLatency),
+			P95Ms:     millis(stats.P95),
+			MaxMs:     millis(stats.MaxLatency),
+			Protocol:  monitor.ProtoName(pe.ep.Proto),
+			ConnectMs: millis(stats.AvgConnect),
+			Drops:     stats.Disconnects,
+			Uptime:    timeline.UptimeSession,
+			Uptime1h:  timeline.Uptime1h,
+			Uptime24h: timeline.Uptime24h,
+			MTTR:      timeline.MTTR.Seconds(),
+			Changes:   timeline.Transitions,
+			Flapping:  pe.flapping,
+		})
+	}
+	p.sampleSystem()
+	peak := p.systemPeak
+	p.emit(PlainEvent{Event: "system_summary", System: &peak})
+}
+
+func (p *Plain) handleServerReady(msg ServerReadyMsg) {
+	if msg.Err != nil {
+		p.emit(PlainEvent{Event: "server", Status: "failed", Message: "server did not become ready: " + msg.Err.Error()})
+		return
+	}
+	ep, err := monitor.NewEndpoint(p.serverURL)
+	if err != nil {
+		p.emit(PlainEvent{Event: "server", Status: "failed", Message: "invalid server endpoint " + p.serverURL + ": " + err.Error()})
+		return
+	}
+	ep.Name = ""
+	p.addEndpoint(ep)
+}
+
+func (p *Plain) drainServer() {
+	if p.server == nil {
+		return
+	}
+	for _, ev := range p.server.DrainEvents() {
+		p.emit(PlainEvent{Time: ev.Time, Event: "server", Status: string(ev.Kind), PID: ev.PID, Message: ev.Message})
+	}
+	for _, line := range p.server.DrainOutput() {
+		p.emit(PlainEvent{Time: line.Time, Event: "output", Stream: line.Stream, Message: line.Text})
+	}
+}
+
+func (p *Plain) emit(ev PlainEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	if p.format == PlainNDJSON {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		p.out.Write(append(data, '\n'))
+		return
+	}
+	fmt.Fprintln(p.out, p.formatText(ev))
+}
+
+func (p *Plain) formatText(ev PlainEvent) string {
+	stamp := ev.Time.Format("15:04:05")
+	if p.color {
+		stamp = p.theme.ColorMuted(stamp)
+	}
+
+	target := ev.URL
+	if ev.Name != "" {
+		target = ev.Name + " (" + ev.URL + ")"
+	}
+
+	var line string
+	switch ev.Event {
+	case "start":
+		line = "LocalPulse " + ev.Message
+	case "scan":
+		line = "scan: " + ev.Message
+	case "endpoint":
+		line = "watching " + target
+	case "status":
+		line = p.statusText(ev.Status) + " " + target + " (was " + ev.Previous + ", " + formatMillis(ev.LatencyMs) + ")"
+		if ev.Message != "" {
+			line += ": " + ev.Message
+		}
+	case "stats":
+		line = "stats " + target + " " + p.statusWord(ev.Status) + " " + p.statsText(ev)
+	case "endpoint_summary":
+		line = "  " + target + " " + p.statusWord(ev.Status) + " " + p.statsText(ev) +
+			fmt.Sprintf(", uptime %.1f%% (1h %.1f%%, 24h %.1f%%), %d changes", ev.Uptime, ev.Uptime1h, ev.Uptime24h, ev.Changes)
+		if ev.MTTR > 0 {
+			line += ", MTTR " + time.Duration(ev.MTTR*float64(time.Second)).Round(time.Millisecond).String()
+		}
+		if ev.Flapping {
+			line += ", flapping"
+		}
+	case "system":
+		line = "system " + systemText(ev.System)
+	case "system_summary":
+		line = "  system peak " + systemText(ev.System)
+	case "flapping":
+		line = "flapping " + ev.Status + " " + target + ": " + ev.Message
+	case "summary":
+		line = fmt.Sprintf("summary after %s: %s", time.Duration(ev.Duration*float64(time.Second)).Round(time.Second), ev.Message)
+	case "server":
+		line = "server " + ev.Status
+		if ev.PID > 0 {
+			line += fmt.Sprintf(" pid %d", ev.PID)
+		}
+		line += ": " + ev.Message
+	case "output":
+		line = "[" + ev.Stream + "] " + ev.Message
+	case "webhook":
+		line = "webhook " + ev.Status + ": " + ev.Message
+	case "alert":
+		line = "alert " + ev.Status
+		if ev.Rule != "" {
+			line += " [" + ev.Rule + "]"
+		}
+		line += " " + ev.Message
+	default:
+		line = ev.Event + " " + ev.Message
+	}
+	return stamp + " " + line
+}
+
+func (p *Plain) statsText(ev PlainEvent) string {
+	text := fmt.Sprintf("%d req, %d err (%.1f%%), avg %s p95 %s max %s",
+		ev.Requests, ev.Errors, ev.ErrorRate, formatMillis(ev.AvgMs), formatMillis(ev.P95Ms), formatMillis(ev.MaxMs))
+	if ev.Protocol != "" {
+		text += " over " + ev.Protocol
+	}
+	if ev.ConnectMs > 0 {
+		text += ", connect " + formatMillis(ev.ConnectMs)
+	}
+	if ev.Drops > 0 {
+		text += fmt.Sprintf(", %d disconnects", ev.Drops)
+	}
+	return text
+}
+
+func systemText(s *PlainSystem) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("cpu %.1f%% ram %.1f%% (%s) swap %s, load %.2f %.2f %.2f, loopback rx %s/s tx %s/s, disk r %s/s w %s/s, localpulse cpu %.1f%% rss %s, %d goroutines",
+		s.CPUPercent, s.RAMPercent, ui.FormatBytes(int64(s.RAMUsed)), ui.FormatBytes(int64(s.SwapUsed)),
+		s.Load1, s.Load5, s.Load15,
+		ui.FormatBytes(int64(s.LoopbackRxRate)), ui.FormatBytes(int64(s.LoopbackTxRate)),
+		ui.FormatBytes(int64(s.DiskReadRate)), ui.FormatBytes(int64(s.DiskWriteRate)),
+		s.SelfCPUPercent, ui.FormatBytes(int64(s.SelfRSS)), s.Goroutines)
+}
+
+func (p *Plain) statusText(status string) string {
+	if !p.color {
+		return strings.ToUpper(status)
+	}
+	ep := monitor.Endpoint{Status: monitor.EndpointStatus(status)}
+	return ep.StatusIcon() + " " + p.statusWord(status)
+}
+
+func (p *Plain) statusWord(status string) string {
+	if !p.color {
+		return status
+	}
+	switch monitor.EndpointStatus(status) {
+	case monitor.StatusHealthy:
+		return p.theme.ColorSuccess(status)
+	case monitor.StatusSlow:
+		return p.theme.ColorWarning(status)
+	case monitor.StatusDown:
+		return p.theme.ColorError(status)
+	}
+	return p.theme.ColorMuted(status)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func formatMillis(ms float64) string {
+	return ui.FormatLatency(int64(ms * float64(time.Millisecond)))
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Brattlof/localpulse/config"
+)
+
+var plainTestTime = time.Date(2026, 3, 14, 9, 26, 53, 0, time.UTC)
+
+var plainTestSystem = &PlainSystem{
+	CPUPercent:     42.5,
+	CPUPerCore:     []float64{40, 45},
+	RAMUsed:        8 << 30,
+	RAMPercent:     50,
+	SwapUsed:       1 << 20,
+	Load1:          1.5,
+	Load5:          1.25,
+	Load15:         1,
+	LoopbackRxRate: 2048,
+	LoopbackTxRate: 1024,
+	DiskReadRate:   0,
+	DiskWriteRate:  4096,
+	SelfCPUPercent: 12.5,
+	SelfRSS:        32 << 20,
+	Goroutines:     18,
+}
+
+func plainTestEvents() []PlainEvent {
+	return []PlainEvent{
+		{Event: "start", Message: "checking every 1s, stats every 10s"},
+		{Event: "endpoint", URL: "http://localhost:3000/health", Name: "API"},
+		{Event: "status", URL: "http://localhost:3000/health", Name: "API", Status: "down", Previous: "unknown", LatencyMs: 12.5, Message: "status 503, expected 200-399"},
+		{Event: "stats", URL: "http://localhost:3000/health", Name: "API", Status: "down", Requests: 4, Errors: 1, ErrorRate: 25, AvgMs: 10, P95Ms: 20, MaxMs: 30, Protocol: "HTTP/1.1"},
+		{Event: "system", System: plainTestSystem},
+		{Event: "flapping", URL: "http://localhost:3000/health", Name: "API", Status: "started", Flapping: true, Message: "5 status changes in 5m0s"},
+		{Event: "alert", Rule: "slow-api", URL: "http://localhost:3000/health", Status: "firing", Message: "p95 above 300ms"},
+		{Event: "summary", Duration: 61, Message: "1 of 1 endpoints down"},
+		{Event: "endpoint_summary", URL: "http://localhost:3000/health", Name: "API", Status: "down", Requests: 4, Errors: 1, ErrorRate: 25, AvgMs: 10, P95Ms: 20, MaxMs: 30, Uptime: 75, Uptime1h: 75, Uptime24h: 75, MTTR: 2, Changes: 5, Flapping: true},
+		{Event: "system_summary", System: plainTestSystem},
+	}
+}
+
+func emitAll(p *Plain, events []PlainEvent) {
+	for _, ev := range events {
+		ev.Time = plainTestTime
+		p.emit(ev)
+	}
+}
+
+func TestPlain_TextGolden(t *testing.T) {
+	var buf bytes.Buffer
+	emitAll(NewPlain(config.DefaultConfig(), &buf, WithColor(false)), plainTestEvents())
+
+	want := `09:26:53 LocalPulse checking every 1s, stats every 10s
+09:26:53 watching API (http://localhost:3000/health)
+09:26:53 DOWN API (http://localhost:3000/health) (was unknown, 12ms): status 503, expected 200-399
+09:26:53 stats API (http://localhost:3000/health) down 4 req, 1 err (25.0%), avg 10ms p95 20ms max 30ms over HTTP/1.1
+09:26:53 system cpu 42.5% ram 50.0% (8GB) swap 1MB, load 1.50 1.25 1.00, loopback rx 2KB/s tx 1KB/s, disk r 0B/s w 4KB/s, localpulse cpu 12.5% rss 32MB, 18 goroutines
+09:26:53 flapping started API (http://localhost:3000/health): 5 status changes in 5m0s
+09:26:53 alert firing [slow-api] p95 above 300ms
+09:26:53 summary after 1m1s: 1 of 1 endpoints down
+09:26:53   API (http://localhost:3000/health) down 4 req, 1 err (25.0%), avg 10ms p95 20ms max 30ms, uptime 75.0% (1h 75.0%, 24h 75.0%), 5 changes, MTTR 2s, flapping
+09:26:53   system peak cpu 42.5% ram 50.0% (8GB) swap 1MB, load 1.50 1.25 1.00, loopback rx 2KB/s tx 1KB/s, disk r 0B/s w 4KB/s, localpulse cpu 12.5% rss 32MB, 18 goroutines
+`
+	if got := buf.String(); got != want {
+		t.Errorf("text output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlain_NDJSONGolden(t *testing.T) {
+	var buf bytes.Buffer
+	emitAll(NewPlain(config.DefaultConfig(), &buf, WithPlainFormat(PlainNDJSON)), plainTestEvents())
+
+	want := `{"time":"2026-03-14T09:26:53Z","event":"start","message":"checking every 1s, stats every 10s"}
+{"time":"2026-03-14T09:26:53Z","event":"endpoint","url":"http://localhost:3000/health","name":"API"}
+{"time":"2026-03-14T09:26:53Z","event":"status","url":"http://localhost:3000/health","name":"API","status":"down","previous":"unknown","latency_ms":12.5,"message":"status 503, expected 200-399"}
+{"time":"2026-03-14T09:26:53Z","event":"stats","url":"http://localhost:3000/health","name":"API","status":"down","requests":4,"errors":1,"error_rate":25,"avg_ms":10,"p95_ms":20,"max_ms":30,"protocol":"HTTP/1.1"}
+{"time":"2026-03-14T09:26:53Z","event":"system","system":{"cpu_percent":42.5,"cpu_per_core":[40,45],"ram_used_bytes":8589934592,"ram_percent":50,"swap_used_bytes":1048576,"load1":1.5,"load5":1.25,"load15":1,"loopback_rx_bytes_per_second":2048,"loopback_tx_bytes_per_second":1024,"disk_read_bytes_per_second":0,"disk_write_bytes_per_second":4096,"self_cpu_percent":12.5,"self_rss_bytes":33554432,"goroutines":18}}
+{"time":"2026-03-14T09:26:53Z","event":"flapping","url":"http://localhost:3000/health","name":"API","status":"started","flapping":true,"message":"5 status changes in 5m0s"}
+{"time":"2026-03-14T09:26:53Z","event":"alert","rule":"slow-api","url":"http://localhost:3000/health","status":"firing","message":"p95 above 300ms"}
+{"time":"2026-03-14T09:26:53Z","event":"summary","duration_seconds":61,"message":"1 of 1 endpoints down"}
+{"time":"2026-03-14T09:26:53Z","event":"endpoint_summary","url":"http://localhost:3000/health","name":"API","status":"down","requests":4,"errors":1,"error_rate":25,"avg_ms":10,"p95_ms":20,"max_ms":30,"uptime_percent":75,"uptime_1h_percent":75,"uptime_24h_percent":75,"mttr_seconds":2,"transitions":5,"flapping":true}
+{"time":"2026-03-14T09:26:53Z","event":"system_summary","system":{"cpu_percent":42.5,"cpu_per_core":[40,45],"ram_used_bytes":8589934592,"ram_percent":50,"swap_used_bytes":1048576,"load1":1.5,"load5":1.25,"load15":1,"loopback_rx_bytes_per_second":2048,"loopback_tx_bytes_per_second":1024,"disk_read_bytes_per_second":0,"disk_write_bytes_per_second":4096,"self_cpu_percent":12.5,"self_rss_bytes":33554432,"goroutines":18}}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("ndjson output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlain_Run(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.DefaultPorts = []int{1}
+	cfg.AddEndpoint(srv.URL, "API")
+	var buf bytes.Buffer
+	p := NewPlain(cfg, &buf, WithPlainFormat(PlainNDJSON))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	var events []string
+	var status PlainEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var ev PlainEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("invalid ndjson: %v", err)
+		}
+		events = append(events, ev.Event)
+		if ev.Event == "status" {
+			status = ev
+		}
+	}
+	for _, want := range []string{"start", "endpoint", "status", "summary", "endpoint_summary", "system_summary"} {
+		if !slices.Contains(events, want) {
+			t.Errorf("events = %v, missing %q", events, want)
+		}
+	}
+	if status.Status != "down" || status.Message != "status 503, expected 200-399" {
+		t.Errorf("status event = %+v, want down on 503", status)
+	}
+	if recent := p.endpoints[0].metrics.GetRecentResults(1); p.Down() != 1 || len(recent) != 1 || recent[0].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Down() = %d, recent = %+v, want one 503 recorded", p.Down(), recent)
+	}
+}
+
+func TestPlain_RunStatsDuringCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.DefaultPorts = []int{1}
+	cfg.AddEndpoint(srv.URL, "API")
+	var buf bytes.Buffer
+	p := NewPlain(cfg, &buf, WithPlainFormat(PlainNDJSON), WithStatsInterval(time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1300*time.Millisecond)
+	defer cancel()
+	if err := p.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	stats, system := 0, 0
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var ev PlainEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("invalid ndjson: %v", err)
+		}
+		if ev.Event == "stats" {
+			stats++
+			if ev.Status != "healthy" || ev.Protocol != "http/1.1" {
+				t.Errorf("stats event = %+v, want healthy over http/1.1", ev)
+			}
+		}
+		if ev.Event == "system" {
+			system++
+			if ev.System == nil || ev.System.Goroutines == 0 {
+				t.Errorf("system event = %+v, want sampled metrics", ev)
+			}
+		}
+	}
+	if stats == 0 {
+		t.Error("no stats events after the check finished")
+	}
+	if system == 0 {
+		t.Error("no system events alongside the stats")
+	}
+}
@@ -0,0 +1,271 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Brattlof/localpulse/config"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+var (
+	tokenMu      sync.Mutex
+	tokenSources = make(map[string]*monitor.TokenSource)
+)
+
+func endpointConfig(cfg *config.Config, url string) (config.EndpointConfig, bool) {
+	endpoints := cfg.GetEndpoints()
+	for _, ep := range endpoints {
+		if ep.URL == url {
+			return ep, true
+		}
+	}
+	for _, ep := range endpoints {
+		if parsed, err := monitor.NewEndpoint(ep.URL); err == nil && parsed.URL == url {
+			return ep, true
+		}
+	}
+	return config.EndpointConfig{}, false
+}
+
+func defaultPolicy(cfg *config.Config) monitor.CheckPolicy {
+	policy := monitor.CheckPolicy{
+		SlowThreshold: time.Duration(cfg.SlowThreshold) * time.Millisecond,
+		DownThreshold: time.Duration(cfg.DownThreshold) * time.Millisecond,
+		Timeout:       time.Duration(cfg.Timeout) * time.Second,
+	}
+	if accept, err := monitor.ParseStatusRanges(cfg.AcceptStatus); err == nil {
+		policy.Accept = accept
+	}
+	return monitor.DefaultPolicy().Merge(policy)
+}
+
+func policyFor(cfg *config.Config, url string) monitor.CheckPolicy {
+	policy := defaultPolicy(cfg)
+	ep, ok := endpointConfig(cfg, url)
+	if !ok {
+		return policy
+	}
+	override := monitor.CheckPolicy{
+		SlowThreshold: time.Duration(ep.SlowThresholdMs) * time.Millisecond,
+		DownThreshold: time.Duration(ep.DownThresholdMs) * time.Millisecond,
+		Timeout:       time.Duration(ep.Timeout) * time.Second,
+	}
+	if accept, err := monitor.ParseStatusRanges(ep.AcceptStatus); err == nil {
+		override.Accept = accept
+	}
+	return policy.Merge(override)
+}
+
+func loadRateFor(cfg *config.Config, url string) monitor.LoadRate {
+	ep, _ := endpointConfig(cfg, url)
+	return monitor.LoadRate{RPS: ep.RPS, Weight: ep.Weight}
+}
+
+func protocolFor(cfg *config.Config, url string) (monitor.Protocol, int) {
+	ep, _ := endpointConfig(cfg, url)
+	protocol, err := monitor.ParseProtocol(ep.Protocol)
+	if err != nil {
+		protocol = monitor.ProtocolAuto
+	}
+	return protocol, ep.Connections
+}
+
+func webSocketFor(cfg *config.Config, url string) *monitor.WebSocketScript {
+	ep, _ := endpointConfig(cfg, url)
+	if len(ep.Messages) == 0 {
+		return nil
+	}
+	return &monitor.WebSocketScript{Messages: append([]string(nil), ep.Messages...), Match: ep.Match}
+}
+
+func tlsOptions(t config.TLSConfig) monitor.TLSOptions {
+	return monitor.TLSOptions{
+		CAFile:     t.CAFile,
+		CertFile:   t.CertFile,
+		KeyFile:    t.KeyFile,
+		ServerName: t.ServerName,
+		MinVersion: t.MinVersion,
+		Insecure:   t.Insecure,
+	}
+}
+
+func defaultTLS(cfg *config.Config) monitor.TLSOptions {
+	return tlsOptions(cfg.DefaultTLS())
+}
+
+func tlsFor(cfg *config.Config, url string) monitor.TLSOptions {
+	ep, ok := endpointConfig(cfg, url)
+	if !ok {
+		return defaultTLS(cfg)
+	}
+	return tlsOptions(cfg.EndpointTLS(ep))
+}
+
+func loadFeeder(cfg *config.Config, name string) (*monitor.Feeder, error) {
+	f, err := cfg.Feeder(name)
+	if err != nil {
+		return nil, err
+	}
+	return monitor.LoadFeeder(f.Name, f.File, monitor.FeedMode(f.Mode))
+}
+
+func requestFor(cfg *config.Config, url string) (*monitor.RequestTemplate, error) {
+	ep, ok := endpointConfig(cfg, url)
+	if !ok || (ep.Method == "" && len(ep.Headers) == 0 && ep.Body == "" && ep.Feed == "" && !strings.Contains(ep.URL, "${")) {
+		return nil, nil
+	}
+
+	raw := ep.URL
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = "http://" + raw
+	}
+	tmpl := &monitor.RequestTemplate{
+		Method:  ep.Method,
+		URL:     raw,
+		Headers: ep.Headers,
+		Body:    ep.Body,
+	}
+	if ep.Feed != "" {
+		feeder, err := loadFeeder(cfg, ep.Feed)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.Feeder = feeder
+	}
+	return tmpl, nil
+}
+
+func authByName(cfg *config.Config, name string) (*monitor.Auth, error) {
+	a, err := cfg.AuthProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return buildAuth(a)
+}
+
+func authFor(cfg *config.Config, url string) (*monitor.Auth, error) {
+	if ep, ok := endpointConfig(cfg, url); ok && ep.Auth != "" {
+		return authByName(cfg, ep.Auth)
+	}
+
+	name := url
+	if parsed, err := monitor.NewEndpoint(url); err == nil {
+		url, name = parsed.URL, parsed.Name
+	}
+	for _, a := range cfg.GetAuthProfiles() {
+		for _, pattern := range a.Endpoints {
+			if matchEndpoint(pattern, url, name) {
+				return buildAuth(a)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func matchEndpoint(pattern, url, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(url, prefix) || strings.HasPrefix(name, prefix)
+	}
+	if pattern == url || pattern == name {
+		return true
+	}
+	parsed, err := monitor.NewEndpoint(pattern)
+	return err == nil && parsed.URL == url
+}
+
+func buildAuth(a config.AuthConfig) (*monitor.Auth, error) {
+	auth := &monitor.Auth{Type: monitor.AuthType(a.Type), Cookies: a.Cookies}
+	var err error
+	switch auth.Type {
+	case monitor.AuthBearer:
+		auth.Token, err = secretEnv(a.Name, a.TokenEnv)
+	case monitor.AuthBasic:
+		auth.Username = a.Username
+		auth.Password, err = secretEnv(a.Name, a.PasswordEnv)
+	case monitor.AuthOAuth2:
+		var secret string
+		if secret, err = secretEnv(a.Name, a.ClientSecretEnv); err != nil {
+			break
+		}
+		key := strings.Join([]string{a.Name, a.TokenURL, a.ClientID, secret, strings.Join(a.Scopes, " ")}, "\x00")
+		tokenMu.Lock()
+		if tokenSources[key] == nil {
+			tokenSources[key] = monitor.NewTokenSource(a.TokenURL, a.ClientID, secret, a.Scopes)
+		}
+		auth.OAuth2 = tokenSources[key]
+		tokenMu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return auth, nil
+}
+
+func secretEnv(profile, name string) (string, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return "", fmt.Errorf("auth profile %s: environment variable %s is not set", profile, name)
+	}
+	return value, nil
+}
+
+func buildScenario(s config.ScenarioConfig) (*monitor.Scenario, error) {
+	sc := &monitor.Scenario{Name: s.Name, Vars: s.Vars}
+	for _, step := range s.Steps {
+		ms := monitor.ScenarioStep{
+			Name:    step.Name,
+			Method:  step.Method,
+			URL:     step.URL,
+			Headers: step.Headers,
+			Body:    step.Body,
+		}
+		for _, name := range slices.Sorted(maps.Keys(step.Extract)) {
+			e, err := monitor.ParseExtraction(name, step.Extract[name])
+			if err != nil {
+				return nil, fmt.Errorf("scenario %s: extract %s: %w", s.Name, name, err)
+			}
+			ms.Extract = append(ms.Extract, e)
+		}
+		sc.Steps = append(sc.Steps, ms)
+	}
+	return sc, nil
+}
+
+func ApplyEndpoint(cfg *config.Config, ep *monitor.Endpoint) []error {
+	var errs []error
+	ep.Policy = policyFor(cfg, ep.URL)
+	if err := ep.SetTLS(tlsFor(cfg, ep.URL)); err != nil {
+		errs = append(errs, fmt.Errorf("tls: %w", err))
+	}
+	if ep.Scenario != nil {
+		return errs
+	}
+	if err := ep.SetProtocol(protocolFor(cfg, ep.URL)); err != nil {
+		errs = append(errs, fmt.Errorf("protocol: %w", err))
+	}
+
+	req, err := requestFor(cfg, ep.URL)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("request template: %w", err))
+	}
+	if req != nil && ep.Request != nil && req.Feeder.Equal(ep.Request.Feeder) {
+		req.Feeder = ep.Request.Feeder
+	}
+	ep.Request = req
+	if ep.IsWebSocket() {
+		ep.WebSocket = webSocketFor(cfg, ep.URL)
+	}
+
+	auth, err := authFor(cfg, ep.URL)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("auth: %w", err))
+	}
+	ep.Auth = auth
+	return errs
+}
@@ -0,0 +1,235 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brattlof/localpulse/config"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func TestPolicyFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SlowThreshold = 300
+	cfg.AcceptStatus = "2xx,401"
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "http://localhost:3000/reports", SlowThresholdMs: 5000, Timeout: 30, AcceptStatus: "200"},
+		{URL: "localhost:8080/health", DownThresholdMs: 2000},
+		{URL: "unix:///var/run/docker.sock:/_ping", Timeout: 2},
+	}
+
+	global := policyFor(cfg, "http://localhost:4000/")
+	if global.SlowThreshold != 300*time.Millisecond || global.Timeout != 5*time.Second {
+		t.Errorf("global policy = %+v, want 300ms slow and 5s timeout", global)
+	}
+	if !global.Accepts(401) || global.Accepts(404) {
+		t.Errorf("global policy accepts = %v, want 2xx and 401", global.Accept)
+	}
+
+	reports := policyFor(cfg, "http://localhost:3000/reports")
+	if reports.SlowThreshold != 5*time.Second || reports.Timeout != 30*time.Second || reports.Accepts(204) {
+		t.Errorf("reports policy = %+v, want 5s slow, 30s timeout and only 200", reports)
+	}
+
+	health := policyFor(cfg, "http://localhost:8080/health")
+	if health.DownThreshold != 2*time.Second || health.SlowThreshold != 300*time.Millisecond {
+		t.Errorf("health policy = %+v, want the global slow threshold and a 2s down threshold", health)
+	}
+
+	if socket := policyFor(cfg, "unix:///var/run/docker.sock:/_ping"); socket.Timeout != 2*time.Second {
+		t.Errorf("socket policy timeout = %v, want 2s", socket.Timeout)
+	}
+}
+
+func TestLoadRateFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "localhost:3000/search", Weight: 25},
+		{URL: "http://localhost:3000/write", RPS: 2.5},
+	}
+
+	if got := loadRateFor(cfg, "http://localhost:3000/search"); got != (monitor.LoadRate{Weight: 25}) {
+		t.Errorf("loadRateFor(search) = %+v, want weight 25", got)
+	}
+	if got := loadRateFor(cfg, "http://localhost:3000/write"); got != (monitor.LoadRate{RPS: 2.5}) {
+		t.Errorf("loadRateFor(write) = %+v, want 2.5 req/s", got)
+	}
+	if got := loadRateFor(cfg, "http://localhost:4000/"); got != (monitor.LoadRate{}) {
+		t.Errorf("loadRateFor(unknown) = %+v, want zero", got)
+	}
+}
+
+func TestProtocolFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "localhost:3000/grpc", Protocol: "H2C", Connections: 4},
+		{URL: "https://localhost:8443/", Protocol: "http1"},
+	}
+
+	if p, conns := protocolFor(cfg, "http://localhost:3000/grpc"); p != monitor.ProtocolH2C || conns != 4 {
+		t.Errorf("protocolFor(grpc) = %q, %d, want h2c over 4 connections", p, conns)
+	}
+	if p, conns := protocolFor(cfg, "https://localhost:8443/"); p != monitor.ProtocolHTTP1 || conns != 0 {
+		t.Errorf("protocolFor(8443) = %q, %d, want http1", p, conns)
+	}
+	if p, _ := protocolFor(cfg, "http://localhost:4000/"); p != monitor.ProtocolAuto {
+		t.Errorf("protocolFor(unknown) = %q, want auto", p)
+	}
+}
+
+func TestWebSocketFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "ws://localhost:8080/chat", Messages: []string{`{"id":${seq}}`, "ping"}, Match: `"id":${seq}`},
+		{URL: "wss://localhost:8443/feed"},
+	}
+	if errs := ValidateConfig(cfg); len(errs) != 0 {
+		t.Fatalf("ValidateConfig() = %v", errs)
+	}
+
+	script := webSocketFor(cfg, "ws://localhost:8080/chat")
+	if script == nil || len(script.Messages) != 2 || script.Match != `"id":${seq}` {
+		t.Errorf("webSocketFor(chat) = %+v, want the configured script", script)
+	}
+	if script := webSocketFor(cfg, "wss://localhost:8443/feed"); script != nil {
+		t.Errorf("webSocketFor(feed) = %+v, want nil without messages", script)
+	}
+}
+
+func TestApplyEndpoint(t *testing.T) {
+	t.Setenv("LP_TEST_TOKEN", "tok")
+	feed := filepath.Join(t.TempDir(), "users.csv")
+	os.WriteFile(feed, []byte("id\n1\n2\n"), 0o644)
+	cfg := config.DefaultConfig()
+	cfg.Feeders = []config.FeederConfig{{Name: "users", File: feed}}
+	cfg.AuthProfiles = []config.AuthConfig{{Name: "api", Type: "bearer", TokenEnv: "LP_TEST_TOKEN"}}
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "localhost:3000/users", Method: "POST", Body: `{"id":${id}}`, Feed: "users", Auth: "api", Protocol: "h2c", AcceptStatus: "201"},
+		{URL: "ws://localhost:8080/chat", Messages: []string{"hi"}},
+		{URL: "localhost:4000/", Auth: "missing"},
+	}
+
+	ep, _ := monitor.NewEndpoint("localhost:3000/users")
+	if errs := ApplyEndpoint(cfg, ep); len(errs) != 0 {
+		t.Fatalf("ApplyEndpoint() = %v", errs)
+	}
+	if ep.Request == nil || ep.Request.Method != "POST" || ep.Request.Feeder == nil || ep.Auth == nil || ep.Auth.Token != "tok" ||
+		ep.Protocol != monitor.ProtocolH2C || ep.Policy.CheckStatus(201) != nil {
+		t.Errorf("ApplyEndpoint() left %+v, want request, feeder, auth, protocol and policy applied", ep)
+	}
+	feeder := ep.Request.Feeder
+	ApplyEndpoint(cfg, ep)
+	if ep.Request.Feeder != feeder {
+		t.Error("reapplying an unchanged feeder replaced it")
+	}
+
+	ws, _ := monitor.NewEndpoint("ws://localhost:8080/chat")
+	ApplyEndpoint(cfg, ws)
+	if ws.WebSocket == nil || ws.WebSocket.Messages[0] != "hi" {
+		t.Errorf("ApplyEndpoint(ws) script = %+v", ws.WebSocket)
+	}
+
+	broken, _ := monitor.NewEndpoint("localhost:4000/")
+	if errs := ApplyEndpoint(cfg, broken); len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "auth: ") {
+		t.Errorf("ApplyEndpoint(unknown auth) = %v, want one auth error", errs)
+	}
+}
+
+func TestAuthFor(t *testing.T) {
+	t.Setenv("LP_TEST_TOKEN", "tok")
+	t.Setenv("LP_TEST_SECRET", "shh")
+	cfg := config.DefaultConfig()
+	cfg.AuthProfiles = []config.AuthConfig{
+		{Name: "api", Type: "bearer", TokenEnv: "LP_TEST_TOKEN", Endpoints: []string{"localhost:3000/api/*"}},
+		{Name: "admin", Type: "oauth2", TokenURL: "http://localhost:9000/token", ClientID: "lp", ClientSecretEnv: "LP_TEST_SECRET", Cookies: true},
+		{Name: "broken", Type: "basic", Username: "dev", PasswordEnv: "LP_TEST_UNSET"},
+	}
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "localhost:3000/api/admin", Auth: "admin"},
+		{URL: "localhost:3000/legacy", Auth: "broken"},
+	}
+
+	auth, err := authFor(cfg, "http://localhost:3000/api/users")
+	if err != nil || auth == nil || auth.Type != monitor.AuthBearer || auth.Token != "tok" {
+		t.Errorf("authFor(api/users) = %+v, %v, want the api bearer profile", auth, err)
+	}
+	admin, err := authFor(cfg, "http://localhost:3000/api/admin")
+	if err != nil || admin == nil || admin.OAuth2 == nil || !admin.Cookies {
+		t.Fatalf("authFor(api/admin) = %+v, %v, want the admin oauth2 profile", admin, err)
+	}
+	again, _ := authByName(cfg, "admin")
+	if again.OAuth2 != admin.OAuth2 {
+		t.Error("authByName(admin) built a new token source, want the cached one")
+	}
+	if auth, err := authFor(cfg, "http://localhost:3000/"); auth != nil || err != nil {
+		t.Errorf("authFor(/) = %+v, %v, want no auth", auth, err)
+	}
+	if _, err := authFor(cfg, "http://localhost:3000/legacy"); err == nil || !strings.Contains(err.Error(), "LP_TEST_UNSET is not set") {
+		t.Errorf("authFor(legacy) error = %v, want the missing environment variable", err)
+	}
+}
+
+func TestEndpointConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Endpoints = []config.EndpointConfig{{URL: "localhost:4000/users", Name: "Users"}}
+
+	if ep, ok := endpointConfig(cfg, "http://localhost:4000/users"); !ok || ep.Name != "Users" {
+		t.Errorf("endpointConfig(normalized) = %+v, %v, want the configured endpoint", ep, ok)
+	}
+	if _, ok := endpointConfig(cfg, "http://localhost:4000/"); ok {
+		t.Error("endpointConfig(unknown) found an endpoint")
+	}
+}
+
+func TestRequestFor(t *testing.T) {
+	feed := filepath.Join(t.TempDir(), "users.csv")
+	os.WriteFile(feed, []byte("id,name\n1,alice\n2,bob\n"), 0o644)
+	cfg := config.DefaultConfig()
+	cfg.Feeders = []config.FeederConfig{{Name: "users", File: feed, Mode: "unique"}}
+	cfg.Endpoints = []config.EndpointConfig{
+		{URL: "localhost:3000/users/${id}", Method: "PUT", Headers: map[string]string{"X-Request-Id": "${uuid}"}, Body: `{"name":"${name}"}`, Feed: "users"},
+		{URL: "localhost:3000/health"},
+		{URL: "localhost:3000/orphan", Feed: "missing"},
+	}
+
+	ep, err := monitor.NewEndpoint("localhost:3000/users/${id}")
+	if err != nil {
+		t.Fatalf("NewEndpoint() error = %v", err)
+	}
+	tmpl, err := requestFor(cfg, ep.URL)
+	if err != nil {
+		t.Fatalf("requestFor() error = %v", err)
+	}
+	if tmpl == nil || tmpl.Method != "PUT" || tmpl.URL != "http://localhost:3000/users/${id}" || tmpl.Feeder == nil {
+		t.Fatalf("requestFor() = %+v, want a PUT template fed by users", tmpl)
+	}
+	if tmpl.Feeder.Len() != 2 || tmpl.Feeder.Mode() != monitor.FeedUnique {
+		t.Errorf("feeder has %d rows in %s mode, want 2 unique rows", tmpl.Feeder.Len(), tmpl.Feeder.Mode())
+	}
+
+	if tmpl, err := requestFor(cfg, "http://localhost:3000/health"); tmpl != nil || err != nil {
+		t.Errorf("requestFor(health) = %+v, %v, want no template for a plain GET", tmpl, err)
+	}
+	if _, err := requestFor(cfg, "http://localhost:3000/orphan"); err == nil || !strings.Contains(err.Error(), `unknown feeder "missing"`) {
+		t.Errorf("requestFor(orphan) error = %v, want unknown feeder", err)
+	}
+}
+
+func TestBuildScenario(t *testing.T) {
+	sc, err := buildScenario(config.ScenarioConfig{
+		Name: "checkout",
+		Steps: []config.StepConfig{
+			{Name: "login", Method: "POST", URL: "${base}/login", Extract: map[string]string{"token": "json:data.token"}},
+			{Name: "cart", URL: "${base}/cart", Headers: map[string]string{"Authorization": "Bearer ${token}"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("buildScenario() error = %v", err)
+	}
+	if len(sc.Steps[0].Extract) != 1 || sc.Steps[0].Extract[0].Var != "token" || sc.Steps[1].Headers["Authorization"] != "Bearer ${token}" {
+		t.Errorf("buildScenario() = %+v", sc)
+	}
+}
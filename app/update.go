@@ -1,8 +1,16 @@
 package app
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Brattlof/localpulse/app/components"
+	"github.com/Brattlof/localpulse/config"
 	"github.com/Brattlof/localpulse/monitor"
 	"github.com/Brattlof/localpulse/ui"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,6 +38,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case MetricsUpdateMsg:
 		return m.handleMetricsUpdate(msg)
+
+	case ServerReadyMsg:
+		return m.handleServerReady(msg)
+
+	case ProcessAttachMsg:
+		return m.handleProcessAttach(msg)
+
+	case ProcessSampleMsg:
+		return m.handleProcessSample(msg)
+
+	case HealthCheckMsg:
+		return m.handleHealthCheck(msg)
+
+	case ConfigPollMsg:
+		if m.configWatcher.Changed() {
+			return m, tea.Batch(DoConfigReload(m.config), DoConfigPoll())
+		}
+		return m, DoConfigPoll()
+
+	case ConfigReloadMsg:
+		return m.handleConfigReload(msg)
+
+	case CertsInspectedMsg:
+		return m.handleCertsInspected(msg)
+
+	case StreamsProbedMsg:
+		return m.handleStreamsProbed(msg)
+
+	case AuthPreparedMsg:
+		for _, e := range msg.Errors {
+			m.logPanel.AddEntry("Auth failed for "+e, true, false)
+		}
+		if msg.Tokens > 0 {
+			m.logPanel.AddEntry("Fetched "+itoa(msg.Tokens)+" OAuth2 token(s) for the load test", false, true)
+		}
+		return m, nil
 	}
 
 	return m, tea.Batch(cmds...)
@@ -40,6 +84,16 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleInputFormKeys(msg)
 	}
 
+	if log := m.focusedLog(); log != nil {
+		if log.IsSearching() {
+			_, cmd := log.Update(msg)
+			return m, cmd
+		}
+		if handled, cmd := m.handleLogKeys(log, msg); handled {
+			return m, cmd
+		}
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
 		m.quitting = true
@@ -50,11 +104,11 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, DoScan(m.scanner)
 
 	case "tab":
-		m.focus = (m.focus + 1) % 3
+		m.focus = (m.focus + 1) % m.focusCount()
 		return m, nil
 
 	case "shift+tab":
-		m.focus = (m.focus - 1 + 3) % 3
+		m.focus = (m.focus - 1 + m.focusCount()) % m.focusCount()
 		return m, nil
 
 	case "up", "k":
@@ -88,6 +142,26 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case ">", "<":
+		if m.focus == FocusEndpoints {
+			step := 5.0
+			if msg.String() == "<" {
+				step = -5
+			}
+			m.adjustLoadRate(step, 0)
+		}
+		return m, nil
+
+	case "]", "[":
+		if m.focus == FocusEndpoints {
+			step := 5.0
+			if msg.String() == "[" {
+				step = -5
+			}
+			m.adjustLoadRate(0, step)
+		}
+		return m, nil
+
 	case "enter":
 		if m.focus == FocusEndpoints && len(m.endpoints) > 0 {
 			return m.toggleLoadTesting()
@@ -114,11 +188,82 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "x":
 		m.stopLoadTesting()
 		return m, nil
+
+	case "R":
+		if m.server != nil {
+			if err := m.server.Restart(); err != nil {
+				m.logPanel.AddEntry("Could not restart server: "+err.Error(), true, false)
+			}
+		}
+		return m, nil
+
+	case "m":
+		m.systemPanel.Toggle()
+		m.updateLayout()
+		return m, nil
+
+	case "P":
+		if m.procMonitor != nil {
+			m.logPanel.AddEntry("Detached from process "+m.procMonitor.Name(), false, false)
+			m.procMonitor = nil
+			m.chartPanel.DetachProcess()
+			return m, nil
+		}
+		if m.selectedIdx >= 0 && m.selectedIdx < len(m.endpoints) {
+			u, err := url.Parse(m.endpoints[m.selectedIdx].URL)
+			if err == nil && u.Port() != "" {
+				return m, DoAttachProcess(":" + u.Port())
+			}
+		}
+		return m, nil
 	}
 
 	return m, nil
 }
 
+func (m *Model) handleLogKeys(log *components.LogPanel, msg tea.KeyMsg) (bool, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		log.ScrollUp(1)
+	case "down", "j":
+		log.ScrollDown(1)
+	case "pgup":
+		log.ScrollUp(log.PageSize())
+	case "pgdown":
+		log.ScrollDown(log.PageSize())
+	case "home", "g":
+		log.ScrollTop()
+	case "end", "G":
+		log.ScrollBottom()
+	case "p", " ":
+		log.TogglePause()
+	case "e":
+		log.ToggleErrorsOnly()
+	case "c":
+		log.CycleStatusClass()
+	case "f":
+		log.CycleEndpoint()
+	case "esc":
+		log.ClearFilters()
+	case "/":
+		return true, log.StartSearch()
+	case "w":
+		prefix := "localpulse-"
+		if log == m.serverLog {
+			prefix = "localpulse-server-"
+		}
+		path := prefix + time.Now().Format("20060102-150405") + ".log"
+		if err := log.SaveTo(path); err != nil {
+			log.AddEntry("Could not save log: "+err.Error(), true, false)
+		} else {
+			log.AddEntry("Log saved to "+path, false, true)
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
 func (m Model) handleInputFormKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.inputForm, cmd = m.inputForm.Update(msg)
@@ -154,11 +299,38 @@ func (m Model) handleTick(msg TickMsg) (tea.Model, tea.Cmd) {
 	sysMetrics := m.sysMonitor.GetMetrics()
 	m.summaryPanel.UpdateCPU(sysMetrics.CPUPercent)
 	m.summaryPanel.UpdateRAM(sysMetrics.RAMUsed, sysMetrics.RAMTotal)
+	m.systemPanel.Update(sysMetrics)
+
+	for _, sample := range m.sampler.Drain() {
+		m.logPanel.AddRequest(sample.Result, sample.Name)
+	}
+
+	if m.server != nil {
+		m.drainServer()
+	}
 
 	if m.state == StateLoadTesting {
 		for url, metrics := range m.metricsMap {
 			cmds = append(cmds, DoMetricsUpdate(url, metrics))
 		}
+		m.endpointList.SetLoadRates(m.loadGenerator.Rates())
+		for url, obs := range m.loadGenerator.Observations() {
+			if ep := m.findEndpoint(url); ep != nil {
+				ep.Apply(obs)
+			}
+		}
+		steps := make(map[string][]monitor.StepStats)
+		ws := make(map[string]monitor.Stats)
+		for _, ep := range m.endpoints {
+			if ep.Scenario != nil {
+				steps[ep.URL] = m.loadGenerator.StepStats(ep.URL)
+			}
+			if metrics, ok := m.metricsMap[ep.URL]; ok && ep.IsWebSocket() {
+				ws[ep.URL] = metrics.GetStats()
+			}
+		}
+		m.endpointList.SetStepStats(steps)
+		m.endpointList.SetWebSocketStats(ws)
 	}
 
 	cmds = append(cmds, DoTick())
@@ -168,9 +340,14 @@ func (m Model) handleTick(msg TickMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleScanComplete(msg ScanCompleteMsg) (tea.Model, tea.Cmd) {
 	m.state = StateIdle
 
+	certs := make(map[string]*monitor.CertInfo)
 	for _, ep := range msg.Endpoints {
 		m.addEndpoint(ep)
+		if ep.Cert != nil {
+			certs[ep.URL] = ep.Cert
+		}
 	}
+	m.applyCerts(certs)
 
 	m.logPanel.AddEntry(
 		"Scan complete: found "+itoa(len(msg.Endpoints))+" endpoints",
@@ -194,6 +371,270 @@ func (m Model) handleMetricsUpdate(msg MetricsUpdateMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) handleHealthCheck(msg HealthCheckMsg) (tea.Model, tea.Cmd) {
+	for _, r := range msg.Results {
+		ep := m.findEndpoint(r.URL)
+		if ep == nil || r.Requests == 0 {
+			continue
+		}
+		ep.Apply(r.Last)
+
+		var stats monitor.Stats
+		if metrics := m.metricsMap[ep.URL]; metrics != nil {
+			stats = metrics.GetStats()
+		}
+
+		old, changed := ep.Timeline.Record(r.Status)
+		if changed && old != monitor.StatusUnknown && m.webhooks != nil {
+			m.webhooks.Send(monitor.StatusChangeEvent(ep, old, stats))
+		}
+		m.checkFlapping(ep)
+
+		for _, event := range m.alerter.Observe(ep, stats) {
+			m.logAlert(event)
+			if m.webhooks != nil {
+				m.webhooks.Send(monitor.AlertWebhookEvent(event, stats))
+			}
+		}
+	}
+	for _, failure := range m.alerter.DrainFailures() {
+		m.logPanel.AddEntry("Alert action failed: "+failure, true, false)
+	}
+	if m.webhooks != nil {
+		for _, failure := range m.webhooks.DrainFailures() {
+			m.logPanel.AddEntry("Webhook failed: "+failure, true, false)
+		}
+	}
+
+	m.healthy, m.slow, m.down = m.endpointList.StatusCounts()
+	return m, m.healthCheck()
+}
+
+func (m *Model) checkFlapping(ep *monitor.Endpoint) {
+	window := time.Duration(m.config.FlapWindow) * time.Second
+	flapping := ep.Timeline.Flapping(m.config.FlapThreshold, window)
+	if flapping == m.flapping[ep.URL] {
+		return
+	}
+	m.flapping[ep.URL] = flapping
+
+	if flapping {
+		m.logPanel.AddEntry(ep.Name+" is flapping: "+itoa(ep.Timeline.Transitions(window))+
+			" status changes in "+window.String(), true, false)
+	} else {
+		m.logPanel.AddEntry(ep.Name+" stopped flapping", false, false)
+	}
+}
+
+func (m *Model) logAlert(event monitor.AlertEvent) {
+	text := "Alert [" + event.Rule + "] " + event.Message
+	if event.Suppressed > 0 {
+		text += " (" + itoa(event.Suppressed) + " repeats suppressed)"
+	}
+	m.logPanel.AddEntry(text, !event.Resolved, event.Resolved)
+}
+
+func (m Model) findEndpoint(url string) *monitor.Endpoint {
+	for _, ep := range m.endpoints {
+		if ep.URL == url {
+			return ep
+		}
+	}
+	return nil
+}
+
+func (m Model) handleServerReady(msg ServerReadyMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.logPanel.AddEntry("Server did not become ready: "+msg.Err.Error(), true, false)
+		return m, nil
+	}
+
+	ep, err := monitor.NewEndpoint(m.serverURL)
+	if err != nil {
+		m.logPanel.AddEntry("Invalid server endpoint "+m.serverURL+": "+err.Error(), true, false)
+		return m, nil
+	}
+	m.addEndpoint(ep)
+	m.logPanel.AddEntry(
+		"Server ready on port "+itoa(m.server.Port())+" after "+msg.Elapsed.Round(100*time.Millisecond).String(),
+		false,
+		true,
+	)
+	return m, nil
+}
+
+func (m *Model) drainServer() {
+	for _, line := range m.server.DrainOutput() {
+		m.serverLog.AddOutput(line)
+	}
+
+	for _, ev := range m.server.DrainEvents() {
+		isError := ev.Kind == monitor.ServerCrashed
+		message := "Server " + ev.Message
+		if ev.PID > 0 {
+			message = "Server (pid " + itoa(ev.PID) + ") " + ev.Message
+		}
+		m.serverLog.AddEntry(message, isError, ev.Kind == monitor.ServerReady)
+		if ev.Kind != monitor.ServerReady {
+			m.logPanel.AddEntry(message, isError, false)
+		}
+	}
+
+	title := "Server"
+	if pid := m.server.PID(); pid > 0 {
+		title += " pid " + itoa(pid)
+	} else {
+		title += " (not running)"
+	}
+	if n := m.server.Restarts(); n > 0 {
+		title += " · " + itoa(n) + " restarts"
+	}
+	if n := m.server.Crashes(); n > 0 {
+		title += " · " + itoa(n) + " crashes"
+	}
+	m.serverLog.Title = title
+}
+
+func (m Model) handleProcessAttach(msg ProcessAttachMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.logPanel.AddEntry("Could not attach to process "+msg.Spec+": "+msg.Err.Error(), true, false)
+		return m, nil
+	}
+
+	m.procMonitor = msg.Monitor
+	m.chartPanel.AttachProcess(msg.Monitor.Name(), msg.Monitor.PID())
+	m.logPanel.AddEntry(
+		"Attached to process "+msg.Monitor.Name()+" (pid "+itoa(int(msg.Monitor.PID()))+")",
+		false,
+		true,
+	)
+	return m, DoSampleProcess(msg.Monitor)
+}
+
+func (m Model) handleProcessSample(msg ProcessSampleMsg) (tea.Model, tea.Cmd) {
+	if msg.Monitor != m.procMonitor {
+		return m, nil
+	}
+
+	if errors.Is(msg.Err, monitor.ErrProcessExited) {
+		m.logPanel.AddEntry(
+			"Process "+msg.Monitor.Name()+" (pid "+itoa(int(msg.Monitor.PID()))+") exited",
+			true,
+			false,
+		)
+		m.procMonitor = nil
+		m.chartPanel.DetachProcess()
+		return m, nil
+	}
+	if msg.Err == nil {
+		m.chartPanel.AddProcessSample(msg.Metrics)
+	}
+	return m, DoSampleProcess(msg.Monitor)
+}
+
+func (m Model) handleConfigReload(msg ConfigReloadMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		var verr *config.ValidationError
+		if errors.As(msg.Err, &verr) {
+			m.logPanel.AddEntry("Config reload failed, keeping previous settings:", true, false)
+			for _, fe := range verr.Errors {
+				m.logPanel.AddEntry("  "+fe.Error(), true, false)
+			}
+		} else {
+			m.logPanel.AddEntry("Config reload failed: "+msg.Err.Error(), true, false)
+		}
+		return m, nil
+	}
+
+	oldPorts := m.scanner.Ports()
+	oldSockets := m.scanner.SocketDirs()
+	oldRPS := m.config.LoadTestRPS
+
+	added, removed := m.config.Apply(msg.Config)
+	m.configWatcher.Reset(m.config.WatchPaths())
+
+	for _, epCfg := range removed {
+		m.removeEndpoint(epCfg.URL)
+	}
+	for _, epCfg := range added {
+		ep, err := monitor.NewEndpoint(epCfg.URL)
+		if err != nil {
+			m.logPanel.AddEntry("Skipping endpoint "+epCfg.URL+": "+err.Error(), true, false)
+			continue
+		}
+		if epCfg.Name != "" {
+			ep.Name = epCfg.Name
+		}
+		m.addEndpoint(ep)
+		if m.loadGenerator.IsRunning() {
+			m.loadGenerator.AddTester(ep, m.metricsMap[ep.URL])
+		}
+	}
+
+	var changes []string
+	if len(added) > 0 || len(removed) > 0 {
+		changes = append(changes, "+"+itoa(len(added))+"/-"+itoa(len(removed))+" endpoints")
+	}
+	if !equalInts(oldPorts, m.config.DefaultPorts) {
+		m.scanner.SetPorts(m.config.DefaultPorts)
+		changes = append(changes, "scan ports updated")
+	}
+	if sockets := m.config.SocketDirPaths(); !slices.Equal(oldSockets, sockets) {
+		m.scanner.SetSocketDirs(sockets)
+		changes = append(changes, "socket dirs updated")
+	}
+	if m.config.LoadTestRPS != oldRPS {
+		m.rps = m.config.LoadTestRPS
+		if m.loadGenerator.IsRunning() {
+			m.loadGenerator.SetRPS(m.rps)
+		}
+		changes = append(changes, "rps "+itoa(oldRPS)+"→"+itoa(m.rps))
+	}
+	m.syncScenarios()
+	m.sampler.SetRate(m.config.LogSampleRate)
+	m.scanner.SetPolicy(defaultPolicy(m.config))
+	m.scanner.SetReportUntrusted(m.config.ReportUntrusted)
+	if err := m.scanner.SetTLS(defaultTLS(m.config)); err != nil {
+		m.logPanel.AddEntry("Scanner TLS: "+err.Error(), true, false)
+	}
+	for _, ep := range m.endpoints {
+		m.loadGenerator.SetRate(ep.URL, loadRateFor(m.config, ep.URL))
+		m.applyEndpointConfig(ep)
+		m.loadGenerator.Reload(ep.URL)
+	}
+	m.alerter.SetRules(alertRules(m.config.GetAlerts()))
+	m.endpointList.SetFlapDetection(m.config.FlapThreshold, time.Duration(m.config.FlapWindow)*time.Second)
+	m.endpointList.SetCertWarning(time.Duration(m.config.CertWarnDays) * 24 * time.Hour)
+	if old := m.webhooks; old != nil {
+		go old.Close(5 * time.Second)
+	}
+	webhooks, err := newWebhookSender(m.config)
+	if err != nil {
+		m.logPanel.AddEntry("Webhooks disabled: "+err.Error(), true, false)
+	}
+	m.webhooks = webhooks
+
+	summary := "no effective changes"
+	if len(changes) > 0 {
+		summary = strings.Join(changes, ", ")
+	}
+	m.logPanel.AddEntry("Config reloaded: "+summary, false, true)
+
+	return m, nil
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *Model) addEndpoint(ep *monitor.Endpoint) {
 	for _, existing := range m.endpoints {
 		if existing.URL == ep.URL {
@@ -201,11 +642,125 @@ func (m *Model) addEndpoint(ep *monitor.Endpoint) {
 		}
 	}
 
+	m.loadGenerator.SetRate(ep.URL, loadRateFor(m.config, ep.URL))
+	m.applyEndpointConfig(ep)
 	m.endpoints = append(m.endpoints, ep)
 	m.metricsMap[ep.URL] = monitor.NewMetrics(1000)
 	m.endpointList.SetEndpoints(m.endpoints)
 
-	m.config.AddEndpoint(ep.URL, ep.Name)
+	if _, ok := endpointConfig(m.config, ep.URL); !ok && ep.Scenario == nil {
+		m.config.AddEndpoint(ep.URL, ep.Name)
+	}
+}
+
+func (m Model) handleCertsInspected(msg CertsInspectedMsg) (tea.Model, tea.Cmd) {
+	m.applyCerts(msg.Certs)
+	return m, DoInspectCerts(m.endpoints, certInterval)
+}
+
+func (m *Model) applyCerts(certs map[string]*monitor.CertInfo) {
+	warn := time.Duration(m.config.CertWarnDays) * 24 * time.Hour
+	now := time.Now()
+	for _, ep := range m.endpoints {
+		cert, ok := certs[ep.URL]
+		if !ok {
+			continue
+		}
+		ep.Cert = cert
+
+		issue := cert.Issue(now, warn)
+		if issue == m.certIssues[ep.URL] {
+			continue
+		}
+		m.certIssues[ep.URL] = issue
+		switch issue {
+		case monitor.CertExpired:
+			m.logPanel.AddEntry(ep.Name+": certificate expired on "+cert.NotAfter.Format("2006-01-02"), true, false)
+		case monitor.CertHostMismatch:
+			m.logPanel.AddEntry(ep.Name+": certificate is "+cert.HostError, true, false)
+		case monitor.CertExpiring:
+			m.logPanel.AddEntry(fmt.Sprintf("%s: certificate expires in %dd (%s)", ep.Name, cert.DaysLeft(now), cert.NotAfter.Format("2006-01-02")), false, false)
+		}
+	}
+}
+
+func (m Model) handleStreamsProbed(msg StreamsProbedMsg) (tea.Model, tea.Cmd) {
+	for _, ep := range m.endpoints {
+		limits, ok := msg.Limits[ep.URL]
+		if !ok {
+			continue
+		}
+		ep.Streams = &limits
+		if !limits.Limited {
+			m.logPanel.AddEntry(ep.Name+": HTTP/2 without a stream limit", false, false)
+			continue
+		}
+		conns := max(ep.Connections, 1)
+		capacity := int(limits.MaxConcurrentStreams) * conns
+		entry := fmt.Sprintf("%s: HTTP/2 allows %d concurrent streams per connection", ep.Name, limits.MaxConcurrentStreams)
+		if ep.Connections > 0 && capacity < m.config.MaxConcurrency {
+			m.logPanel.AddEntry(entry+fmt.Sprintf(", %d over %d connections; requests beyond that will queue", capacity, conns), true, false)
+			continue
+		}
+		m.logPanel.AddEntry(entry, false, false)
+	}
+	return m, nil
+}
+
+func (m *Model) applyEndpointConfig(ep *monitor.Endpoint) {
+	for _, err := range ApplyEndpoint(m.config, ep) {
+		m.logPanel.AddEntry(ep.Name+": "+err.Error(), true, false)
+	}
+}
+
+func (m *Model) syncScenarios() {
+	configs, err := m.config.GetScenarios()
+	if err != nil {
+		m.logPanel.AddEntry("Could not load scenarios: "+err.Error(), true, false)
+	}
+
+	wanted := make(map[string]bool, len(configs))
+	for _, sc := range configs {
+		wanted[sc.Name] = true
+		if old, ok := m.scenarios[sc.Name]; ok && reflect.DeepEqual(old, sc) {
+			continue
+		}
+		scenario, err := buildScenario(sc)
+		if err != nil {
+			m.logPanel.AddEntry("Skipping "+err.Error(), true, false)
+			continue
+		}
+		if sc.Feed != "" {
+			if scenario.Feeder, err = loadFeeder(m.config, sc.Feed); err != nil {
+				m.logPanel.AddEntry("Skipping scenario "+sc.Name+": "+err.Error(), true, false)
+				continue
+			}
+		}
+		if sc.Auth != "" {
+			if scenario.Auth, err = authByName(m.config, sc.Auth); err != nil {
+				m.logPanel.AddEntry("Skipping scenario "+sc.Name+": "+err.Error(), true, false)
+				continue
+			}
+		}
+
+		m.removeEndpoint(monitor.ScenarioURL(sc.Name))
+		ep := monitor.NewScenarioEndpoint(scenario)
+		m.addEndpoint(ep)
+		if m.loadGenerator.IsRunning() {
+			m.loadGenerator.AddTester(ep, m.metricsMap[ep.URL])
+		}
+		m.scenarios[sc.Name] = sc
+	}
+
+	if err != nil {
+		return
+	}
+	for name := range m.scenarios {
+		if !wanted[name] {
+			m.removeEndpoint(monitor.ScenarioURL(name))
+			delete(m.scenarios, name)
+		}
+	}
 }
 
 func (m *Model) removeSelectedEndpoint() {
@@ -215,7 +770,10 @@ func (m *Model) removeSelectedEndpoint() {
 
 	ep := m.endpoints[m.selectedIdx]
 	delete(m.metricsMap, ep.URL)
-	m.config.RemoveEndpoint(ep.URL)
+	if ec, ok := endpointConfig(m.config, ep.URL); ok {
+		m.config.RemoveEndpoint(ec.URL)
+	}
+	m.loadGenerator.RemoveTester(ep.URL)
 
 	m.endpointList.RemoveSelected()
 	m.endpoints = m.endpointList.Endpoints
@@ -225,6 +783,45 @@ func (m *Model) removeSelectedEndpoint() {
 	}
 }
 
+func (m *Model) removeEndpoint(url string) {
+	for i, ep := range m.endpoints {
+		if ep.URL != url {
+			continue
+		}
+		delete(m.metricsMap, url)
+		m.loadGenerator.RemoveTester(url)
+
+		m.endpoints = append(m.endpoints[:i:i], m.endpoints[i+1:]...)
+		m.endpointList.SetEndpoints(m.endpoints)
+		m.selectedIdx = m.endpointList.Selected
+		return
+	}
+}
+
+func (m *Model) adjustLoadRate(rps, weight float64) {
+	if m.selectedIdx < 0 || m.selectedIdx >= len(m.endpoints) {
+		return
+	}
+	ep := m.endpoints[m.selectedIdx]
+
+	rate := m.loadGenerator.GetRate(ep.URL)
+	rate.RPS = max(rate.RPS+rps, 0)
+	rate.Weight = max(rate.Weight+weight, 0)
+	m.loadGenerator.SetRate(ep.URL, rate)
+	if m.loadGenerator.IsRunning() {
+		m.endpointList.SetLoadRates(m.loadGenerator.Rates())
+	}
+
+	switch {
+	case rate.RPS > 0:
+		m.logPanel.AddEntry(fmt.Sprintf("%s: fixed rate %g req/s", ep.Name, rate.RPS), false, false)
+	case rate.Weight > 0:
+		m.logPanel.AddEntry(fmt.Sprintf("%s: weight %g of the %d req/s budget", ep.Name, rate.Weight, m.rps), false, false)
+	default:
+		m.logPanel.AddEntry(ep.Name+": full "+itoa(m.rps)+" req/s budget", false, false)
+	}
+}
+
 func (m Model) toggleLoadTesting() (tea.Model, tea.Cmd) {
 	if m.loadGenerator.IsRunning() {
 		m.stopLoadTesting()
@@ -248,20 +845,24 @@ func (m Model) startLoadTesting() (tea.Model, tea.Cmd) {
 	m.loadGenerator.Start(m.rps)
 	m.logPanel.AddEntry("Load testing started at "+itoa(m.rps)+" req/s", false, true)
 
-	return m, DoTick()
+	return m, tea.Batch(DoTick(), DoPrepareAuth(m.endpoints), DoProbeStreams(m.endpoints))
 }
 
 func (m *Model) stopLoadTesting() {
 	m.loadGenerator.Stop()
+	m.endpointList.SetLoadRates(nil)
+	m.endpointList.SetStepStats(nil)
+	m.endpointList.SetWebSocketStats(nil)
 	m.state = StateIdle
 	m.logPanel.AddEntry("Load testing stopped", false, false)
 }
 
 func (m *Model) updateLayout() {
 	summaryHeight := 4
-	logHeight := 8
+	logHeight := 10
 	helpHeight := 2
-	remainingHeight := m.height - summaryHeight - logHeight - helpHeight
+	m.systemPanel.SetWidth(m.width - 2)
+	remainingHeight := m.height - summaryHeight - m.systemPanel.Height() - logHeight - helpHeight
 
 	leftWidth := m.width / 2
 	rightWidth := m.width - leftWidth
@@ -269,7 +870,12 @@ func (m *Model) updateLayout() {
 	m.summaryPanel.SetWidth(m.width)
 	m.endpointList.SetSize(leftWidth-2, remainingHeight-2)
 	m.chartPanel.SetSize(rightWidth-2, remainingHeight-2)
-	m.logPanel.SetSize(m.width-2, logHeight-2)
+	if m.serverLog != nil {
+		m.logPanel.SetSize(leftWidth-2, logHeight-2)
+		m.serverLog.SetSize(rightWidth-2, logHeight-2)
+	} else {
+		m.logPanel.SetSize(m.width-2, logHeight-2)
+	}
 }
 
 func itoa(n int) string {
@@ -301,6 +907,11 @@ func (m Model) View() string {
 	b.WriteString(m.summaryPanel.View())
 	b.WriteString("\n")
 
+	if m.systemPanel.Expanded {
+		b.WriteString(m.systemPanel.View())
+		b.WriteString("\n")
+	}
+
 	leftPanel := m.endpointList.View()
 	rightPanel := m.chartPanel.View()
 
@@ -313,7 +924,11 @@ func (m Model) View() string {
 	b.WriteString(panels)
 	b.WriteString("\n")
 
-	b.WriteString(m.logPanel.View())
+	if m.serverLog != nil {
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.logPanel.View(), " ", m.serverLog.View()))
+	} else {
+		b.WriteString(m.logPanel.View())
+	}
 	b.WriteString("\n")
 
 	if m.inputForm.IsActive() {
@@ -330,10 +945,28 @@ func (m Model) View() string {
 func (m Model) renderHelpBar() string {
 	var keys []ui.HelpKey
 
-	if m.state == StateLoadTesting {
+	if log := m.focusedLog(); log != nil && log.IsSearching() {
+		keys = []ui.HelpKey{
+			{Key: "enter", Desc: "apply"},
+			{Key: "esc", Desc: "close"},
+		}
+	} else if m.focusedLog() != nil && !m.inputForm.IsActive() {
+		keys = []ui.HelpKey{
+			{Key: "/", Desc: "search"},
+			{Key: "e", Desc: "errors"},
+			{Key: "c", Desc: "status"},
+			{Key: "f", Desc: "endpoint"},
+			{Key: "p", Desc: "pause"},
+			{Key: "↑↓", Desc: "scroll"},
+			{Key: "w", Desc: "save"},
+			{Key: "esc", Desc: "clear"},
+		}
+	} else if m.state == StateLoadTesting {
 		keys = []ui.HelpKey{
 			{Key: "x", Desc: "stop load"},
 			{Key: "+/-", Desc: "adjust rps"},
+			{Key: "</>", Desc: "endpoint rps"},
+			{Key: "[/]", Desc: "weight"},
 			{Key: "q", Desc: "quit"},
 		}
 	} else if m.inputForm.IsActive() {
@@ -347,8 +980,13 @@ func (m Model) renderHelpBar() string {
 			{Key: "s", Desc: "start load"},
 			{Key: "a", Desc: "add"},
 			{Key: "d", Desc: "delete"},
-			{Key: "q", Desc: "quit"},
+			{Key: "P", Desc: "attach"},
+			{Key: "m", Desc: "system"},
+		}
+		if m.server != nil {
+			keys = append(keys, ui.HelpKey{Key: "R", Desc: "restart server"})
 		}
+		keys = append(keys, ui.HelpKey{Key: "q", Desc: "quit"})
 	}
 
 	rpsInfo := " [RPS: " + itoa(m.rps) + "]"
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Brattlof/localpulse/config"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func ValidateConfig(c *config.Config) []config.FieldError {
+	var errs []config.FieldError
+
+	for i, ep := range c.Endpoints {
+		path := "endpoints[" + strconv.Itoa(i) + "]"
+		if strings.TrimSpace(ep.URL) == "" {
+			continue
+		}
+		errs = append(errs, checkAccept(path+".accept_status", ep.AcceptStatus)...)
+		errs = append(errs, checkTLSVersion(path+".tls_min_version", ep.TLSMinVersion)...)
+		if protocol, err := monitor.ParseProtocol(ep.Protocol); err != nil {
+			errs = append(errs, config.FieldError{Field: path + ".protocol", Message: err.Error()})
+		} else if err := protocol.Check(ep.URL); err != nil {
+			errs = append(errs, config.FieldError{Field: path + ".protocol", Message: err.Error()})
+		}
+		errs = append(errs, checkTemplate(path+".url", ep.URL)...)
+		errs = append(errs, checkTemplate(path+".body", ep.Body)...)
+		for _, name := range slices.Sorted(maps.Keys(ep.Headers)) {
+			errs = append(errs, checkTemplate(path+".headers."+name, ep.Headers[name])...)
+		}
+		for j, msg := range ep.Messages {
+			errs = append(errs, checkTemplate(path+".messages["+strconv.Itoa(j)+"]", msg)...)
+		}
+		errs = append(errs, checkTemplate(path+".match", ep.Match)...)
+		if strings.HasPrefix(ep.URL, "unix://") {
+			if _, _, err := monitor.ParseUnixURL(ep.URL); err != nil {
+				errs = append(errs, config.FieldError{Field: path + ".url", Message: err.Error()})
+			}
+		}
+	}
+
+	errs = append(errs, checkAccept("accept_status", c.AcceptStatus)...)
+	errs = append(errs, checkTLSVersion("tls_min_version", c.TLSMinVersion)...)
+
+	for i, sc := range c.Scenarios {
+		errs = append(errs, checkScenario("scenarios["+strconv.Itoa(i)+"]", sc)...)
+	}
+	return errs
+}
+
+func checkScenario(path string, s config.ScenarioConfig) []config.FieldError {
+	var errs []config.FieldError
+
+	defined := make(map[string]bool, len(s.Vars))
+	for name := range s.Vars {
+		defined[name] = true
+	}
+	undefined := func(field, text string) {
+		errs = append(errs, checkTemplate(field, text)...)
+		if s.Feed != "" {
+			return
+		}
+		for _, ref := range monitor.VarRefs(text) {
+			if !defined[ref] {
+				errs = append(errs, config.FieldError{Field: field, Message: "uses ${" + ref + "} before it is set"})
+			}
+		}
+	}
+
+	for i, step := range s.Steps {
+		stepPath := path + ".steps[" + strconv.Itoa(i) + "]"
+		undefined(stepPath+".url", step.URL)
+		undefined(stepPath+".body", step.Body)
+		for _, name := range slices.Sorted(maps.Keys(step.Headers)) {
+			undefined(stepPath+".headers."+name, step.Headers[name])
+		}
+		for _, name := range slices.Sorted(maps.Keys(step.Extract)) {
+			if _, err := monitor.ParseExtraction(name, step.Extract[name]); err != nil {
+				errs = append(errs, config.FieldError{Field: stepPath + ".extract." + name, Message: err.Error()})
+			}
+			defined[name] = true
+		}
+	}
+	return errs
+}
+
+func checkAccept(field, accept string) []config.FieldError {
+	if accept == "" {
+		return nil
+	}
+	if _, err := monitor.ParseStatusRanges(accept); err != nil {
+		return []config.FieldError{{Field: field, Message: err.Error()}}
+	}
+	return nil
+}
+
+func checkTLSVersion(field, version string) []config.FieldError {
+	if _, err := monitor.ParseTLSVersion(version); err != nil {
+		return []config.FieldError{{Field: field, Message: err.Error()}}
+	}
+	return nil
+}
+
+func checkTemplate(field, text string) []config.FieldError {
+	if err := monitor.CheckTemplate(text); err != nil {
+		return []config.FieldError{{Field: field, Message: err.Error()}}
+	}
+	return nil
+}
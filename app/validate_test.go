@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Brattlof/localpulse/config"
+)
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		wantLine  int
+		wantField string
+	}{
+		{
+			name: "yaml endpoint accept status",
+			file: "localpulse.yaml",
+			content: `endpoints:
+  - url: http://localhost:3000/reports
+    timeout_seconds: 10
+    accept_status: 2xx,600
+`,
+			wantLine:  4,
+			wantField: "endpoints[0].accept_status",
+		},
+		{
+			name: "yaml scenario variable used before extraction",
+			file: "localpulse.yaml",
+			content: `scenarios:
+  - name: orders
+    steps:
+      - url: http://localhost:3000/orders/${id}
+      - method: POST
+        url: http://localhost:3000/orders
+        extract:
+          id: json:data.id
+`,
+			wantLine:  4,
+			wantField: "scenarios[0].steps[0].url",
+		},
+		{
+			name:      "yaml relative unix socket",
+			file:      "localpulse.yaml",
+			content:   "endpoints:\n  - url: https://localhost:8443\n  - url: unix://run/app.sock:/health\n",
+			wantLine:  3,
+			wantField: "endpoints[1].url",
+		},
+		{
+			name:      "yaml h2 over cleartext",
+			file:      "localpulse.yaml",
+			content:   "endpoints:\n  - url: http://localhost:3000\n    protocol: h2\n",
+			wantLine:  3,
+			wantField: "endpoints[0].protocol",
+		},
+		{
+			name:      "json unknown generator",
+			file:      "localpulse.json",
+			content:   "{\n  \"endpoints\": [\n    {\"url\": \"http://localhost:3000/users\", \"method\": \"POST\", \"body\": \"${randomint(1,5)}\"}\n  ]\n}",
+			wantLine:  3,
+			wantField: "endpoints[0].body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			var verr *config.ValidationError
+			if err := config.ValidateFile(path, config.WithValidator(ValidateConfig)); !errors.As(err, &verr) {
+				t.Fatalf("error = %v (%T), want *config.ValidationError", err, err)
+			}
+			if len(verr.Errors) != 1 {
+				t.Fatalf("got %d errors (%v), want 1", len(verr.Errors), verr.Errors)
+			}
+			fe := verr.Errors[0]
+			if fe.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d", fe.Line, tt.wantLine)
+			}
+			if fe.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", fe.Field, tt.wantField)
+			}
+			if err := config.ValidateFile(path); err != nil {
+				t.Errorf("ValidateFile() without the validator = %v, want nil", err)
+			}
+		})
+	}
+}
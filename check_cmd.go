@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Brattlof/localpulse/app"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func runCheck(args []string, opts *cliOptions) int {
+	var count int
+	var format, output, expectStatus, expectBody string
+	var maxLatency time.Duration
+
+	args, err := parseFlags("localpulse check", args, opts, func(fs *flag.FlagSet) {
+		fs.IntVar(&count, "count", 1, "")
+		fs.IntVar(&count, "n", 1, "")
+		fs.StringVar(&format, "format", "table", "")
+		fs.StringVar(&output, "output", "", "")
+		fs.StringVar(&output, "o", "", "")
+		fs.StringVar(&expectStatus, "expect-status", "", "")
+		fs.StringVar(&expectBody, "expect-body", "", "")
+		fs.DurationVar(&maxLatency, "max-latency", 0, "")
+	})
+	if err != nil {
+		return usageError(err)
+	}
+	if count < 1 {
+		return usageError(errors.New("--count must be at least 1"))
+	}
+	if format != "table" && format != "json" && format != "junit" {
+		return usageError(fmt.Errorf("unknown format %q: expected table, json or junit", format))
+	}
+
+	statuses, err := parseStatusList(expectStatus)
+	if err != nil {
+		return usageError(err)
+	}
+
+	cfg, ok := loadConfig(opts)
+	if !ok {
+		return 1
+	}
+
+	var endpoints []*monitor.Endpoint
+	for _, arg := range args {
+		ep, err := monitor.NewEndpoint(arg)
+		if err != nil {
+			return usageError(fmt.Errorf("invalid URL %q: %w", arg, err))
+		}
+		ep.Name = ""
+		endpoints = append(endpoints, ep)
+	}
+	if len(endpoints) == 0 {
+		for _, epCfg := range cfg.GetEndpoints() {
+			ep, err := monitor.NewEndpoint(epCfg.URL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid endpoint URL %q in config: %v\n", epCfg.URL, err)
+				return 2
+			}
+			ep.Name = epCfg.Name
+			endpoints = append(endpoints, ep)
+		}
+	}
+	if len(endpoints) == 0 {
+		return usageError(errors.New("nothing to check: pass URLs or configure endpoints"))
+	}
+	for _, ep := range endpoints {
+		if errs := app.ApplyEndpoint(cfg, ep); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", ep.URL, err)
+			}
+			return 1
+		}
+	}
+
+	results := monitor.CheckEndpoints(context.Background(), endpoints,
+		monitor.WithCheckCount(count),
+		monitor.WithCheckTimeout(time.Duration(cfg.Timeout)*time.Second),
+		monitor.WithAssertions(monitor.CheckAssertions{
+			Statuses:     statuses,
+			MaxLatency:   maxLatency,
+			BodyContains: expectBody,
+		}),
+	)
+
+	var out io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch format {
+	case "json":
+		err = writeCheckJSON(out, results)
+	case "junit":
+		err = writeCheckJUnit(out, results)
+	default:
+		err = writeCheckTable(out, results)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if checksFailed(results) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func parseStatusList(s string) ([]int, error) {
+	var statuses []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status %q in --expect-status", part)
+		}
+		statuses = append(statuses, code)
+	}
+	return statuses, nil
+}
+
+func checksFailed(results []monitor.CheckResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Status == monitor.StatusDown {
+			failed++
+		}
+	}
+	return failed
+}
+
+func formatStatusCodes(codes map[int]int) string {
+	if len(codes) == 0 {
+		return "-"
+	}
+	keys := make([]int, 0, len(codes))
+	for code := range codes {
+		keys = append(keys, code)
+	}
+	sort.Ints(keys)
+
+	parts := make([]string, len(keys))
+	for i, code := range keys {
+		parts[i] = fmt.Sprintf("%d×%d", code, codes[code])
+	}
+	return strings.Join(parts, " ")
+}
+
+func checkName(r monitor.CheckResult) string {
+	if r.Name != "" {
+		return r.Name + " (" + r.URL + ")"
+	}
+	return r.URL
+}
+
+func writeCheckTable(w io.Writer, results []monitor.CheckResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tSTATUS\tOK\tAVG\tMAX\tCODES")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\t%s\t%s\n",
+			checkName(r), r.Status, r.Requests-r.Failed, r.Requests,
+			r.AvgLatency.Round(time.Millisecond), r.MaxLatency.Round(time.Millisecond),
+			formatStatusCodes(r.StatusCodes))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if len(r.Failures) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n✗ %s\n", checkName(r))
+		for _, f := range uniqueFailures(r.Failures) {
+			fmt.Fprintf(w, "    %s\n", f)
+		}
+	}
+
+	if failed := checksFailed(results); failed > 0 {
+		_, err := fmt.Fprintf(w, "\n%d of %d endpoints down\n", failed, len(results))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nAll %d endpoints up\n", len(results))
+	return err
+}
+
+func uniqueFailures(failures []string) []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, f := range failures {
+		if counts[f] == 0 {
+			order = append(order, f)
+		}
+		counts[f]++
+	}
+	for i, f := range order {
+		if counts[f] > 1 {
+			order[i] = fmt.Sprintf("%s (×%d)", f, counts[f])
+		}
+	}
+	return order
+}
+
+type checkReport struct {
+	OK        bool            `json:"ok"`
+	Checked   int             `json:"checked"`
+	Down      int             `json:"down"`
+	Endpoints []checkEndpoint `json:"endpoints"`
+}
+
+type checkEndpoint struct {
+	Name         string         `json:"name,omitempty"`
+	URL          string         `json:"url"`
+	Status       string         `json:"status"`
+	Requests     int            `json:"requests"`
+	Failed       int            `json:"failed"`
+	MinLatencyMs float64        `json:"min_latency_ms"`
+	AvgLatencyMs float64        `json:"avg_latency_ms"`
+	MaxLatencyMs float64        `json:"max_latency_ms"`
+	StatusCodes  map[string]int `json:"status_codes"`
+	Failures     []string       `json:"failures,omitempty"`
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func writeCheckJSON(w io.Writer, results []monitor.CheckResult) error {
+	report := checkReport{
+		Checked:   len(results),
+		Down:      checksFailed(results),
+		Endpoints: make([]checkEndpoint, 0, len(results)),
+	}
+	report.OK = report.Down == 0
+
+	for _, r := range results {
+		codes := make(map[string]int, len(r.StatusCodes))
+		for code, n := range r.StatusCodes {
+			codes[strconv.Itoa(code)] = n
+		}
+		report.Endpoints = append(report.Endpoints, checkEndpoint{
+			Name:         r.Name,
+			URL:          r.URL,
+			Status:       string(r.Status),
+			Requests:     r.Requests,
+			Failed:       r.Failed,
+			MinLatencyMs: millis(r.MinLatency),
+			AvgLatencyMs: millis(r.AvgLatency),
+			MaxLatencyMs: millis(r.MaxLatency),
+			StatusCodes:  codes,
+			Failures:     r.Failures,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func junitSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+func writeCheckJUnit(w io.Writer, results []monitor.CheckResult) error {
+	suite := junitSuite{Name: "localpulse check", Tests: len(results)}
+
+	var total time.Duration
+	for _, r := range results {
+		if r.Duration > total {
+			total = r.Duration
+		}
+
+		tc := junitCase{
+			Name:      checkName(r),
+			ClassName: "localpulse.check",
+			Time:      junitSeconds(r.Duration),
+			SystemOut: fmt.Sprintf("status=%s ok=%d/%d avg=%s max=%s codes=%s",
+				r.Status, r.Requests-r.Failed, r.Requests,
+				r.AvgLatency.Round(time.Millisecond), r.MaxLatency.Round(time.Millisecond),
+				formatStatusCodes(r.StatusCodes)),
+		}
+		if r.Status == monitor.StatusDown {
+			suite.Failures++
+			message := "endpoint down"
+			if len(r.Failures) > 0 {
+				message = r.Failures[0]
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    string(r.Status),
+				Text:    strings.Join(uniqueFailures(r.Failures), "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
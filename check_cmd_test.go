@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func checkTestResults() []monitor.CheckResult {
+	return []monitor.CheckResult{
+		{
+			Name:        "API",
+			URL:         "http://localhost:3000/health",
+			Status:      monitor.StatusHealthy,
+			Requests:    3,
+			MinLatency:  8 * time.Millisecond,
+			AvgLatency:  12 * time.Millisecond,
+			MaxLatency:  20 * time.Millisecond,
+			StatusCodes: map[int]int{200: 3},
+			Duration:    40 * time.Millisecond,
+		},
+		{
+			URL:         "http://localhost:8080/admin",
+			Status:      monitor.StatusDown,
+			Requests:    3,
+			Failed:      2,
+			MinLatency:  5 * time.Millisecond,
+			AvgLatency:  7 * time.Millisecond,
+			MaxLatency:  9 * time.Millisecond,
+			StatusCodes: map[int]int{200: 1, 503: 2},
+			LastCode:    503,
+			Failures:    []string{"status 503, expected 200-399", "status 503, expected 200-399"},
+			Duration:    25 * time.Millisecond,
+		},
+	}
+}
+
+func TestCheckWriters_Golden(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(io.Writer, []monitor.CheckResult) error
+		want  string
+	}{
+		{"table", writeCheckTable, `ENDPOINT                            STATUS   OK   AVG   MAX   CODES
+API (http://localhost:3000/health)  healthy  3/3  12ms  20ms  200×3
+http://localhost:8080/admin         down     1/3  7ms   9ms   200×1 503×2
+
+✗ http://localhost:8080/admin
+    status 503, expected 200-399 (×2)
+
+1 of 2 endpoints down
+`},
+		{"json", writeCheckJSON, `{
+  "ok": false,
+  "checked": 2,
+  "down": 1,
+  "endpoints": [
+    {
+      "name": "API",
+      "url": "http://localhost:3000/health",
+      "status": "healthy",
+      "requests": 3,
+      "failed": 0,
+      "min_latency_ms": 8,
+      "avg_latency_ms": 12,
+      "max_latency_ms": 20,
+      "status_codes": {
+        "200": 3
+      }
+    },
+    {
+      "url": "http://localhost:8080/admin",
+      "status": "down",
+      "requests": 3,
+      "failed": 2,
+      "min_latency_ms": 5,
+      "avg_latency_ms": 7,
+      "max_latency_ms": 9,
+      "status_codes": {
+        "200": 1,
+        "503": 2
+      },
+      "failures": [
+        "status 503, expected 200-399",
+        "status 503, expected 200-399"
+      ]
+    }
+  ]
+}
+`},
+		{"junit", writeCheckJUnit, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="localpulse check" tests="2" failures="1" time="0.040">
+    <testcase name="API (http://localhost:3000/health)" classname="localpulse.check" time="0.040">
+      <system-out>status=healthy ok=3/3 avg=12ms max=20ms codes=200×3</system-out>
+    </testcase>
+    <testcase name="http://localhost:8080/admin" classname="localpulse.check" time="0.025">
+      <failure message="status 503, expected 200-399" type="down">status 503, expected 200-399 (×2)</failure>
+      <system-out>status=down ok=1/3 avg=7ms max=9ms codes=200×1 503×2</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, checkTestResults()); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
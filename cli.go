@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Brattlof/localpulse/app"
+	"github.com/Brattlof/localpulse/config"
+	"github.com/mattn/go-isatty"
+)
+
+type cliOptions struct {
+	loadOpts      []config.LoadOption
+	version       bool
+	help          bool
+	plain         bool
+	ndjson        bool
+	duration      time.Duration
+	statsInterval time.Duration
+}
+
+type configFlag struct {
+	name    string
+	short   string
+	key     string
+	boolean bool
+}
+
+var configFlags = []configFlag{
+	{name: "host", key: "host"},
+	{name: "ports", short: "p", key: "default_ports"},
+	{name: "socket-dirs", key: "socket_dirs"},
+	{name: "endpoint", short: "e", key: "endpoints"},
+	{name: "interval", short: "i", key: "check_interval_seconds"},
+	{name: "rps", short: "r", key: "load_test_rps"},
+	{name: "timeout", short: "t", key: "timeout_seconds"},
+	{name: "slow-threshold", key: "slow_threshold_ms"},
+	{name: "down-threshold", key: "down_threshold_ms"},
+	{name: "accept-status", key: "accept_status"},
+	{name: "concurrency", key: "max_concurrency"},
+	{name: "window", key: "window_seconds"},
+	{name: "flap-threshold", key: "flap_threshold"},
+	{name: "flap-window", key: "flap_window_seconds"},
+	{name: "log-sample-rate", key: "log_sample_rate"},
+	{name: "theme", key: "theme"},
+	{name: "process", key: "process"},
+	{name: "scenarios", key: "scenario_file"},
+	{name: "tls-ca-file", key: "tls_ca_file"},
+	{name: "tls-cert-file", key: "tls_cert_file"},
+	{name: "tls-key-file", key: "tls_key_file"},
+	{name: "tls-min-version", key: "tls_min_version"},
+	{name: "tls-insecure", key: "tls_insecure", boolean: true},
+	{name: "report-untrusted-tls", key: "report_untrusted_tls", boolean: true},
+	{name: "cert-warn-days", key: "cert_warn_days"},
+}
+
+func newCLIOptions() *cliOptions {
+	return &cliOptions{
+		loadOpts:      []config.LoadOption{config.WithEnv(os.Environ()), config.WithValidator(app.ValidateConfig)},
+		statsInterval: 10 * time.Second,
+	}
+}
+
+func parseFlags(name string, args []string, opts *cliOptions, extra ...func(*flag.FlagSet)) ([]string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	fs.BoolVar(&opts.version, "version", opts.version, "")
+	fs.BoolVar(&opts.version, "v", opts.version, "")
+	fs.BoolVar(&opts.help, "help", opts.help, "")
+	fs.BoolVar(&opts.help, "h", opts.help, "")
+	fs.BoolVar(&opts.plain, "plain", opts.plain, "")
+	fs.BoolVar(&opts.ndjson, "ndjson", opts.ndjson, "")
+	fs.DurationVar(&opts.duration, "duration", opts.duration, "")
+	fs.DurationVar(&opts.statsInterval, "stats-interval", opts.statsInterval, "")
+
+	configFile := func(path string) error {
+		opts.loadOpts = append(opts.loadOpts, config.WithConfigFile(path))
+		return nil
+	}
+	fs.Func("config", "", configFile)
+	fs.Func("c", "", configFile)
+
+	for _, f := range configFlags {
+		set := func(value string) error {
+			opts.loadOpts = append(opts.loadOpts, config.WithOverride(f.key, value, "flag --"+f.name))
+			return nil
+		}
+		if f.boolean {
+			fs.BoolFunc(f.name, "", set)
+			continue
+		}
+		fs.Func(f.name, "", set)
+		if f.short != "" {
+			fs.Func(f.short, "", set)
+		}
+	}
+
+	for _, setup := range extra {
+		setup(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+func (o *cliOptions) plainMode() bool {
+	if o.plain || o.ndjson {
+		return true
+	}
+	fd := os.Stdout.Fd()
+	return !isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd)
+}
+
+func usageError(err error) int {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	fmt.Fprintln(os.Stderr, "Run 'localpulse --help' for usage.")
+	return 2
+}
@@ -1,31 +1,175 @@
 package config
 
 import (
-	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 )
 
 type EndpointConfig struct {
-	URL  string `json:"url"`
-	Name string `json:"name,omitempty"`
+	URL             string            `json:"url" yaml:"url" toml:"url"`
+	Name            string            `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	SlowThresholdMs int               `json:"slow_threshold_ms,omitempty" yaml:"slow_threshold_ms,omitempty" toml:"slow_threshold_ms,omitempty"`
+	DownThresholdMs int               `json:"down_threshold_ms,omitempty" yaml:"down_threshold_ms,omitempty" toml:"down_threshold_ms,omitempty"`
+	Timeout         int               `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty" toml:"timeout_seconds,omitempty"`
+	AcceptStatus    string            `json:"accept_status,omitempty" yaml:"accept_status,omitempty" toml:"accept_status,omitempty"`
+	RPS             float64           `json:"rps,omitempty" yaml:"rps,omitempty" toml:"rps,omitempty"`
+	Weight          float64           `json:"weight,omitempty" yaml:"weight,omitempty" toml:"weight,omitempty"`
+	Method          string            `json:"method,omitempty" yaml:"method,omitempty" toml:"method,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty,inline"`
+	Body            string            `json:"body,omitempty" yaml:"body,omitempty" toml:"body,omitempty"`
+	Feed            string            `json:"feed,omitempty" yaml:"feed,omitempty" toml:"feed,omitempty"`
+	Auth            string            `json:"auth,omitempty" yaml:"auth,omitempty" toml:"auth,omitempty"`
+	TLSCAFile       string            `json:"tls_ca_file,omitempty" yaml:"tls_ca_file,omitempty" toml:"tls_ca_file,omitempty"`
+	TLSCertFile     string            `json:"tls_cert_file,omitempty" yaml:"tls_cert_file,omitempty" toml:"tls_cert_file,omitempty"`
+	TLSKeyFile      string            `json:"tls_key_file,omitempty" yaml:"tls_key_file,omitempty" toml:"tls_key_file,omitempty"`
+	TLSServerName   string            `json:"tls_server_name,omitempty" yaml:"tls_server_name,omitempty" toml:"tls_server_name,omitempty"`
+	TLSMinVersion   string            `json:"tls_min_version,omitempty" yaml:"tls_min_version,omitempty" toml:"tls_min_version,omitempty"`
+	TLSInsecure     bool              `json:"tls_insecure,omitempty" yaml:"tls_insecure,omitempty" toml:"tls_insecure,omitempty"`
+	Protocol        string            `json:"protocol,omitempty" yaml:"protocol,omitempty" toml:"protocol,omitempty"`
+	Connections     int               `json:"connections,omitempty" yaml:"connections,omitempty" toml:"connections,omitempty"`
+	Messages        []string          `json:"messages,omitempty" yaml:"messages,omitempty" toml:"messages,omitempty"`
+	Match           string            `json:"match,omitempty" yaml:"match,omitempty" toml:"match,omitempty"`
+}
+
+type AuthConfig struct {
+	Name            string   `json:"name" yaml:"name" toml:"name"`
+	Type            string   `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+	TokenEnv        string   `json:"token_env,omitempty" yaml:"token_env,omitempty" toml:"token_env,omitempty"`
+	Username        string   `json:"username,omitempty" yaml:"username,omitempty" toml:"username,omitempty"`
+	PasswordEnv     string   `json:"password_env,omitempty" yaml:"password_env,omitempty" toml:"password_env,omitempty"`
+	TokenURL        string   `json:"token_url,omitempty" yaml:"token_url,omitempty" toml:"token_url,omitempty"`
+	ClientID        string   `json:"client_id,omitempty" yaml:"client_id,omitempty" toml:"client_id,omitempty"`
+	ClientSecretEnv string   `json:"client_secret_env,omitempty" yaml:"client_secret_env,omitempty" toml:"client_secret_env,omitempty"`
+	Scopes          []string `json:"scopes,omitempty" yaml:"scopes,omitempty" toml:"scopes,omitempty"`
+	Cookies         bool     `json:"cookies,omitempty" yaml:"cookies,omitempty" toml:"cookies,omitempty"`
+	Endpoints       []string `json:"endpoints,omitempty" yaml:"endpoints,omitempty" toml:"endpoints,omitempty"`
+}
+
+type FeederConfig struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	File string `json:"file" yaml:"file" toml:"file"`
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty" toml:"mode,omitempty"`
+}
+
+type AlertConfig struct {
+	Name            string   `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Endpoint        string   `json:"endpoint,omitempty" yaml:"endpoint,omitempty" toml:"endpoint,omitempty"`
+	When            string   `json:"when" yaml:"when" toml:"when"`
+	For             int      `json:"for,omitempty" yaml:"for,omitempty" toml:"for,omitempty"`
+	Threshold       float64  `json:"threshold,omitempty" yaml:"threshold,omitempty" toml:"threshold,omitempty"`
+	Actions         []string `json:"actions" yaml:"actions" toml:"actions"`
+	Command         string   `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	CooldownSeconds int      `json:"cooldown_seconds,omitempty" yaml:"cooldown_seconds,omitempty" toml:"cooldown_seconds,omitempty"`
+}
+
+type WebhookConfig struct {
+	URL      string            `json:"url" yaml:"url" toml:"url"`
+	Format   string            `json:"format,omitempty" yaml:"format,omitempty" toml:"format,omitempty"`
+	Template string            `json:"template,omitempty" yaml:"template,omitempty" toml:"template,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty"`
+	Events   []string          `json:"events,omitempty" yaml:"events,omitempty" toml:"events,omitempty"`
+	Retries  int               `json:"retries,omitempty" yaml:"retries,omitempty" toml:"retries,omitempty"`
+}
+
+type StepConfig struct {
+	Name    string            `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Method  string            `json:"method,omitempty" yaml:"method,omitempty" toml:"method,omitempty"`
+	URL     string            `json:"url" yaml:"url" toml:"url"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty" toml:"body,omitempty"`
+	Extract map[string]string `json:"extract,omitempty" yaml:"extract,omitempty" toml:"extract,omitempty"`
+}
+
+type ScenarioConfig struct {
+	Name  string            `json:"name" yaml:"name" toml:"name"`
+	Vars  map[string]string `json:"vars,omitempty" yaml:"vars,omitempty" toml:"vars,omitempty"`
+	Feed  string            `json:"feed,omitempty" yaml:"feed,omitempty" toml:"feed,omitempty"`
+	Auth  string            `json:"auth,omitempty" yaml:"auth,omitempty" toml:"auth,omitempty"`
+	Steps []StepConfig      `json:"steps" yaml:"steps" toml:"steps"`
 }
 
 type Config struct {
 	mu sync.RWMutex
 
-	Endpoints      []EndpointConfig `json:"endpoints"`
-	DefaultPorts   []int            `json:"default_ports"`
-	CheckInterval  int              `json:"check_interval_seconds"`
-	LoadTestRPS    int              `json:"load_test_rps"`
-	Timeout        int              `json:"timeout_seconds"`
-	MaxConcurrency int              `json:"max_concurrency"`
-	WindowSeconds  int              `json:"window_seconds"`
+	Version         int              `json:"version" yaml:"version" toml:"version"`
+	Endpoints       []EndpointConfig `json:"endpoints" yaml:"endpoints" toml:"endpoints"`
+	DefaultPorts    []int            `json:"default_ports" yaml:"default_ports" toml:"default_ports"`
+	SocketDirs      []string         `json:"socket_dirs,omitempty" yaml:"socket_dirs,omitempty" toml:"socket_dirs,omitempty"`
+	CheckInterval   int              `json:"check_interval_seconds" yaml:"check_interval_seconds" toml:"check_interval_seconds"`
+	LoadTestRPS     int              `json:"load_test_rps" yaml:"load_test_rps" toml:"load_test_rps"`
+	Timeout         int              `json:"timeout_seconds" yaml:"timeout_seconds" toml:"timeout_seconds"`
+	MaxConcurrency  int              `json:"max_concurrency" yaml:"max_concurrency" toml:"max_concurrency"`
+	WindowSeconds   int              `json:"window_seconds" yaml:"window_seconds" toml:"window_seconds"`
+	FlapThreshold   int              `json:"flap_threshold" yaml:"flap_threshold" toml:"flap_threshold"`
+	FlapWindow      int              `json:"flap_window_seconds" yaml:"flap_window_seconds" toml:"flap_window_seconds"`
+	SlowThreshold   int              `json:"slow_threshold_ms" yaml:"slow_threshold_ms" toml:"slow_threshold_ms"`
+	DownThreshold   int              `json:"down_threshold_ms" yaml:"down_threshold_ms" toml:"down_threshold_ms"`
+	AcceptStatus    string           `json:"accept_status" yaml:"accept_status" toml:"accept_status"`
+	LogSampleRate   float64          `json:"log_sample_rate" yaml:"log_sample_rate" toml:"log_sample_rate"`
+	Host            string           `json:"host" yaml:"host" toml:"host"`
+	Theme           string           `json:"theme" yaml:"theme" toml:"theme"`
+	Process         string           `json:"process,omitempty" yaml:"process,omitempty" toml:"process,omitempty"`
+	Alerts          []AlertConfig    `json:"alerts,omitempty" yaml:"alerts,omitempty" toml:"alerts,omitempty"`
+	Webhooks        []WebhookConfig  `json:"webhooks,omitempty" yaml:"webhooks,omitempty" toml:"webhooks,omitempty"`
+	Scenarios       []ScenarioConfig `json:"scenarios,omitempty" yaml:"scenarios,omitempty" toml:"scenarios,omitempty"`
+	ScenarioFile    string           `json:"scenario_file,omitempty" yaml:"scenario_file,omitempty" toml:"scenario_file,omitempty"`
+	Feeders         []FeederConfig   `json:"feeders,omitempty" yaml:"feeders,omitempty" toml:"feeders,omitempty"`
+	AuthProfiles    []AuthConfig     `json:"auth,omitempty" yaml:"auth,omitempty" toml:"auth,omitempty"`
+	TLSCAFile       string           `json:"tls_ca_file,omitempty" yaml:"tls_ca_file,omitempty" toml:"tls_ca_file,omitempty"`
+	TLSCertFile     string           `json:"tls_cert_file,omitempty" yaml:"tls_cert_file,omitempty" toml:"tls_cert_file,omitempty"`
+	TLSKeyFile      string           `json:"tls_key_file,omitempty" yaml:"tls_key_file,omitempty" toml:"tls_key_file,omitempty"`
+	TLSMinVersion   string           `json:"tls_min_version,omitempty" yaml:"tls_min_version,omitempty" toml:"tls_min_version,omitempty"`
+	TLSInsecure     bool             `json:"tls_insecure,omitempty" yaml:"tls_insecure,omitempty" toml:"tls_insecure,omitempty"`
+	CertWarnDays    int              `json:"cert_warn_days" yaml:"cert_warn_days" toml:"cert_warn_days"`
+	ReportUntrusted bool             `json:"report_untrusted_tls,omitempty" yaml:"report_untrusted_tls,omitempty" toml:"report_untrusted_tls,omitempty"`
+
+	files       []string
+	sources     map[string]string
+	epSources   map[string]string
+	userLayer   *layer
+	projectPath string
+	overlayURLs map[string]bool
+	userPath    string
+	saveErr     error
+	saveMu      sync.Mutex
+	saved       *Config
+	loaded      []EndpointConfig
+	loadOpts    []LoadOption
+	validators  []Validator
+}
+
+type loadOptions struct {
+	path       string
+	dir        string
+	overrides  []override
+	validators []Validator
+}
+
+type LoadOption func(*loadOptions)
+
+func WithConfigFile(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.path = path
+	}
+}
+
+func WithWorkDir(dir string) LoadOption {
+	return func(o *loadOptions) {
+		o.dir = dir
+	}
+}
+
+func WithValidator(v Validator) LoadOption {
+	return func(o *loadOptions) {
+		o.validators = append(o.validators, v)
+	}
 }
 
 func DefaultConfig() *Config {
 	return &Config{
+		Version:        CurrentVersion,
 		Endpoints:      []EndpointConfig{},
 		DefaultPorts:   []int{3000, 3001, 8080, 8000, 5000, 9000, 4000, 4200, 5173},
 		CheckInterval:  1,
@@ -33,6 +177,14 @@ func DefaultConfig() *Config {
 		Timeout:        5,
 		MaxConcurrency: 100,
 		WindowSeconds:  30,
+		FlapThreshold:  4,
+		FlapWindow:     300,
+		CertWarnDays:   14,
+		SlowThreshold:  500,
+		AcceptStatus:   "200-399",
+		LogSampleRate:  0.1,
+		Host:           "localhost",
+		Theme:          "auto",
 	}
 }
 
@@ -40,10 +192,8 @@ func (c *Config) AddEndpoint(url, name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for _, ep := range c.Endpoints {
-		if ep.URL == url {
-			return
-		}
+	if c.endpointIndex(url) >= 0 {
+		return
 	}
 
 	c.Endpoints = append(c.Endpoints, EndpointConfig{
@@ -56,12 +206,168 @@ func (c *Config) RemoveEndpoint(url string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	for i, ep := range c.Endpoints {
-		if ep.URL == url {
-			c.Endpoints = append(c.Endpoints[:i], c.Endpoints[i+1:]...)
-			return
+	if i := c.endpointIndex(url); i >= 0 {
+		c.Endpoints = append(c.Endpoints[:i], c.Endpoints[i+1:]...)
+	}
+}
+
+func (c *Config) GetAlerts() []AlertConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]AlertConfig, len(c.Alerts))
+	copy(result, c.Alerts)
+	return result
+}
+
+func (c *Config) GetWebhooks() []WebhookConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]WebhookConfig, len(c.Webhooks))
+	copy(result, c.Webhooks)
+	return result
+}
+
+func (c *Config) GetScenarios() ([]ScenarioConfig, error) {
+	c.mu.RLock()
+	scenarios := make([]ScenarioConfig, len(c.Scenarios))
+	copy(scenarios, c.Scenarios)
+	path := c.scenarioPath()
+	c.mu.RUnlock()
+
+	if path == "" {
+		return scenarios, nil
+	}
+	l, err := readLayer(path, c.validators)
+	if err != nil {
+		return scenarios, err
+	}
+	for _, sc := range l.config.Scenarios {
+		replaced := false
+		for i := range scenarios {
+			if scenarios[i].Name == sc.Name {
+				scenarios[i] = sc
+				replaced = true
+			}
+		}
+		if !replaced {
+			scenarios = append(scenarios, sc)
+		}
+	}
+	return scenarios, nil
+}
+
+func (c *Config) scenarioPath() string {
+	return c.resolvePath("scenario_file", c.ScenarioFile)
+}
+
+func (c *Config) resolvePath(key, path string) string {
+	return c.resolveFrom(c.sources[key], path)
+}
+
+func (c *Config) resolveFrom(src, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	for _, file := range c.files {
+		if file == src {
+			return filepath.Join(filepath.Dir(file), path)
+		}
+	}
+	return path
+}
+
+func (c *Config) SocketDirPaths() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	dirs := make([]string, len(c.SocketDirs))
+	for i, dir := range c.SocketDirs {
+		dirs[i] = c.resolvePath("socket_dirs", dir)
+	}
+	return dirs
+}
+
+type TLSConfig struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	MinVersion string
+	Insecure   bool
+}
+
+func (c *Config) DefaultTLS() TLSConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.defaultTLS()
+}
+
+func (c *Config) defaultTLS() TLSConfig {
+	return TLSConfig{
+		CAFile:     c.resolvePath("tls_ca_file", c.TLSCAFile),
+		CertFile:   c.resolvePath("tls_cert_file", c.TLSCertFile),
+		KeyFile:    c.resolvePath("tls_key_file", c.TLSKeyFile),
+		MinVersion: c.TLSMinVersion,
+		Insecure:   c.TLSInsecure,
+	}
+}
+
+func (c *Config) EndpointTLS(ep EndpointConfig) TLSConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	opts := c.defaultTLS()
+	src := c.epSources[ep.URL]
+	if ep.TLSCAFile != "" {
+		opts.CAFile = c.resolveFrom(src, ep.TLSCAFile)
+	}
+	if ep.TLSCertFile != "" || ep.TLSKeyFile != "" {
+		opts.CertFile = c.resolveFrom(src, ep.TLSCertFile)
+		opts.KeyFile = c.resolveFrom(src, ep.TLSKeyFile)
+	}
+	if ep.TLSServerName != "" {
+		opts.ServerName = ep.TLSServerName
+	}
+	if ep.TLSMinVersion != "" {
+		opts.MinVersion = ep.TLSMinVersion
+	}
+	opts.Insecure = opts.Insecure || ep.TLSInsecure
+	return opts
+}
+
+func (c *Config) Feeder(name string) (FeederConfig, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, feeder := range c.Feeders {
+		if feeder.Name == name {
+			feeder.File = c.resolvePath("feeders", feeder.File)
+			return feeder, nil
 		}
 	}
+	return FeederConfig{}, fmt.Errorf("unknown feeder %q", name)
+}
+
+func (c *Config) AuthProfile(name string) (AuthConfig, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, a := range c.AuthProfiles {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return AuthConfig{}, fmt.Errorf("unknown auth profile %q", name)
+}
+
+func (c *Config) GetAuthProfiles() []AuthConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]AuthConfig, len(c.AuthProfiles))
+	copy(result, c.AuthProfiles)
+	return result
 }
 
 func (c *Config) GetEndpoints() []EndpointConfig {
@@ -73,65 +379,189 @@ func (c *Config) GetEndpoints() []EndpointConfig {
 	return result
 }
 
+func (c *Config) endpointIndex(url string) int {
+	for i, ep := range c.Endpoints {
+		if ep.URL == url {
+			return i
+		}
+	}
+	return -1
+}
+
+var UserFileNames = []string{
+	".localpulse.json",
+	".localpulse.yaml",
+	".localpulse.yml",
+	".localpulse.toml",
+}
+
 func configPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(homeDir, ".localpulse.json"), nil
+	for _, name := range UserFileNames {
+		candidate := filepath.Join(homeDir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return filepath.Join(homeDir, UserFileNames[0]), nil
 }
 
-func Load() (*Config, error) {
-	path, err := configPath()
-	if err != nil {
-		return DefaultConfig(), err
+func ActiveFiles(opts ...LoadOption) (userPath, projectPath string, err error) {
+	options := loadOptions{dir: "."}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	data, err := os.ReadFile(path)
+	userPath, err = configPath()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return DefaultConfig(), nil
+		return "", "", err
+	}
+	if _, err := os.Stat(userPath); err != nil {
+		userPath = ""
+	}
+
+	projectPath = options.path
+	if projectPath == "" {
+		projectPath, err = FindProjectConfig(options.dir)
+		if err != nil {
+			return "", "", err
 		}
-		return DefaultConfig(), err
 	}
+	return userPath, projectPath, nil
+}
+
+func failedLoad(err error) (*Config, error) {
+	cfg := DefaultConfig()
+	cfg.saveErr = fmt.Errorf("config not saved because it failed to load: %w", err)
+	return cfg, err
+}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return DefaultConfig(), err
+func Load(opts ...LoadOption) (*Config, error) {
+	options := loadOptions{dir: "."}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	if len(cfg.DefaultPorts) == 0 {
-		cfg.DefaultPorts = DefaultConfig().DefaultPorts
+	path, err := configPath()
+	if err != nil {
+		return failedLoad(err)
+	}
+	_, projectPath, err := ActiveFiles(opts...)
+	if err != nil {
+		return failedLoad(err)
 	}
-	if cfg.CheckInterval <= 0 {
-		cfg.CheckInterval = 1
+
+	cfg := DefaultConfig()
+	cfg.sources = make(map[string]string)
+	cfg.epSources = make(map[string]string)
+	cfg.overlayURLs = make(map[string]bool)
+	cfg.userPath = path
+
+	user, err := readLayer(path, options.validators)
+	if err != nil && !os.IsNotExist(err) {
+		return failedLoad(err)
 	}
-	if cfg.Timeout <= 0 {
-		cfg.Timeout = 5
+	if user != nil {
+		user.applyTo(cfg)
+		cfg.userLayer = user
+		cfg.files = append(cfg.files, path)
 	}
-	if cfg.MaxConcurrency <= 0 {
-		cfg.MaxConcurrency = 100
+
+	if projectPath != "" {
+		project, err := readLayer(projectPath, options.validators)
+		if err != nil {
+			return failedLoad(err)
+		}
+		project.applyTo(cfg)
+		for _, ep := range project.config.Endpoints {
+			cfg.overlayURLs[ep.URL] = true
+		}
+		cfg.projectPath = projectPath
+		cfg.files = append(cfg.files, projectPath)
 	}
-	if cfg.WindowSeconds <= 0 {
-		cfg.WindowSeconds = 30
+
+	cfg.validators = options.validators
+	if err := cfg.applyOverrides(options.overrides); err != nil {
+		return failedLoad(err)
 	}
 
-	return &cfg, nil
+	cfg.normalize()
+	cfg.saved = cfg.userView()
+	cfg.loaded = append([]EndpointConfig(nil), cfg.Endpoints...)
+	cfg.loadOpts = opts
+	return cfg, nil
 }
 
-func (c *Config) Save() error {
-	path, err := configPath()
-	if err != nil {
-		return err
+func (c *Config) normalize() {
+	if c.Endpoints == nil {
+		c.Endpoints = []EndpointConfig{}
+	}
+	if len(c.DefaultPorts) == 0 {
+		c.DefaultPorts = DefaultConfig().DefaultPorts
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = 1
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5
+	}
+	if c.MaxConcurrency <= 0 {
+		c.MaxConcurrency = 100
+	}
+	if c.WindowSeconds <= 0 {
+		c.WindowSeconds = 30
+	}
+	if c.FlapThreshold <= 0 {
+		c.FlapThreshold = 4
+	}
+	if c.FlapWindow <= 0 {
+		c.FlapWindow = 300
 	}
+	if c.SlowThreshold <= 0 {
+		c.SlowThreshold = 500
+	}
+	if c.AcceptStatus == "" {
+		c.AcceptStatus = "200-399"
+	}
+	if c.LogSampleRate < 0 || c.LogSampleRate > 1 {
+		c.LogSampleRate = 0.1
+	}
+	if c.Host == "" {
+		c.Host = "localhost"
+	}
+	if c.Theme == "" {
+		c.Theme = "auto"
+	}
+}
 
+func (c *Config) Files() []string {
 	c.mu.RLock()
-	data, err := json.MarshalIndent(c, "", "  ")
-	c.mu.RUnlock()
+	defer c.mu.RUnlock()
 
-	if err != nil {
-		return err
+	result := make([]string, len(c.files))
+	copy(result, c.files)
+	return result
+}
+
+func (c *Config) Source(key string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if src, ok := c.sources[key]; ok {
+		return src
 	}
+	return "default"
+}
 
-	return os.WriteFile(path, data, 0600)
+func (c *Config) EndpointSource(url string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if src, ok := c.epSources[url]; ok {
+		return src
+	}
+	return "runtime"
 }
@@ -0,0 +1,606 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+type editor interface {
+	setKey(key string, value any) error
+	upsertEndpoint(ep EndpointConfig) error
+	removeEndpoint(url string) error
+	bytes() ([]byte, error)
+}
+
+func newEditor(format Format, data []byte) (editor, error) {
+	switch format {
+	case FormatTOML:
+		return newTOMLEditor(data), nil
+	default:
+		return newNodeEditor(format, data)
+	}
+}
+
+type nodeEditor struct {
+	format Format
+	root   *yaml.Node
+}
+
+func newNodeEditor(format Format, data []byte) (*nodeEditor, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var root *yaml.Node
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	} else {
+		root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level must be a mapping")
+	}
+
+	return &nodeEditor{format: format, root: &doc}, nil
+}
+
+func (e *nodeEditor) mapping() *yaml.Node {
+	return e.root.Content[0]
+}
+
+func (e *nodeEditor) lookup(key string) (*yaml.Node, int) {
+	m := e.mapping()
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1], i + 1
+		}
+	}
+	return nil, -1
+}
+
+func encodeNode(value any) (*yaml.Node, error) {
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (e *nodeEditor) setKey(key string, value any) error {
+	n, err := encodeNode(value)
+	if err != nil {
+		return err
+	}
+
+	old, idx := e.lookup(key)
+	if old == nil {
+		m := e.mapping()
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, n)
+		return nil
+	}
+
+	n.LineComment = old.LineComment
+	n.HeadComment = old.HeadComment
+	n.FootComment = old.FootComment
+	if old.Kind == yaml.SequenceNode && n.Kind == yaml.SequenceNode {
+		n.Style = old.Style
+	}
+	e.mapping().Content[idx] = n
+	return nil
+}
+
+func (e *nodeEditor) endpoints() *yaml.Node {
+	seq, idx := e.lookup("endpoints")
+	if seq != nil && seq.Kind == yaml.SequenceNode {
+		return seq
+	}
+
+	seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	m := e.mapping()
+	if idx < 0 {
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "endpoints"}, seq)
+	} else {
+		m.Content[idx] = seq
+	}
+	return seq
+}
+
+func endpointURL(item *yaml.Node) string {
+	if item.Kind != yaml.MappingNode {
+		return ""
+	}
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value == "url" {
+			return item.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
+func (e *nodeEditor) upsertEndpoint(ep EndpointConfig) error {
+	n, err := encodeNode(ep)
+	if err != nil {
+		return err
+	}
+
+	seq := e.endpoints()
+	for i, item := range seq.Content {
+		if endpointURL(item) == ep.URL {
+			n.HeadComment = item.HeadComment
+			n.LineComment = item.LineComment
+			n.Style = item.Style
+			seq.Content[i] = n
+			return nil
+		}
+	}
+	seq.Content = append(seq.Content, n)
+	return nil
+}
+
+func (e *nodeEditor) removeEndpoint(url string) error {
+	seq, _ := e.lookup("endpoints")
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+	for i, item := range seq.Content {
+		if endpointURL(item) == url {
+			seq.Content = append(seq.Content[:i], seq.Content[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e *nodeEditor) bytes() ([]byte, error) {
+	if e.format == FormatJSON {
+		var buf bytes.Buffer
+		if err := writeJSONNode(&buf, e.mapping(), 0); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(e.root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSONNode(buf *bytes.Buffer, n *yaml.Node, depth int) error {
+	indent := strings.Repeat("  ", depth)
+	inner := strings.Repeat("  ", depth+1)
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, _ := json.Marshal(n.Content[i].Value)
+			buf.WriteString(inner)
+			buf.Write(key)
+			buf.WriteString(": ")
+			if err := writeJSONNode(buf, n.Content[i+1], depth+1); err != nil {
+				return err
+			}
+			if i+2 < len(n.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		scalars := true
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				scalars = false
+				break
+			}
+		}
+		if scalars {
+			buf.WriteByte('[')
+			for i, item := range n.Content {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				if err := writeJSONNode(buf, item, depth+1); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range n.Content {
+			buf.WriteString(inner)
+			if err := writeJSONNode(buf, item, depth+1); err != nil {
+				return err
+			}
+			if i+1 < len(n.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!int", "!!float", "!!bool":
+			buf.WriteString(n.Value)
+		case "!!null":
+			buf.WriteString("null")
+		default:
+			s, _ := json.Marshal(n.Value)
+			buf.Write(s)
+		}
+	case yaml.AliasNode:
+		return writeJSONNode(buf, n.Alias, depth)
+	default:
+		return fmt.Errorf("unsupported node kind %v", n.Kind)
+	}
+	return nil
+}
+
+var (
+	tomlHeaderPattern = regexp.MustCompile(`^\s*\[(\[?)\s*([^\]]+?)\s*\]\]?\s*(#.*)?$`)
+	tomlKeyPattern    = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=`)
+)
+
+type tomlEditor struct {
+	lines []string
+}
+
+func newTOMLEditor(data []byte) *tomlEditor {
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return &tomlEditor{}
+	}
+	return &tomlEditor{lines: strings.Split(text, "\n")}
+}
+
+func (e *tomlEditor) firstHeader() int {
+	code := e.statements()
+	for i, line := range e.lines {
+		if code[i] && tomlHeaderPattern.MatchString(line) {
+			return i
+		}
+	}
+	return len(e.lines)
+}
+
+func (e *tomlEditor) statements() []bool {
+	code := make([]bool, len(e.lines))
+	var s tomlScanner
+	for i, line := range e.lines {
+		code[i] = s.quote == "" && s.depth <= 0
+		s.scan(line)
+	}
+	return code
+}
+
+func (e *tomlEditor) findKey(key string, from, to int) (start, end int) {
+	code := e.statements()
+	for i := from; i < to; i++ {
+		m := tomlKeyPattern.FindStringSubmatch(e.lines[i])
+		if !code[i] || m == nil || m[1] != key {
+			continue
+		}
+		var s tomlScanner
+		s.scan(e.lines[i][len(m[0]):])
+		end = i
+		for end+1 < to && (s.depth > 0 || s.quote != "") {
+			end++
+			s.scan(e.lines[end])
+		}
+		return i, end
+	}
+	return -1, -1
+}
+
+func (e *tomlEditor) trailingComment(start, end int) string {
+	var s tomlScanner
+	for _, line := range e.lines[start : end+1] {
+		s.scan(line)
+	}
+	return s.comment
+}
+
+type tomlScanner struct {
+	depth   int
+	quote   string
+	comment string
+}
+
+func (s *tomlScanner) scan(line string) {
+	s.comment = ""
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if s.quote != "" {
+			switch {
+			case c == '\\' && s.quote[0] == '"':
+				i++
+			case strings.HasPrefix(line[i:], s.quote):
+				i += len(s.quote) - 1
+				s.quote = ""
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			s.quote = line[i : i+1]
+			if strings.HasPrefix(line[i:], strings.Repeat(s.quote, 3)) {
+				s.quote = line[i : i+3]
+				i += 2
+			}
+		case '#':
+			s.comment = strings.TrimRight(line[i:], " ")
+			return
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			s.depth--
+		}
+	}
+	if len(s.quote) == 1 {
+		s.quote = ""
+	}
+}
+
+func tomlLines(v any) ([]string, error) {
+	data, err := toml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(string(data), "\n"), "\n"), nil
+}
+
+func (e *tomlEditor) replace(start, end int, lines []string) {
+	out := make([]string, 0, len(e.lines)-(end-start+1)+len(lines))
+	out = append(out, e.lines[:start]...)
+	out = append(out, lines...)
+	out = append(out, e.lines[end+1:]...)
+	e.lines = out
+}
+
+func (e *tomlEditor) insert(at int, lines []string) {
+	out := make([]string, 0, len(e.lines)+len(lines))
+	out = append(out, e.lines[:at]...)
+	out = append(out, lines...)
+	out = append(out, e.lines[at:]...)
+	e.lines = out
+}
+
+func (e *tomlEditor) setKey(key string, value any) error {
+	lines, err := tomlLines(map[string]any{key: value})
+	if err != nil {
+		return err
+	}
+
+	top := e.firstHeader()
+	start, end := e.findKey(key, 0, top)
+	if start < 0 {
+		at := 0
+		for i := 0; i < top; i++ {
+			trimmed := strings.TrimSpace(e.lines[i])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				at = i + 1
+			}
+		}
+		e.insert(at, lines)
+		return nil
+	}
+
+	if comment := e.trailingComment(start, end); comment != "" && len(lines) == 1 {
+		lines[0] += " " + comment
+	}
+	e.replace(start, end, lines)
+	return nil
+}
+
+func (e *tomlEditor) endpointTables() [][2]int {
+	var tables [][2]int
+	current := -1
+	code := e.statements()
+	for i, line := range e.lines {
+		m := tomlHeaderPattern.FindStringSubmatch(line)
+		if !code[i] || m == nil {
+			continue
+		}
+		if current >= 0 {
+			tables = append(tables, [2]int{current, e.blockEnd(current, i)})
+			current = -1
+		}
+		if m[1] == "[" && m[2] == "endpoints" {
+			current = i
+		}
+	}
+	if current >= 0 {
+		tables = append(tables, [2]int{current, e.blockEnd(current, len(e.lines))})
+	}
+	return tables
+}
+
+func (e *tomlEditor) blockEnd(start, next int) int {
+	end := next - 1
+	for end > start {
+		trimmed := strings.TrimSpace(e.lines[end])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+func (e *tomlEditor) tableURL(table [2]int) string {
+	start, end := e.findKey("url", table[0]+1, table[1]+1)
+	if start < 0 {
+		return ""
+	}
+	var v struct {
+		URL string `toml:"url"`
+	}
+	if err := toml.Unmarshal([]byte(strings.Join(e.lines[start:end+1], "\n")), &v); err != nil {
+		return ""
+	}
+	return v.URL
+}
+
+func (e *tomlEditor) upsertEndpoint(ep EndpointConfig) error {
+	if start, _ := e.findKey("endpoints", 0, e.firstHeader()); start >= 0 {
+		return e.patchInlineEndpoints(func(eps []EndpointConfig) []EndpointConfig {
+			return mergeEndpoints(eps, []EndpointConfig{ep})
+		})
+	}
+
+	for _, table := range e.endpointTables() {
+		if e.tableURL(table) == ep.URL {
+			return e.patchTable(table, ep)
+		}
+	}
+
+	fields, err := tomlLines(ep)
+	if err != nil {
+		return err
+	}
+	block := append([]string{"[[endpoints]]"}, fields...)
+	if len(e.lines) > 0 {
+		block = append([]string{""}, block...)
+	}
+	e.lines = append(e.lines, block...)
+	return nil
+}
+
+func (e *tomlEditor) patchTable(table [2]int, ep EndpointConfig) error {
+	var v struct {
+		Endpoints []EndpointConfig `toml:"endpoints"`
+	}
+	if err := toml.Unmarshal([]byte(strings.Join(e.lines[table[0]:table[1]+1], "\n")), &v); err != nil {
+		return err
+	}
+	if len(v.Endpoints) != 1 {
+		return fmt.Errorf("endpoint table at line %d is not a single table", table[0]+1)
+	}
+
+	fields, err := tomlLines(ep)
+	if err != nil {
+		return err
+	}
+	lines := make(map[string]string, len(fields))
+	for _, line := range fields {
+		if m := tomlKeyPattern.FindStringSubmatch(line); m != nil {
+			lines[m[1]] = line
+		}
+	}
+
+	ov := reflect.ValueOf(v.Endpoints[0])
+	nv := reflect.ValueOf(ep)
+	t := nv.Type()
+	end := table[1]
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldKey(t.Field(i))
+		if key == "" || reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+		line, set := lines[key]
+		start, stop := e.findKey(key, table[0]+1, end+1)
+		switch {
+		case start < 0 && set:
+			e.insert(end+1, []string{line})
+			end++
+		case start < 0:
+		case set:
+			if comment := e.trailingComment(start, stop); comment != "" {
+				line += " " + comment
+			}
+			e.replace(start, stop, []string{line})
+			end -= stop - start
+		default:
+			e.replace(start, stop, nil)
+			end -= stop - start + 1
+		}
+	}
+	return nil
+}
+
+func (e *tomlEditor) removeEndpoint(url string) error {
+	if start, _ := e.findKey("endpoints", 0, e.firstHeader()); start >= 0 {
+		return e.patchInlineEndpoints(func(eps []EndpointConfig) []EndpointConfig {
+			out := eps[:0]
+			for _, ep := range eps {
+				if ep.URL != url {
+					out = append(out, ep)
+				}
+			}
+			return out
+		})
+	}
+
+	for _, table := range e.endpointTables() {
+		if e.tableURL(table) == url {
+			start, end := table[0], table[1]
+			for start > 0 && strings.HasPrefix(strings.TrimSpace(e.lines[start-1]), "#") {
+				start--
+			}
+			if end+1 < len(e.lines) && strings.TrimSpace(e.lines[end+1]) == "" {
+				end++
+			} else if start > 0 && strings.TrimSpace(e.lines[start-1]) == "" {
+				start--
+			}
+			e.replace(start, end, nil)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (e *tomlEditor) patchInlineEndpoints(update func([]EndpointConfig) []EndpointConfig) error {
+	start, end := e.findKey("endpoints", 0, e.firstHeader())
+	var v struct {
+		Endpoints []EndpointConfig `toml:"endpoints"`
+	}
+	if err := toml.Unmarshal([]byte(strings.Join(e.lines[start:end+1], "\n")), &v); err != nil {
+		return err
+	}
+
+	var items []string
+	for _, ep := range update(v.Endpoints) {
+		fields, err := tomlLines(ep)
+		if err != nil {
+			return err
+		}
+		items = append(items, "{ "+strings.Join(fields, ", ")+" }")
+	}
+
+	line := "endpoints = [" + strings.Join(items, ", ") + "]"
+	if comment := e.trailingComment(start, end); comment != "" {
+		line += " " + comment
+	}
+	e.replace(start, end, []string{line})
+	return nil
+}
+
+func (e *tomlEditor) bytes() ([]byte, error) {
+	return []byte(strings.Join(e.lines, "\n") + "\n"), nil
+}
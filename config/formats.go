@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+func FormatForPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+type document struct {
+	raw   map[string]any
+	lines map[string]int
+}
+
+func (d *document) lineFor(path string) int {
+	for path != "" {
+		if line, ok := d.lines[path]; ok {
+			return line
+		}
+		idx := strings.LastIndexAny(path, ".[")
+		if idx < 0 {
+			break
+		}
+		path = path[:idx]
+	}
+	return 0
+}
+
+func parseDocument(format Format, data []byte) (*document, *FieldError) {
+	doc := &document{lines: make(map[string]int)}
+
+	switch format {
+	case FormatYAML:
+		var root yaml.Node
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, yamlError(err)
+		}
+		if err := root.Decode(&doc.raw); err != nil {
+			return nil, yamlError(err)
+		}
+		if len(root.Content) > 0 {
+			locateYAML(root.Content[0], "", doc.lines)
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &doc.raw); err != nil {
+			var derr *toml.DecodeError
+			if errors.As(err, &derr) {
+				line, _ := derr.Position()
+				return nil, &FieldError{Line: line, Message: derr.Error()}
+			}
+			return nil, &FieldError{Message: err.Error()}
+		}
+		locateTOML(data, doc.lines)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc.raw); err != nil {
+			var serr *json.SyntaxError
+			if errors.As(err, &serr) {
+				return nil, &FieldError{Line: lineAt(data, serr.Offset), Message: serr.Error()}
+			}
+			var terr *json.UnmarshalTypeError
+			if errors.As(err, &terr) {
+				return nil, &FieldError{Line: lineAt(data, terr.Offset), Message: "top level must be an object"}
+			}
+			if errors.Is(err, io.EOF) {
+				doc.raw = map[string]any{}
+				break
+			}
+			return nil, &FieldError{Message: err.Error()}
+		}
+		locateJSON(data, doc.lines)
+	}
+
+	if doc.raw == nil {
+		doc.raw = map[string]any{}
+	}
+	return doc, nil
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+func yamlError(err error) *FieldError {
+	fe := &FieldError{Message: err.Error()}
+	if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		fe.Line, _ = strconv.Atoi(m[1])
+	}
+	return fe
+}
+
+func locateYAML(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := joinPath(path, key.Value)
+			lines[child] = key.Line
+			locateYAML(value, child, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := path + "[" + strconv.Itoa(i) + "]"
+			lines[child] = item.Line
+			locateYAML(item, child, lines)
+		}
+	}
+}
+
+func locateJSON(data []byte, lines map[string]int) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+	locateJSONValue(dec, data, tok, "", lines)
+}
+
+func locateJSONValue(dec *json.Decoder, data []byte, tok json.Token, path string, lines map[string]int) {
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return
+	}
+
+	switch delim {
+	case '{':
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return
+			}
+			key, _ := keyTok.(string)
+			child := joinPath(path, key)
+			lines[child] = lineAt(data, dec.InputOffset())
+
+			valueTok, err := dec.Token()
+			if err != nil {
+				return
+			}
+			locateJSONValue(dec, data, valueTok, child, lines)
+		}
+		dec.Token()
+	case '[':
+		for i := 0; dec.More(); i++ {
+			valueTok, err := dec.Token()
+			if err != nil {
+				return
+			}
+			child := path + "[" + strconv.Itoa(i) + "]"
+			lines[child] = lineAt(data, dec.InputOffset())
+			locateJSONValue(dec, data, valueTok, child, lines)
+		}
+		dec.Token()
+	}
+}
+
+func locateTOML(data []byte, lines map[string]int) {
+	p := unstable.Parser{}
+	p.Reset(data)
+
+	prefix := ""
+	arrayCounts := make(map[string]int)
+
+	for p.NextExpression() {
+		expr := p.Expression()
+
+		keys, line := tomlKey(&p, expr.Key())
+		switch expr.Kind {
+		case unstable.Table:
+			prefix = keys
+			lines[prefix] = line
+		case unstable.ArrayTable:
+			idx := arrayCounts[keys]
+			arrayCounts[keys] = idx + 1
+			prefix = keys + "[" + strconv.Itoa(idx) + "]"
+			lines[keys] = line
+			lines[prefix] = line
+		case unstable.KeyValue:
+			lines[joinPath(prefix, keys)] = line
+		}
+	}
+}
+
+func tomlKey(p *unstable.Parser, it unstable.Iterator) (string, int) {
+	var parts []string
+	line := 0
+	for it.Next() {
+		n := it.Node()
+		if line == 0 {
+			line = p.Shape(n.Raw).Start.Line
+		}
+		parts = append(parts, string(n.Data))
+	}
+	return strings.Join(parts, "."), line
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func marshal(format Format, v any) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(v)
+	case FormatTOML:
+		return toml.Marshal(v)
+	case FormatJSON:
+		return json.MarshalIndent(v, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+}
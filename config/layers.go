@@ -0,0 +1,165 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+var ProjectFileNames = []string{
+	"localpulse.json",
+	".localpulse.json",
+	"localpulse.yaml",
+	".localpulse.yaml",
+	"localpulse.yml",
+	".localpulse.yml",
+	"localpulse.toml",
+	".localpulse.toml",
+}
+
+type layer struct {
+	path    string
+	config  Config
+	present map[string]any
+}
+
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	userPath, _ := configPath()
+	if userPath != "" {
+		userPath, _ = filepath.Abs(userPath)
+	}
+
+	for {
+		for _, name := range ProjectFileNames {
+			candidate := filepath.Join(dir, name)
+			if candidate == userPath {
+				continue
+			}
+			info, err := os.Stat(candidate)
+			if err == nil && !info.IsDir() {
+				return candidate, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func readLayer(path string, validators []Validator) (*layer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ferr := parseDocument(FormatForPath(path), data)
+	if ferr != nil {
+		ferr.File = path
+		return nil, &ValidationError{Errors: []FieldError{*ferr}}
+	}
+
+	if ferr := migrate(doc.raw); ferr != nil {
+		ferr.File = path
+		ferr.Line = doc.lineFor(ferr.Field)
+		return nil, &ValidationError{Errors: []FieldError{*ferr}}
+	}
+
+	errs := checkTypes("", doc.raw, reflect.TypeOf((*Config)(nil)).Elem())
+
+	l := &layer{path: path, present: doc.raw}
+	if len(errs) == 0 {
+		normalized, err := json.Marshal(doc.raw)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(normalized, &l.config); err != nil {
+			return nil, &ValidationError{Errors: []FieldError{{File: path, Message: err.Error()}}}
+		}
+		errs = l.config.validate(validators)
+	}
+
+	if len(errs) > 0 {
+		for i := range errs {
+			errs[i].File = path
+			errs[i].Line = doc.lineFor(errs[i].Field)
+		}
+		return nil, &ValidationError{Errors: errs}
+	}
+	return l, nil
+}
+
+func ValidateFile(path string, opts ...LoadOption) error {
+	var options loadOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	_, err := readLayer(path, options.validators)
+	return err
+}
+
+func (l *layer) applyTo(dst *Config) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(&l.config).Elem()
+	t := dv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		if _, ok := l.present[key]; !ok {
+			continue
+		}
+
+		if key == "endpoints" {
+			dst.Endpoints = mergeEndpoints(dst.Endpoints, l.config.Endpoints)
+			for _, ep := range l.config.Endpoints {
+				dst.epSources[ep.URL] = l.path
+			}
+		} else {
+			dv.Field(i).Set(sv.Field(i))
+		}
+		dst.sources[key] = l.path
+	}
+}
+
+func mergeEndpoints(base, over []EndpointConfig) []EndpointConfig {
+	result := make([]EndpointConfig, len(base), len(base)+len(over))
+	copy(result, base)
+
+	for _, ep := range over {
+		replaced := false
+		for i := range result {
+			if result[i].URL == ep.URL {
+				result[i] = ep
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result = append(result, ep)
+		}
+	}
+	return result
+}
+
+func fieldKey(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "" || tag == "-" {
+		return ""
+	}
+	return strings.Split(tag, ",")[0]
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func setHome(t *testing.T, dir string) {
+	t.Helper()
+
+	origHome := os.Getenv("HOME")
+	origUserProfile := os.Getenv("USERPROFILE")
+	t.Cleanup(func() {
+		os.Setenv("HOME", origHome)
+		os.Setenv("USERPROFILE", origUserProfile)
+	})
+
+	os.Setenv("HOME", dir)
+	os.Setenv("USERPROFILE", dir)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	setHome(t, t.TempDir())
+
+	nested := filepath.Join(root, "services", "api", "src")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FindProjectConfig() = %q, want none", got)
+	}
+
+	want := filepath.Join(root, "services", ".localpulse.yaml")
+	writeFile(t, want, "load_test_rps: 20\n")
+
+	got, err = FindProjectConfig(nested)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestFindProjectConfig_SkipsUserConfig(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	writeFile(t, filepath.Join(home, ".localpulse.json"), `{"load_test_rps": 5}`)
+
+	got, err := FindProjectConfig(home)
+	if err != nil {
+		t.Fatalf("FindProjectConfig() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("FindProjectConfig() = %q, the user config should not count as a project file", got)
+	}
+}
+
+func TestLoad_ProjectLayeredOverUser(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	setHome(t, home)
+
+	userPath := filepath.Join(home, ".localpulse.json")
+	writeFile(t, userPath, `{
+  "endpoints": [{"url": "http://localhost:9000/mine", "name": "Mine"}],
+  "load_test_rps": 5,
+  "timeout_seconds": 3
+}`)
+	projectPath := filepath.Join(project, "localpulse.yaml")
+	writeFile(t, projectPath, `
+endpoints:
+  - url: http://localhost:3000/health
+    name: API
+default_ports: [3000, 3001]
+load_test_rps: 50
+`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.LoadTestRPS != 50 {
+		t.Errorf("LoadTestRPS = %d, want 50 from project", cfg.LoadTestRPS)
+	}
+	if cfg.Timeout != 3 {
+		t.Errorf("Timeout = %d, want 3 from user", cfg.Timeout)
+	}
+	if len(cfg.DefaultPorts) != 2 {
+		t.Errorf("DefaultPorts = %v, want project ports", cfg.DefaultPorts)
+	}
+	if len(cfg.Endpoints) != 2 {
+		t.Errorf("Endpoints = %v, want user and project endpoints", cfg.Endpoints)
+	}
+	if got := cfg.Source("load_test_rps"); got != projectPath {
+		t.Errorf("Source(load_test_rps) = %q, want %q", got, projectPath)
+	}
+	if got := cfg.Source("timeout_seconds"); got != userPath {
+		t.Errorf("Source(timeout_seconds) = %q, want %q", got, userPath)
+	}
+	if got := cfg.Source("window_seconds"); got != "default" {
+		t.Errorf("Source(window_seconds) = %q, want default", got)
+	}
+	if files := cfg.Files(); len(files) != 2 {
+		t.Errorf("Files() = %v, want user and project", files)
+	}
+}
+
+func TestLoad_ExplicitConfigFile(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "localpulse.json"), `{"load_test_rps": 50}`)
+
+	explicit := filepath.Join(t.TempDir(), "team.json")
+	writeFile(t, explicit, `{"load_test_rps": 70}`)
+
+	cfg, err := Load(WithWorkDir(project), WithConfigFile(explicit))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LoadTestRPS != 70 {
+		t.Errorf("LoadTestRPS = %d, want 70 from --config file", cfg.LoadTestRPS)
+	}
+}
+
+func TestLoad_ZeroLogSampleRate(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogSampleRate != 0.1 {
+		t.Errorf("LogSampleRate = %v, want default 0.1 when unset", cfg.LogSampleRate)
+	}
+
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), "log_sample_rate: 0\n")
+	cfg, err = Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.LogSampleRate != 0 {
+		t.Errorf("LogSampleRate = %v, want explicit 0 kept", cfg.LogSampleRate)
+	}
+}
+
+func TestSave_DoesNotCopyProjectValues(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	setHome(t, home)
+
+	userPath := filepath.Join(home, ".localpulse.json")
+	writeFile(t, userPath, `{"load_test_rps": 5}`)
+	writeFile(t, filepath.Join(project, "localpulse.json"), `{
+  "endpoints": [{"url": "http://localhost:3000/health"}],
+  "load_test_rps": 50
+}`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.AddEndpoint("http://localhost:8080/", "Personal")
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(userPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Endpoints   []EndpointConfig `json:"endpoints"`
+		LoadTestRPS int              `json:"load_test_rps"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.LoadTestRPS != 5 {
+		t.Errorf("saved load_test_rps = %d, want the user's 5", saved.LoadTestRPS)
+	}
+	if len(saved.Endpoints) != 1 || saved.Endpoints[0].URL != "http://localhost:8080/" {
+		t.Errorf("saved endpoints = %v, want only the personal endpoint", saved.Endpoints)
+	}
+}
+
+func TestLoad_ScenarioFile(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), `scenario_file: load/flows.yaml
+scenarios:
+  - name: browse
+    steps:
+      - url: http://localhost:3000/
+  - name: checkout
+    steps:
+      - url: http://localhost:3000/old
+`)
+	writeFile(t, filepath.Join(project, "load", "flows.yaml"), `scenarios:
+  - name: checkout
+    vars:
+      base: http://localhost:3000
+    steps:
+      - name: login
+        method: POST
+        url: ${base}/login
+        body: '{"user":"alice"}'
+        extract:
+          token: json:data.token
+      - name: cart
+        url: ${base}/cart
+        headers:
+          Authorization: Bearer ${token}
+`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	scenarios, err := cfg.GetScenarios()
+	if err != nil {
+		t.Fatalf("GetScenarios() error = %v", err)
+	}
+	if len(scenarios) != 2 || scenarios[1].Name != "checkout" || len(scenarios[1].Steps) != 2 {
+		t.Fatalf("GetScenarios() = %+v, want browse and the checkout from the scenario file", scenarios)
+	}
+
+	if steps := scenarios[1].Steps; steps[0].Extract["token"] != "json:data.token" || steps[1].Headers["Authorization"] != "Bearer ${token}" {
+		t.Errorf("checkout steps = %+v", steps)
+	}
+
+	watched := cfg.WatchPaths()
+	if last := watched[len(watched)-1]; last != filepath.Join(project, "load", "flows.yaml") {
+		t.Errorf("WatchPaths() = %v, want the scenario file included", watched)
+	}
+}
+
+func TestLoad_Feeder(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), `feeders:
+  - name: users
+    file: data/users.csv
+    mode: unique
+endpoints:
+  - url: localhost:3000/users/${id}
+    method: PUT
+    headers:
+      X-Request-Id: ${uuid}
+    body: '{"name":"${name}"}'
+    feed: users
+  - url: localhost:3000/health
+  - url: localhost:3000/orphan
+    feed: missing
+`)
+	writeFile(t, filepath.Join(project, "data", "users.csv"), "id,name\n1,alice\n2,bob\n")
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	feeder, err := cfg.Feeder("users")
+	if err != nil {
+		t.Fatalf("Feeder() error = %v", err)
+	}
+	if want := (FeederConfig{Name: "users", File: filepath.Join(project, "data", "users.csv"), Mode: "unique"}); feeder != want {
+		t.Errorf("Feeder(users) = %+v, want %+v", feeder, want)
+	}
+	if _, err := cfg.Feeder("missing"); err == nil || !strings.Contains(err.Error(), `unknown feeder "missing"`) {
+		t.Errorf("Feeder(missing) error = %v, want unknown feeder", err)
+	}
+}
+
+func TestLoad_TLSOptions(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), `tls_ca_file: certs/rootCA.pem
+tls_min_version: "1.2"
+endpoints:
+  - url: https://localhost:8443/
+  - url: https://127.0.0.1:9443/
+    tls_server_name: api.local
+    tls_cert_file: certs/client.pem
+    tls_key_file: certs/client-key.pem
+    tls_min_version: "1.3"
+`)
+
+	cfg, err := Load(WithWorkDir(project), WithEnv([]string{"LOCALPULSE_TLS_INSECURE=true"}))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ca := filepath.Join(project, "certs", "rootCA.pem")
+	endpoints := cfg.GetEndpoints()
+	if got := cfg.EndpointTLS(endpoints[0]); got != (TLSConfig{CAFile: ca, MinVersion: "1.2", Insecure: true}) {
+		t.Errorf("EndpointTLS(8443) = %+v, want the global options", got)
+	}
+	want := TLSConfig{
+		CAFile:     ca,
+		CertFile:   filepath.Join(project, "certs", "client.pem"),
+		KeyFile:    filepath.Join(project, "certs", "client-key.pem"),
+		ServerName: "api.local",
+		MinVersion: "1.3",
+		Insecure:   true,
+	}
+	if got := cfg.EndpointTLS(endpoints[1]); got != want {
+		t.Errorf("EndpointTLS(9443) = %+v, want %+v", got, want)
+	}
+	if src := cfg.Source("tls_insecure"); src != "env LOCALPULSE_TLS_INSECURE" {
+		t.Errorf("Source(tls_insecure) = %q", src)
+	}
+}
+
+func TestLoad_UnixSockets(t *testing.T) {
+	setHome(t, t.TempDir())
+	project := t.TempDir()
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), `socket_dirs: [run, /var/run]
+endpoints:
+  - url: unix:///var/run/docker.sock:/_ping
+    timeout_seconds: 2
+`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.SocketDirPaths(); !slices.Equal(got, []string{filepath.Join(project, "run"), "/var/run"}) {
+		t.Errorf("SocketDirPaths() = %v", got)
+	}
+
+	env, err := Load(WithWorkDir(project), WithEnv([]string{"LOCALPULSE_SOCKET_DIRS=/tmp/a, /tmp/b"}))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := env.SocketDirPaths(); !slices.Equal(got, []string{"/tmp/a", "/tmp/b"}) {
+		t.Errorf("SocketDirPaths() with env = %v", got)
+	}
+}
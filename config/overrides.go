@@ -0,0 +1,222 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const EnvPrefix = "LOCALPULSE_"
+
+var structuredKeys = map[string]bool{"alerts": true, "webhooks": true, "scenarios": true, "feeders": true, "auth": true}
+
+type override struct {
+	key    string
+	value  string
+	source string
+}
+
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+func WithOverride(key, value, source string) LoadOption {
+	return func(o *loadOptions) {
+		o.overrides = append(o.overrides, override{key: key, value: value, source: source})
+	}
+}
+
+func WithEnv(environ []string) LoadOption {
+	return func(o *loadOptions) {
+		for _, kv := range environ {
+			name, value, ok := strings.Cut(kv, "=")
+			if !ok || !strings.HasPrefix(name, EnvPrefix) || value == "" {
+				continue
+			}
+			if name == EnvPrefix+"CONFIG" {
+				o.path = value
+				continue
+			}
+			key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+			if overridable(key) {
+				o.overrides = append(o.overrides, override{key: key, value: value, source: "env " + name})
+			}
+		}
+	}
+}
+
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+func Keys() []string {
+	t := reflect.TypeOf((*Config)(nil)).Elem()
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if key := fieldKey(t.Field(i)); key != "" && key != "version" && !structuredKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func overridable(key string) bool {
+	for _, k := range Keys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Config) applyOverrides(overrides []override) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	cv := reflect.ValueOf(c).Elem()
+	t := cv.Type()
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		if key := fieldKey(t.Field(i)); key != "" {
+			fields[key] = i
+		}
+	}
+
+	var errs []FieldError
+	for _, o := range overrides {
+		idx, ok := fields[o.key]
+		if !ok || o.key == "version" {
+			errs = append(errs, FieldError{File: o.source, Field: o.key, Message: "unknown setting"})
+			continue
+		}
+
+		if o.key == "endpoints" {
+			var eps []EndpointConfig
+			for _, url := range splitList(o.value) {
+				eps = append(eps, EndpointConfig{URL: url})
+				c.epSources[url] = o.source
+				c.overlayURLs[url] = true
+			}
+			c.Endpoints = mergeEndpoints(c.Endpoints, eps)
+			c.sources[o.key] = o.source
+			continue
+		}
+
+		if err := setFromString(cv.Field(idx), o.value); err != nil {
+			errs = append(errs, FieldError{File: o.source, Field: o.key, Message: err.Error()})
+			continue
+		}
+		c.sources[o.key] = o.source
+	}
+
+	if len(errs) == 0 {
+		for _, fe := range c.validate(c.validators) {
+			key := fe.Field
+			if idx := strings.IndexAny(key, ".["); idx >= 0 {
+				key = key[:idx]
+			}
+			fe.File = c.sources[key]
+			errs = append(errs, fe)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func setFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", s)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(strings.TrimSpace(s))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			v.Set(reflect.ValueOf(splitList(s)))
+			return nil
+		}
+		if v.Type().Elem().Kind() != reflect.Int {
+			return fmt.Errorf("cannot be set from a string")
+		}
+		var ports []int
+		for _, item := range splitList(s) {
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return fmt.Errorf("expected a comma-separated list of integers, got %q", s)
+			}
+			ports = append(ports, n)
+		}
+		v.Set(reflect.ValueOf(ports))
+	default:
+		return fmt.Errorf("cannot be set from a string")
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (c *Config) Settings() []Setting {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cv := reflect.ValueOf(c).Elem()
+	t := cv.Type()
+
+	var settings []Setting
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldKey(t.Field(i))
+		if key == "" || key == "version" || key == "endpoints" || structuredKeys[key] {
+			continue
+		}
+		src, ok := c.sources[key]
+		if !ok {
+			src = "default"
+		}
+		settings = append(settings, Setting{Key: key, Value: formatValue(cv.Field(i)), Source: src})
+	}
+	return settings
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			parts[i] = formatValue(v.Index(i))
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
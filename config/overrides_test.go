@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverridePrecedence(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	project := t.TempDir()
+
+	writeFile(t, filepath.Join(home, ".localpulse.json"), `{"load_test_rps": 10, "timeout_seconds": 3, "max_concurrency": 20}`)
+	writeFile(t, filepath.Join(project, "localpulse.yaml"), "load_test_rps: 20\ntimeout_seconds: 4\n")
+
+	cfg, err := Load(
+		WithWorkDir(project),
+		WithEnv([]string{
+			"LOCALPULSE_LOAD_TEST_RPS=30",
+			"LOCALPULSE_TIMEOUT_SECONDS=6",
+			"LOCALPULSE_DEFAULT_PORTS=3000, 8080",
+			"LOCALPULSE_UNRELATED=1",
+			"PATH=/usr/bin",
+		}),
+		WithOverride("load_test_rps", "40", "flag --rps"),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    int
+		want   int
+		source string
+	}{
+		{"load_test_rps", cfg.LoadTestRPS, 40, "flag --rps"},
+		{"timeout_seconds", cfg.Timeout, 6, "env LOCALPULSE_TIMEOUT_SECONDS"},
+		{"max_concurrency", cfg.MaxConcurrency, 20, filepath.Join(home, ".localpulse.json")},
+		{"window_seconds", cfg.WindowSeconds, 30, "default"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d, want %d", tt.key, tt.got, tt.want)
+		}
+		if src := cfg.Source(tt.key); src != tt.source {
+			t.Errorf("Source(%s) = %q, want %q", tt.key, src, tt.source)
+		}
+	}
+
+	if len(cfg.DefaultPorts) != 2 || cfg.DefaultPorts[1] != 8080 {
+		t.Errorf("DefaultPorts = %v, want [3000 8080]", cfg.DefaultPorts)
+	}
+}
+
+func TestOverrideErrors(t *testing.T) {
+	setHome(t, t.TempDir())
+
+	tests := []struct {
+		name string
+		opt  LoadOption
+		want string
+	}{
+		{"bad int", WithEnv([]string{"LOCALPULSE_TIMEOUT_SECONDS=soon"}), "env LOCALPULSE_TIMEOUT_SECONDS: timeout_seconds: expected an integer"},
+		{"bad list", WithOverride("default_ports", "3000,web", "flag --ports"), "flag --ports: default_ports: expected a comma-separated list"},
+		{"invalid value", WithOverride("theme", "neon", "flag --theme"), "flag --theme: theme: must be auto, dark or light"},
+		{"out of range", WithOverride("default_ports", "70000", "flag --ports"), "flag --ports: default_ports[0]: port 70000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(WithWorkDir(t.TempDir()), tt.opt)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Load() error = %v, want a ValidationError", err)
+			}
+			if !strings.HasPrefix(verr.Error(), tt.want) {
+				t.Errorf("error = %q, want prefix %q", verr.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestOverridesAreNotSaved(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	userFile := filepath.Join(home, ".localpulse.json")
+	writeFile(t, userFile, "{\n  \"load_test_rps\": 10\n}\n")
+
+	cfg, err := Load(
+		WithWorkDir(t.TempDir()),
+		WithOverride("load_test_rps", "99", "flag --rps"),
+		WithOverride("endpoints", "http://localhost:7000/", "flag --endpoint"),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.AddEndpoint("http://localhost:8080/", "Kept")
+
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(userFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := string(data)
+	if strings.Contains(saved, "99") || strings.Contains(saved, "7000") {
+		t.Errorf("overrides leaked into the user file:\n%s", saved)
+	}
+	if !strings.Contains(saved, "8080") {
+		t.Errorf("endpoint added at runtime was not saved:\n%s", saved)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	setHome(t, t.TempDir())
+
+	cfg, err := Load(WithWorkDir(t.TempDir()), WithOverride("log_sample_rate", "0.25", "flag --log-sample-rate"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	found := false
+	for _, s := range cfg.Settings() {
+		if s.Key == "version" || s.Key == "endpoints" {
+			t.Errorf("Settings() should not include %s", s.Key)
+		}
+		if s.Key == "log_sample_rate" {
+			found = true
+			if s.Value != "0.25" || s.Source != "flag --log-sample-rate" {
+				t.Errorf("log_sample_rate = %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Error("Settings() is missing log_sample_rate")
+	}
+}
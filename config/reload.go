@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"time"
+)
+
+func (c *Config) Reload() (*Config, error) {
+	c.mu.RLock()
+	opts := c.loadOpts
+	c.mu.RUnlock()
+
+	return Load(opts...)
+}
+
+func (c *Config) Apply(next *Config) (added, removed []EndpointConfig) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wasLoaded := make(map[string]bool, len(c.loaded))
+	for _, ep := range c.loaded {
+		wasLoaded[ep.URL] = true
+	}
+	current := make(map[string]bool, len(c.Endpoints))
+	for _, ep := range c.Endpoints {
+		current[ep.URL] = true
+	}
+
+	endpoints := append([]EndpointConfig(nil), next.Endpoints...)
+	inNext := make(map[string]bool, len(endpoints))
+	for _, ep := range endpoints {
+		inNext[ep.URL] = true
+	}
+
+	for url := range wasLoaded {
+		if !current[url] && inNext[url] {
+			endpoints = removeURL(endpoints, url)
+			delete(inNext, url)
+		}
+	}
+	for _, ep := range c.Endpoints {
+		if !wasLoaded[ep.URL] && !inNext[ep.URL] {
+			endpoints = append(endpoints, ep)
+			inNext[ep.URL] = true
+		}
+	}
+
+	for _, ep := range endpoints {
+		if !current[ep.URL] {
+			added = append(added, ep)
+		}
+	}
+	for _, ep := range c.Endpoints {
+		if !inNext[ep.URL] {
+			removed = append(removed, ep)
+		}
+	}
+
+	cv := reflect.ValueOf(c).Elem()
+	nv := reflect.ValueOf(next).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		if key := fieldKey(cv.Type().Field(i)); key != "" && key != "endpoints" {
+			cv.Field(i).Set(nv.Field(i))
+		}
+	}
+	c.Endpoints = endpoints
+
+	c.files = next.files
+	c.sources = next.sources
+	c.epSources = next.epSources
+	c.userLayer = next.userLayer
+	c.projectPath = next.projectPath
+	c.overlayURLs = next.overlayURLs
+	c.userPath = next.userPath
+	c.saveErr = next.saveErr
+	c.saved = next.saved
+	c.loaded = next.loaded
+	c.loadOpts = next.loadOpts
+	c.validators = next.validators
+
+	return added, removed
+}
+
+func removeURL(endpoints []EndpointConfig, url string) []EndpointConfig {
+	out := endpoints[:0]
+	for _, ep := range endpoints {
+		if ep.URL != url {
+			out = append(out, ep)
+		}
+	}
+	return out
+}
+
+func (c *Config) WatchPaths() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var paths []string
+	if c.userPath != "" {
+		paths = append(paths, c.userPath)
+	}
+	if c.projectPath != "" {
+		paths = append(paths, c.projectPath)
+	}
+	if path := c.scenarioPath(); path != "" {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+type Watcher struct {
+	stamps map[string]fileStamp
+}
+
+func NewWatcher(paths []string) *Watcher {
+	w := &Watcher{}
+	w.Reset(paths)
+	return w
+}
+
+func (w *Watcher) Reset(paths []string) {
+	w.stamps = make(map[string]fileStamp, len(paths))
+	for _, path := range paths {
+		w.stamps[path] = stampFile(path)
+	}
+}
+
+func (w *Watcher) Changed() bool {
+	changed := false
+	for path, old := range w.stamps {
+		current := stampFile(path)
+		if current != old {
+			w.stamps[path] = current
+			changed = true
+		}
+	}
+	return changed
+}
+
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
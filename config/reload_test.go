@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReloadApply(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	project := t.TempDir()
+
+	projectFile := filepath.Join(project, "localpulse.yaml")
+	writeFile(t, projectFile, `load_test_rps: 20
+endpoints:
+  - url: http://localhost:3000/
+    name: API
+  - url: http://localhost:4000/
+    name: Web
+`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.AddEndpoint("http://localhost:9000/", "Runtime")
+	cfg.RemoveEndpoint("http://localhost:4000/")
+
+	writeFile(t, projectFile, `load_test_rps: 50
+endpoints:
+  - url: http://localhost:4000/
+    name: Web
+  - url: http://localhost:5000/
+    name: Worker
+`)
+
+	next, err := cfg.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	added, removed := cfg.Apply(next)
+
+	if cfg.LoadTestRPS != 50 {
+		t.Errorf("LoadTestRPS = %d, want 50", cfg.LoadTestRPS)
+	}
+	if len(added) != 1 || added[0].URL != "http://localhost:5000/" {
+		t.Errorf("added = %+v, want only the worker endpoint", added)
+	}
+	if len(removed) != 1 || removed[0].URL != "http://localhost:3000/" {
+		t.Errorf("removed = %+v, want only the API endpoint", removed)
+	}
+
+	urls := make(map[string]bool)
+	for _, ep := range cfg.GetEndpoints() {
+		urls[ep.URL] = true
+	}
+	for _, want := range []string{"http://localhost:5000/", "http://localhost:9000/"} {
+		if !urls[want] {
+			t.Errorf("endpoint %s missing after reload", want)
+		}
+	}
+	if urls["http://localhost:4000/"] {
+		t.Error("endpoint removed at runtime came back after reload")
+	}
+}
+
+func TestReloadInvalidKeepsConfig(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+	project := t.TempDir()
+
+	projectFile := filepath.Join(project, "localpulse.json")
+	writeFile(t, projectFile, `{"load_test_rps": 20}`)
+
+	cfg, err := Load(WithWorkDir(project))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	writeFile(t, projectFile, "{\n  \"load_test_rps\": -5\n}")
+
+	_, err = cfg.Reload()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Reload() error = %v, want a ValidationError", err)
+	}
+	if verr.Errors[0].Line != 2 {
+		t.Errorf("error line = %d, want 2", verr.Errors[0].Line)
+	}
+	if cfg.LoadTestRPS != 20 {
+		t.Errorf("LoadTestRPS = %d, want 20 to be kept", cfg.LoadTestRPS)
+	}
+}
+
+func TestWatcherChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "localpulse.json")
+	writeFile(t, path, `{}`)
+
+	w := NewWatcher([]string{path})
+	if w.Changed() {
+		t.Error("Changed() = true before any edit")
+	}
+
+	writeFile(t, path, `{"load_test_rps": 5}`)
+	later := time.Now().Add(2 * time.Second)
+	os.Chtimes(path, later, later)
+
+	if !w.Changed() {
+		t.Error("Changed() = false after edit")
+	}
+	if w.Changed() {
+		t.Error("Changed() = true twice for one edit")
+	}
+
+	os.Remove(path)
+	if !w.Changed() {
+		t.Error("Changed() = false after removal")
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+func (c *Config) Save() error {
+	if c.saveErr != nil {
+		return c.saveErr
+	}
+
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	path := c.userPath
+	if path == "" {
+		var err error
+		path, err = configPath()
+		if err != nil {
+			return err
+		}
+	}
+
+	c.mu.RLock()
+	view := c.userView()
+	c.mu.RUnlock()
+
+	baseline := c.saved
+	if baseline == nil {
+		baseline = DefaultConfig()
+	}
+
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		data, err = marshal(FormatForPath(path), view)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if _, err := parseDocument(FormatForPath(path), data); err != nil {
+			return fmt.Errorf("config not saved because %s could not be parsed: %w", path, err)
+		}
+		data, err = patchDocument(FormatForPath(path), data, baseline, view)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			c.saved = view
+			return nil
+		}
+	}
+
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	c.saved = view
+	return nil
+}
+
+func patchDocument(format Format, data []byte, baseline, view *Config) ([]byte, error) {
+	ed, err := newEditor(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	bv := reflect.ValueOf(baseline).Elem()
+	vv := reflect.ValueOf(view).Elem()
+	t := vv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldKey(t.Field(i))
+		if key == "" || key == "version" || key == "endpoints" {
+			continue
+		}
+		if reflect.DeepEqual(bv.Field(i).Interface(), vv.Field(i).Interface()) {
+			continue
+		}
+		if err := ed.setKey(key, vv.Field(i).Interface()); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+
+	before := make(map[string]EndpointConfig, len(baseline.Endpoints))
+	for _, ep := range baseline.Endpoints {
+		before[ep.URL] = ep
+	}
+	after := make(map[string]bool, len(view.Endpoints))
+	for _, ep := range view.Endpoints {
+		after[ep.URL] = true
+		if old, ok := before[ep.URL]; ok && reflect.DeepEqual(old, ep) {
+			continue
+		}
+		if err := ed.upsertEndpoint(ep); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+	for _, ep := range baseline.Endpoints {
+		if !after[ep.URL] {
+			if err := ed.removeEndpoint(ep.URL); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return ed.bytes()
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmpName)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		cleanup()
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		cleanup()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+func (c *Config) userView() *Config {
+	out := DefaultConfig()
+	dv := reflect.ValueOf(out).Elem()
+	cv := reflect.ValueOf(c).Elem()
+
+	for i := 0; i < dv.NumField(); i++ {
+		key := fieldKey(dv.Type().Field(i))
+		if key == "" || key == "endpoints" {
+			continue
+		}
+		if src, ok := c.sources[key]; !ok || src == c.userPath {
+			dv.Field(i).Set(cv.Field(i))
+			continue
+		}
+		if c.userLayer != nil {
+			if _, ok := c.userLayer.present[key]; ok {
+				dv.Field(i).Set(reflect.ValueOf(&c.userLayer.config).Elem().Field(i))
+			}
+		}
+	}
+
+	for _, ep := range c.Endpoints {
+		if !c.overlayURLs[ep.URL] {
+			out.Endpoints = append(out.Endpoints, ep)
+		}
+	}
+	out.Version = CurrentVersion
+	return out
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func loadFromHome(t *testing.T, home, name, content string) (*Config, string) {
+	t.Helper()
+	setHome(t, home)
+	path := filepath.Join(home, name)
+	writeFile(t, path, content)
+
+	cfg, err := Load(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg, path
+}
+
+func readString(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSave_PreservesYAMLComments(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.yaml", `# my settings
+load_test_rps: 20 # keep it gentle
+endpoints:
+  # the main API
+  - url: http://localhost:3000/health
+    name: API
+`)
+
+	cfg.AddEndpoint("http://localhost:8080/", "Admin")
+	cfg.RemoveEndpoint("http://localhost:3000/health")
+	cfg.AddEndpoint("http://localhost:3000/health", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got := readString(t, path)
+	for _, want := range []string{"# my settings", "# keep it gentle", "# the main API", "http://localhost:8080/"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saved file missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "window_seconds") {
+		t.Errorf("unchanged fields should not be written:\n%s", got)
+	}
+}
+
+func TestSave_PreservesTOMLComments(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.toml", `# personal localpulse config
+load_test_rps = 20 # gentle
+
+# the main API
+[[endpoints]]
+url = "http://localhost:3000/health"
+name = "API"
+
+# legacy service, going away
+[[endpoints]]
+url = "http://localhost:4000/"
+`)
+
+	cfg.RemoveEndpoint("http://localhost:4000/")
+	cfg.AddEndpoint("http://localhost:8080/", "Admin")
+	cfg.mu.Lock()
+	cfg.Timeout = 9
+	cfg.mu.Unlock()
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got := readString(t, path)
+	for _, want := range []string{"# personal localpulse config", "# gentle", "# the main API", "timeout_seconds = 9", `url = 'http://localhost:8080/'`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saved file missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "localhost:4000") {
+		t.Errorf("removed endpoint still present:\n%s", got)
+	}
+	if err := ValidateFile(path); err != nil {
+		t.Errorf("saved TOML is invalid: %v\n%s", err, got)
+	}
+	if strings.Index(got, "timeout_seconds") > strings.Index(got, "[[endpoints]]") {
+		t.Errorf("top-level key written inside a table:\n%s", got)
+	}
+}
+
+func TestSave_PatchesTOMLEndpointTable(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.toml", `[[endpoints]]
+# staging only
+url = "http://localhost:3000/health"
+name = "API" # shown in the list
+rps = 5
+# auth header for the gateway
+headers = { Authorization = "Bearer x" }
+`)
+
+	cfg.mu.Lock()
+	ep := cfg.Endpoints[0]
+	ep.Name = "Gateway"
+	ep.RPS = 0
+	ep.Timeout = 3
+	ep.Headers = map[string]string{"Authorization": "Bearer x", "X-Env": "dev"}
+	cfg.Endpoints[0] = ep
+	cfg.mu.Unlock()
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got := readString(t, path)
+	want := `[[endpoints]]
+# staging only
+url = "http://localhost:3000/health"
+name = 'Gateway' # shown in the list
+# auth header for the gateway
+headers = {Authorization = 'Bearer x', X-Env = 'dev'}
+timeout_seconds = 3
+`
+	if got != want {
+		t.Errorf("saved file =\n%s\nwant\n%s", got, want)
+	}
+	reloaded, err := Load(WithConfigFile(path))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if e := reloaded.Endpoints[0]; e.Name != "Gateway" || e.Timeout != 3 || e.RPS != 0 || e.Headers["X-Env"] != "dev" {
+		t.Errorf("reloaded endpoint = %+v", e)
+	}
+}
+
+func TestSave_TOMLRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edit    func(c *Config)
+		want    string
+	}{
+		{
+			name: "inline tables",
+			content: `endpoints = [{ url = "http://localhost:3000/", name = "API", headers = { X-Env = "dev" } }] # both services
+
+[[webhooks]]
+url = "http://localhost:9000/events"
+headers = { Authorization = "Bearer token", "Content-Type" = "text/plain" }
+`,
+			edit: func(c *Config) {
+				c.AddEndpoint("http://localhost:8080/", "Admin")
+				c.Timeout = 9
+			},
+			want: `endpoints = [{ url = 'http://localhost:3000/', name = 'API', headers = {X-Env = 'dev'} }, { url = 'http://localhost:8080/', name = 'Admin' }] # both services
+timeout_seconds = 9
+
+[[webhooks]]
+url = "http://localhost:9000/events"
+headers = { Authorization = "Bearer token", "Content-Type" = "text/plain" }
+`,
+		},
+		{
+			name: "arrays of tables",
+			content: `[[scenarios]]
+name = "checkout"
+
+[[scenarios.steps]]
+url = "http://localhost:3000/login"
+
+[[scenarios.steps]]
+url = "http://localhost:3000/cart"
+
+[[endpoints]]
+url = "http://localhost:3000/health"
+
+[[alerts]]
+when = "down"
+actions = ["bell"]
+
+[[endpoints]]
+url = "http://localhost:4000/"
+`,
+			edit: func(c *Config) {
+				c.RemoveEndpoint("http://localhost:4000/")
+				c.Endpoints[0].Name = "API"
+				c.WindowSeconds = 60
+			},
+			want: `window_seconds = 60
+[[scenarios]]
+name = "checkout"
+
+[[scenarios.steps]]
+url = "http://localhost:3000/login"
+
+[[scenarios.steps]]
+url = "http://localhost:3000/cart"
+
+[[endpoints]]
+url = "http://localhost:3000/health"
+name = 'API'
+
+[[alerts]]
+when = "down"
+actions = ["bell"]
+`,
+		},
+		{
+			name: "multi-line strings",
+			content: `[[endpoints]]
+url = "http://localhost:3000/orders"
+method = "POST"
+body = """
+[items]
+name = "widget" # not a comment
+tags = ["a", "b"
+"""
+name = "Orders" # checkout service
+
+[[webhooks]]
+url = "http://localhost:9000/events"
+format = "custom"
+template = '''
+[[endpoints]]
+{{.Endpoint}} is {{.NewStatus}}
+'''
+`,
+			edit: func(c *Config) {
+				c.Endpoints[0].Name = "Cart"
+				c.Endpoints[0].Timeout = 3
+			},
+			want: `[[endpoints]]
+url = "http://localhost:3000/orders"
+method = "POST"
+body = """
+[items]
+name = "widget" # not a comment
+tags = ["a", "b"
+"""
+name = 'Cart' # checkout service
+timeout_seconds = 3
+
+[[webhooks]]
+url = "http://localhost:9000/events"
+format = "custom"
+template = '''
+[[endpoints]]
+{{.Endpoint}} is {{.NewStatus}}
+'''
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.toml", tt.content)
+
+			tt.edit(cfg)
+			if err := cfg.Save(); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			if got := readString(t, path); got != tt.want {
+				t.Errorf("saved file =\n%s\nwant\n%s", got, tt.want)
+			}
+			reloaded, err := Load(WithWorkDir(t.TempDir()))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got, want := reloaded.userView(), cfg.userView(); !reflect.DeepEqual(got, want) {
+				t.Errorf("reloaded config = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSave_KeepsJSONKeyOrder(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.json", `{
+  "window_seconds": 60,
+  "load_test_rps": 20,
+  "endpoints": []
+}`)
+
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got := readString(t, path)
+	if strings.Index(got, "window_seconds") > strings.Index(got, "load_test_rps") {
+		t.Errorf("key order changed:\n%s", got)
+	}
+	if strings.Contains(got, "max_concurrency") {
+		t.Errorf("unchanged fields should not be written:\n%s", got)
+	}
+	if err := ValidateFile(path); err != nil {
+		t.Errorf("saved JSON is invalid: %v\n%s", err, got)
+	}
+}
+
+func TestSave_OnlyWritesChangedFields(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.yaml", "load_test_rps: 20\n")
+
+	writeFile(t, path, "load_test_rps: 20\ntimeout_seconds: 7\n")
+
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got := readString(t, path)
+	if !strings.Contains(got, "timeout_seconds: 7") {
+		t.Errorf("edit made by another process was lost:\n%s", got)
+	}
+}
+
+func TestSave_NoTempFilesLeft(t *testing.T) {
+	home := t.TempDir()
+	cfg, _ := loadFromHome(t, home, ".localpulse.json", `{"load_test_rps": 20}`)
+
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temporary file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestSave_KeepsFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	home := t.TempDir()
+	cfg, path := loadFromHome(t, home, ".localpulse.json", `{"load_test_rps": 20}`)
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode after save = %v, want the existing 0644 kept", info.Mode().Perm())
+	}
+
+	fresh := DefaultConfig()
+	fresh.userPath = filepath.Join(home, "new.json")
+	if err := fresh.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if info, err = os.Stat(fresh.userPath); err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("new file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestSave_RefusesUnparseableFile(t *testing.T) {
+	cfg, path := loadFromHome(t, t.TempDir(), ".localpulse.json", `{"load_test_rps": 20}`)
+
+	writeFile(t, path, `{"load_test_rps": `)
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err == nil {
+		t.Error("Save() should refuse to overwrite a file it cannot parse")
+	}
+	if got := readString(t, path); got != `{"load_test_rps": ` {
+		t.Errorf("file was modified: %q", got)
+	}
+}
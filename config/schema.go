@@ -0,0 +1,571 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const CurrentVersion = 1
+
+type FieldError struct {
+	File    string
+	Line    int
+	Field   string
+	Message string
+}
+
+type Validator func(c *Config) []FieldError
+
+func (e FieldError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			b.WriteString(":" + strconv.Itoa(e.Line))
+		}
+		b.WriteString(": ")
+	}
+	if e.Field != "" {
+		b.WriteString(e.Field + ": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		lines[i] = fe.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+type migration func(raw map[string]any) error
+
+var migrations = []migration{
+	func(raw map[string]any) error {
+		return nil
+	},
+}
+
+func migrate(raw map[string]any) *FieldError {
+	version := 0
+	if v, ok := raw["version"]; ok {
+		n, ok := toInt(v)
+		if !ok || n < 0 {
+			return &FieldError{Field: "version", Message: "must be a non-negative integer"}
+		}
+		version = n
+	}
+
+	if version > CurrentVersion {
+		return &FieldError{
+			Field:   "version",
+			Message: fmt.Sprintf("schema version %d is newer than this LocalPulse supports (%d)", version, CurrentVersion),
+		}
+	}
+
+	for ; version < CurrentVersion; version++ {
+		if err := migrations[version](raw); err != nil {
+			return &FieldError{Field: "version", Message: fmt.Sprintf("migrating from version %d: %v", version, err)}
+		}
+	}
+	raw["version"] = CurrentVersion
+	return nil
+}
+
+func checkTypes(path string, value any, t reflect.Type) []FieldError {
+	switch t.Kind() {
+	case reflect.Int:
+		if _, ok := toInt(value); !ok {
+			return []FieldError{{Field: path, Message: "expected an integer, got " + describe(value)}}
+		}
+	case reflect.Float64:
+		f, ok := toFloat(value)
+		if !ok {
+			return []FieldError{{Field: path, Message: "expected a number, got " + describe(value)}}
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return []FieldError{{Field: path, Message: "must be a finite number"}}
+		}
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return []FieldError{{Field: path, Message: "expected a string, got " + describe(value)}}
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return []FieldError{{Field: path, Message: "expected true or false, got " + describe(value)}}
+		}
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			return []FieldError{{Field: path, Message: "expected a list, got " + describe(value)}}
+		}
+		var errs []FieldError
+		for i, item := range items {
+			errs = append(errs, checkTypes(path+"["+strconv.Itoa(i)+"]", item, t.Elem())...)
+		}
+		return errs
+	case reflect.Struct:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return []FieldError{{Field: path, Message: "expected an object, got " + describe(value)}}
+		}
+		fields := make(map[string]reflect.StructField)
+		for i := 0; i < t.NumField(); i++ {
+			if key := fieldKey(t.Field(i)); key != "" {
+				fields[key] = t.Field(i)
+			}
+		}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var errs []FieldError
+		for _, key := range keys {
+			child := joinPath(path, key)
+			field, ok := fields[key]
+			if !ok {
+				errs = append(errs, FieldError{Field: child, Message: "unknown field"})
+				continue
+			}
+			errs = append(errs, checkTypes(child, obj[key], field.Type)...)
+		}
+		return errs
+	}
+	return nil
+}
+
+func toInt(v any) (int, bool) {
+	f, ok := toFloat(v)
+	if !ok || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int(f), true
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func describe(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "an object"
+	}
+	if _, ok := toFloat(v); ok {
+		return "a number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func (c *Config) Validate() []FieldError {
+	var errs []FieldError
+
+	for i, ep := range c.Endpoints {
+		path := "endpoints[" + strconv.Itoa(i) + "]"
+		if strings.TrimSpace(ep.URL) == "" {
+			errs = append(errs, FieldError{Field: path + ".url", Message: "is required"})
+			continue
+		}
+		errs = append(errs, validatePolicy(path+".", ep.SlowThresholdMs, ep.DownThresholdMs, ep.Timeout)...)
+		errs = append(errs, validateRate(path+".rps", ep.RPS)...)
+		errs = append(errs, validateRate(path+".weight", ep.Weight)...)
+		if !validMethod(ep.Method) {
+			errs = append(errs, FieldError{Field: path + ".method", Message: "unsupported method " + strconv.Quote(ep.Method)})
+		}
+		errs = append(errs, validateTLS(path+".", ep.TLSCertFile, ep.TLSKeyFile)...)
+		if ep.Connections < 0 {
+			errs = append(errs, FieldError{Field: path + ".connections", Message: "must not be negative"})
+		}
+		websocket := strings.HasPrefix(ep.URL, "ws://") || strings.HasPrefix(ep.URL, "wss://")
+		if !websocket && len(ep.Messages) > 0 {
+			errs = append(errs, FieldError{Field: path + ".messages", Message: "only applies to ws:// and wss:// URLs"})
+		}
+		if !websocket && ep.Match != "" {
+			errs = append(errs, FieldError{Field: path + ".match", Message: "only applies to ws:// and wss:// URLs"})
+		}
+		if strings.HasPrefix(ep.URL, "unix://") {
+			continue
+		}
+		raw := ep.URL
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: path + ".url", Message: "invalid URL: " + err.Error()})
+			continue
+		}
+		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss" {
+			errs = append(errs, FieldError{Field: path + ".url", Message: "unsupported scheme " + strconv.Quote(u.Scheme)})
+		} else if u.Host == "" {
+			errs = append(errs, FieldError{Field: path + ".url", Message: "missing host"})
+		}
+	}
+
+	for i, port := range c.DefaultPorts {
+		if port < 1 || port > 65535 {
+			errs = append(errs, FieldError{
+				Field:   "default_ports[" + strconv.Itoa(i) + "]",
+				Message: fmt.Sprintf("port %d is out of range 1-65535", port),
+			})
+		}
+	}
+
+	nonNegative := []struct {
+		field string
+		value int
+	}{
+		{"check_interval_seconds", c.CheckInterval},
+		{"load_test_rps", c.LoadTestRPS},
+		{"timeout_seconds", c.Timeout},
+		{"max_concurrency", c.MaxConcurrency},
+		{"window_seconds", c.WindowSeconds},
+		{"flap_threshold", c.FlapThreshold},
+		{"flap_window_seconds", c.FlapWindow},
+		{"cert_warn_days", c.CertWarnDays},
+	}
+	for _, f := range nonNegative {
+		if f.value < 0 {
+			errs = append(errs, FieldError{Field: f.field, Message: "must not be negative"})
+		}
+	}
+
+	errs = append(errs, validatePolicy("", c.SlowThreshold, c.DownThreshold, 0)...)
+	errs = append(errs, validateTLS("", c.TLSCertFile, c.TLSKeyFile)...)
+
+	if c.LoadTestRPS > maxRate {
+		errs = append(errs, FieldError{Field: "load_test_rps", Message: "must be at most " + strconv.Itoa(maxRate)})
+	}
+
+	if c.FlapWindow > 86400 {
+		errs = append(errs, FieldError{Field: "flap_window_seconds", Message: "must be at most 86400 (24h)"})
+	}
+
+	if c.LogSampleRate < 0 || c.LogSampleRate > 1 {
+		errs = append(errs, FieldError{Field: "log_sample_rate", Message: "must be between 0 and 1"})
+	}
+
+	if strings.ContainsAny(c.Host, "/ \t") {
+		errs = append(errs, FieldError{Field: "host", Message: "must be a bare host name or IP address"})
+	}
+
+	switch c.Theme {
+	case "", "auto", "dark", "light":
+	default:
+		errs = append(errs, FieldError{Field: "theme", Message: "must be auto, dark or light, got " + strconv.Quote(c.Theme)})
+	}
+
+	for i, alert := range c.Alerts {
+		errs = append(errs, alert.validate("alerts["+strconv.Itoa(i)+"]")...)
+	}
+	for i, hook := range c.Webhooks {
+		errs = append(errs, hook.validate("webhooks["+strconv.Itoa(i)+"]")...)
+	}
+	seen := make(map[string]bool, len(c.Scenarios))
+	for i, sc := range c.Scenarios {
+		path := "scenarios[" + strconv.Itoa(i) + "]"
+		if seen[sc.Name] {
+			errs = append(errs, FieldError{Field: path + ".name", Message: "duplicate scenario " + strconv.Quote(sc.Name)})
+		}
+		seen[sc.Name] = true
+		errs = append(errs, sc.validate(path)...)
+	}
+	profiles := make(map[string]bool, len(c.AuthProfiles))
+	for i, a := range c.AuthProfiles {
+		path := "auth[" + strconv.Itoa(i) + "]"
+		if profiles[a.Name] {
+			errs = append(errs, FieldError{Field: path + ".name", Message: "duplicate auth profile " + strconv.Quote(a.Name)})
+		}
+		profiles[a.Name] = true
+		errs = append(errs, a.validate(path)...)
+	}
+	feeders := make(map[string]bool, len(c.Feeders))
+	for i, feeder := range c.Feeders {
+		path := "feeders[" + strconv.Itoa(i) + "]"
+		if feeders[feeder.Name] {
+			errs = append(errs, FieldError{Field: path + ".name", Message: "duplicate feeder " + strconv.Quote(feeder.Name)})
+		}
+		feeders[feeder.Name] = true
+		errs = append(errs, feeder.validate(path)...)
+	}
+
+	return errs
+}
+
+func (c *Config) validate(validators []Validator) []FieldError {
+	errs := c.Validate()
+	for _, v := range validators {
+		errs = append(errs, v(c)...)
+	}
+	return errs
+}
+
+func validatePolicy(prefix string, slowMs, downMs, timeout int) []FieldError {
+	var errs []FieldError
+
+	if slowMs < 0 {
+		errs = append(errs, FieldError{Field: prefix + "slow_threshold_ms", Message: "must not be negative"})
+	}
+	if downMs < 0 {
+		errs = append(errs, FieldError{Field: prefix + "down_threshold_ms", Message: "must not be negative"})
+	} else if downMs > 0 && slowMs > 0 && downMs <= slowMs {
+		errs = append(errs, FieldError{Field: prefix + "down_threshold_ms", Message: "must be above slow_threshold_ms"})
+	}
+	if timeout < 0 {
+		errs = append(errs, FieldError{Field: prefix + "timeout_seconds", Message: "must not be negative"})
+	}
+	return errs
+}
+
+const maxRate = 1000000
+
+func validateRate(field string, v float64) []FieldError {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return []FieldError{{Field: field, Message: "must be a finite number"}}
+	case v < 0:
+		return []FieldError{{Field: field, Message: "must not be negative"}}
+	case v > maxRate:
+		return []FieldError{{Field: field, Message: "must be at most " + strconv.Itoa(maxRate)}}
+	}
+	return nil
+}
+
+func validateTLS(prefix, certFile, keyFile string) []FieldError {
+	var errs []FieldError
+
+	switch {
+	case certFile != "" && keyFile == "":
+		errs = append(errs, FieldError{Field: prefix + "tls_key_file", Message: "is required with tls_cert_file"})
+	case keyFile != "" && certFile == "":
+		errs = append(errs, FieldError{Field: prefix + "tls_cert_file", Message: "is required with tls_key_file"})
+	}
+	return errs
+}
+
+func (a AlertConfig) validate(path string) []FieldError {
+	var errs []FieldError
+
+	switch a.When {
+	case "down", "slow":
+	case "error_rate":
+		if a.Threshold <= 0 || a.Threshold > 100 {
+			errs = append(errs, FieldError{Field: path + ".threshold", Message: "must be a percentage above 0 and at most 100"})
+		}
+	case "":
+		errs = append(errs, FieldError{Field: path + ".when", Message: "is required"})
+	default:
+		errs = append(errs, FieldError{Field: path + ".when", Message: "must be down, slow or error_rate, got " + strconv.Quote(a.When)})
+	}
+
+	if a.For < 0 {
+		errs = append(errs, FieldError{Field: path + ".for", Message: "must not be negative"})
+	}
+	if a.CooldownSeconds < 0 {
+		errs = append(errs, FieldError{Field: path + ".cooldown_seconds", Message: "must not be negative"})
+	}
+
+	if len(a.Actions) == 0 {
+		errs = append(errs, FieldError{Field: path + ".actions", Message: "needs at least one of bell, notify or command"})
+	}
+	for i, action := range a.Actions {
+		switch action {
+		case "bell", "notify":
+		case "command":
+			if strings.TrimSpace(a.Command) == "" {
+				errs = append(errs, FieldError{Field: path + ".command", Message: "is required for the command action"})
+			}
+		default:
+			errs = append(errs, FieldError{
+				Field:   path + ".actions[" + strconv.Itoa(i) + "]",
+				Message: "must be bell, notify or command, got " + strconv.Quote(action),
+			})
+		}
+	}
+	return errs
+}
+
+func (w WebhookConfig) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if strings.TrimSpace(w.URL) == "" {
+		errs = append(errs, FieldError{Field: path + ".url", Message: "is required"})
+	} else if u, err := url.Parse(w.URL); err != nil {
+		errs = append(errs, FieldError{Field: path + ".url", Message: "invalid URL: " + err.Error()})
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		errs = append(errs, FieldError{Field: path + ".url", Message: "must be an http or https URL"})
+	}
+
+	switch w.Format {
+	case "", "json", "slack":
+	case "custom":
+		if strings.TrimSpace(w.Template) == "" {
+			errs = append(errs, FieldError{Field: path + ".template", Message: "is required for the custom format"})
+		}
+	default:
+		errs = append(errs, FieldError{Field: path + ".format", Message: "must be json, slack or custom, got " + strconv.Quote(w.Format)})
+	}
+
+	for i, event := range w.Events {
+		if event != "status" && event != "alert" {
+			errs = append(errs, FieldError{
+				Field:   path + ".events[" + strconv.Itoa(i) + "]",
+				Message: "must be status or alert, got " + strconv.Quote(event),
+			})
+		}
+	}
+	if w.Retries < 0 {
+		errs = append(errs, FieldError{Field: path + ".retries", Message: "must not be negative"})
+	}
+	return errs
+}
+
+func (a AuthConfig) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if strings.TrimSpace(a.Name) == "" {
+		errs = append(errs, FieldError{Field: path + ".name", Message: "is required"})
+	}
+	envVar := func(field, value string) {
+		if value == "" {
+			errs = append(errs, FieldError{Field: path + "." + field, Message: "is required for " + a.Type + " auth"})
+		} else if !varName.MatchString(value) {
+			errs = append(errs, FieldError{Field: path + "." + field, Message: "must name an environment variable, got " + strconv.Quote(value)})
+		}
+	}
+
+	switch a.Type {
+	case "":
+		if !a.Cookies {
+			errs = append(errs, FieldError{Field: path + ".type", Message: "is required unless cookies is set"})
+		}
+	case "bearer":
+		envVar("token_env", a.TokenEnv)
+	case "basic":
+		if a.Username == "" {
+			errs = append(errs, FieldError{Field: path + ".username", Message: "is required for basic auth"})
+		}
+		envVar("password_env", a.PasswordEnv)
+	case "oauth2":
+		if u, err := url.Parse(a.TokenURL); a.TokenURL == "" {
+			errs = append(errs, FieldError{Field: path + ".token_url", Message: "is required for oauth2 auth"})
+		} else if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{Field: path + ".token_url", Message: "must be an http or https URL"})
+		}
+		if a.ClientID == "" {
+			errs = append(errs, FieldError{Field: path + ".client_id", Message: "is required for oauth2 auth"})
+		}
+		envVar("client_secret_env", a.ClientSecretEnv)
+	default:
+		errs = append(errs, FieldError{Field: path + ".type", Message: "must be bearer, basic or oauth2, got " + strconv.Quote(a.Type)})
+	}
+	return errs
+}
+
+func (f FeederConfig) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if strings.TrimSpace(f.Name) == "" {
+		errs = append(errs, FieldError{Field: path + ".name", Message: "is required"})
+	}
+	switch ext := strings.ToLower(filepath.Ext(f.File)); {
+	case strings.TrimSpace(f.File) == "":
+		errs = append(errs, FieldError{Field: path + ".file", Message: "is required"})
+	case ext != ".csv" && ext != ".json":
+		errs = append(errs, FieldError{Field: path + ".file", Message: "must be a .csv or .json file"})
+	}
+	switch f.Mode {
+	case "", "sequential", "random", "unique":
+	default:
+		errs = append(errs, FieldError{Field: path + ".mode", Message: "must be sequential, random or unique, got " + strconv.Quote(f.Mode)})
+	}
+	return errs
+}
+
+func validMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case "", "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+var varName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (s ScenarioConfig) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if strings.TrimSpace(s.Name) == "" {
+		errs = append(errs, FieldError{Field: path + ".name", Message: "is required"})
+	}
+	if len(s.Steps) == 0 {
+		errs = append(errs, FieldError{Field: path + ".steps", Message: "needs at least one step"})
+	}
+
+	for i, step := range s.Steps {
+		stepPath := path + ".steps[" + strconv.Itoa(i) + "]"
+		if !validMethod(step.Method) {
+			errs = append(errs, FieldError{Field: stepPath + ".method", Message: "unsupported method " + strconv.Quote(step.Method)})
+		}
+
+		if strings.TrimSpace(step.URL) == "" {
+			errs = append(errs, FieldError{Field: stepPath + ".url", Message: "is required"})
+		} else if !strings.HasPrefix(step.URL, "${") && !strings.HasPrefix(step.URL, "http://") && !strings.HasPrefix(step.URL, "https://") && !strings.HasPrefix(step.URL, "unix://") {
+			errs = append(errs, FieldError{Field: stepPath + ".url", Message: "must start with http://, https://, unix:// or a ${variable}"})
+		}
+
+		for _, name := range sortedKeys(step.Extract) {
+			if !varName.MatchString(name) {
+				errs = append(errs, FieldError{Field: stepPath + ".extract." + name, Message: "is not a valid variable name"})
+			}
+		}
+	}
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validationErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("error = %v (%T), want *ValidationError", err, err)
+	}
+	return verr.Errors
+}
+
+func TestValidateFile_Formats(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		wantLine  int
+		wantField string
+	}{
+		{
+			name: "json bad port",
+			file: "localpulse.json",
+			content: `{
+  "default_ports": [
+    3000,
+    70000
+  ]
+}`,
+			wantLine:  4,
+			wantField: "default_ports[1]",
+		},
+		{
+			name: "yaml unknown field",
+			file: "localpulse.yaml",
+			content: `endpoints:
+  - url: http://localhost:3000
+load_tset_rps: 10
+`,
+			wantLine:  3,
+			wantField: "load_tset_rps",
+		},
+		{
+			name: "toml wrong type",
+			file: "localpulse.toml",
+			content: `load_test_rps = 10
+
+[[endpoints]]
+url = "http://localhost:3000"
+
+[[endpoints]]
+url = 8080
+`,
+			wantLine:  7,
+			wantField: "endpoints[1].url",
+		},
+		{
+			name:      "yaml negative value",
+			file:      ".localpulse.yml",
+			content:   "timeout_seconds: 5\nmax_concurrency: -1\n",
+			wantLine:  2,
+			wantField: "max_concurrency",
+		},
+		{
+			name:      "json unsupported scheme",
+			file:      "localpulse.json",
+			content:   "{\n  \"endpoints\": [\n    {\"url\": \"ftp://localhost:21\"}\n  ]\n}",
+			wantLine:  3,
+			wantField: "endpoints[0].url",
+		},
+		{
+			name: "yaml unknown alert action",
+			file: "localpulse.yaml",
+			content: `alerts:
+  - when: down
+    for: 3
+    actions: [bell, email]
+`,
+			wantLine:  4,
+			wantField: "alerts[0].actions[1]",
+		},
+		{
+			name:      "json alert command missing",
+			file:      "localpulse.json",
+			content:   "{\n  \"alerts\": [\n    {\"when\": \"slow\", \"actions\": [\"command\"]}\n  ]\n}",
+			wantLine:  3,
+			wantField: "alerts[0].command",
+		},
+		{
+			name: "yaml webhook format",
+			file: "localpulse.yaml",
+			content: `webhooks:
+  - url: http://localhost:9999/hook
+    format: teams
+`,
+			wantLine:  3,
+			wantField: "webhooks[0].format",
+		},
+		{
+			name:      "json down below slow threshold",
+			file:      "localpulse.json",
+			content:   "{\n  \"slow_threshold_ms\": 500,\n  \"down_threshold_ms\": 300\n}",
+			wantLine:  3,
+			wantField: "down_threshold_ms",
+		},
+		{
+			name:      "toml negative endpoint weight",
+			file:      "localpulse.toml",
+			content:   "[[endpoints]]\nurl = \"http://localhost:3000/search\"\nrps = 20\nweight = -5\n",
+			wantLine:  4,
+			wantField: "endpoints[0].weight",
+		},
+		{
+			name:      "toml infinite endpoint rps",
+			file:      "localpulse.toml",
+			content:   "[[endpoints]]\nurl = \"http://localhost:3000/search\"\nrps = inf\n",
+			wantLine:  3,
+			wantField: "endpoints[0].rps",
+		},
+		{
+			name:      "yaml absurd endpoint weight",
+			file:      "localpulse.yaml",
+			content:   "endpoints:\n  - url: http://localhost:3000/search\n    weight: 1e12\n",
+			wantLine:  3,
+			wantField: "endpoints[0].weight",
+		},
+		{
+			name: "yaml auth secret without env var",
+			file: "localpulse.yaml",
+			content: `auth:
+  - name: api
+    type: oauth2
+    token_url: http://localhost:9000/token
+    client_id: localpulse
+    client_secret_env: "not a var"
+`,
+			wantLine:  6,
+			wantField: "auth[0].client_secret_env",
+		},
+		{
+			name:      "toml messages on http endpoint",
+			file:      "localpulse.toml",
+			content:   "[[endpoints]]\nurl = \"http://localhost:3000\"\nmessages = [\"ping\"]\n",
+			wantLine:  3,
+			wantField: "endpoints[0].messages",
+		},
+		{
+			name:      "toml client cert without key",
+			file:      "localpulse.toml",
+			content:   "[[endpoints]]\nurl = \"https://localhost:8443\"\ntls_cert_file = \"client.pem\"\n",
+			wantLine:  1,
+			wantField: "endpoints[0].tls_key_file",
+		},
+		{
+			name: "yaml unknown feeder mode",
+			file: "localpulse.yaml",
+			content: `feeders:
+  - name: users
+    file: users.csv
+    mode: shuffled
+`,
+			wantLine:  4,
+			wantField: "feeders[0].mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+
+			errs := validationErrors(t, ValidateFile(path))
+			if len(errs) != 1 {
+				t.Fatalf("got %d errors (%v), want 1", len(errs), errs)
+			}
+			fe := errs[0]
+			if fe.File != path {
+				t.Errorf("File = %q, want %q", fe.File, path)
+			}
+			if fe.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d", fe.Line, tt.wantLine)
+			}
+			if fe.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", fe.Field, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestValidateFile_Alerts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "localpulse.yaml")
+	writeFile(t, path, `alerts:
+  - name: api down
+    endpoint: http://localhost:3000/health
+    when: down
+    for: 3
+    actions: [bell, notify]
+  - when: error_rate
+    threshold: 5
+    cooldown_seconds: 300
+    actions: [command]
+    command: ./scripts/page.sh
+`)
+
+	if err := ValidateFile(path); err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+}
+
+func TestValidateFile_Webhooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "localpulse.toml")
+	writeFile(t, path, `[[webhooks]]
+url = "https://hooks.slack.com/services/T000/B000/XXX"
+format = "slack"
+events = ["alert"]
+
+[[webhooks]]
+url = "http://localhost:9000/events"
+format = "custom"
+template = "{{.Endpoint}} {{.NewStatus}}"
+headers = { Authorization = "Bearer token", "Content-Type" = "text/plain" }
+retries = 5
+`)
+
+	if err := ValidateFile(path); err != nil {
+		t.Fatalf("ValidateFile() error = %v", err)
+	}
+}
+
+func TestValidateFile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantLine int
+	}{
+		{"json", "localpulse.json", "{\n  \"load_test_rps\": 10,\n  oops\n}", 3},
+		{"yaml", "localpulse.yaml", "load_test_rps: 10\nendpoints:\n\t- url: x\n", 3},
+		{"toml", "localpulse.toml", "load_test_rps = 10\nwindow_seconds = = 3\n", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeFile(t, path, tt.content)
+
+			errs := validationErrors(t, ValidateFile(path))
+			if errs[0].Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", errs[0].Line, tt.wantLine, errs[0])
+			}
+		})
+	}
+}
+
+func TestValidateFile_NewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "localpulse.json")
+	writeFile(t, path, `{"version": 99}`)
+
+	errs := validationErrors(t, ValidateFile(path))
+	if errs[0].Field != "version" || !strings.Contains(errs[0].Message, "newer") {
+		t.Errorf("error = %v, want a version error", errs[0])
+	}
+}
+
+func TestFieldError_Error(t *testing.T) {
+	fe := FieldError{File: "a.yaml", Line: 3, Field: "load_test_rps", Message: "must not be negative"}
+	if got, want := fe.Error(), "a.yaml:3: load_test_rps: must not be negative"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestLoad_InvalidUserConfigIsNeverOverwritten(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+
+	userPath := filepath.Join(home, ".localpulse.json")
+	broken := `{"endpoints": [{"url": "http://localhost:3000"}],`
+	writeFile(t, userPath, broken)
+
+	cfg, err := Load(WithWorkDir(t.TempDir()))
+	if err == nil {
+		t.Fatal("Load() should report the parse error")
+	}
+
+	cfg.AddEndpoint("http://localhost:8080", "")
+	if err := cfg.Save(); err == nil {
+		t.Error("Save() after a failed Load should refuse to write")
+	}
+
+	data, _ := os.ReadFile(userPath)
+	if string(data) != broken {
+		t.Errorf("user config was modified: %q", data)
+	}
+}
+
+func TestSave_KeepsUserFileFormat(t *testing.T) {
+	home := t.TempDir()
+	setHome(t, home)
+
+	userPath := filepath.Join(home, ".localpulse.toml")
+	writeFile(t, userPath, "load_test_rps = 15\n")
+
+	cfg, err := Load(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cfg.AddEndpoint("http://localhost:3000", "API")
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(WithWorkDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Load() after Save error = %v", err)
+	}
+	if loaded.LoadTestRPS != 15 || len(loaded.Endpoints) != 1 {
+		t.Errorf("reloaded rps=%d endpoints=%v, want 15 and one endpoint", loaded.LoadTestRPS, loaded.Endpoints)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".localpulse.json")); !os.IsNotExist(err) {
+		t.Error("Save() should write the existing TOML file, not create a JSON one")
+	}
+}
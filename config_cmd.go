@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Brattlof/localpulse/config"
+)
+
+func runConfigCommand(args []string, opts *cliOptions) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: localpulse config show|validate")
+		return 2
+	}
+
+	rest, err := parseFlags("localpulse config "+args[0], args[1:], opts)
+	if err != nil {
+		return usageError(err)
+	}
+
+	switch args[0] {
+	case "show":
+		if len(rest) > 0 {
+			return usageError(fmt.Errorf("unexpected argument %q", rest[0]))
+		}
+		return runConfigShow(opts.loadOpts)
+	case "validate":
+		return runConfigValidate(rest, opts.loadOpts)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown config command %q\n", args[0])
+		return 2
+	}
+}
+
+func runConfigShow(loadOpts []config.LoadOption) int {
+	cfg, err := config.Load(loadOpts...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config:\n%v\n", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SETTING\tVALUE\tSOURCE")
+	for _, s := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, s.Source)
+	}
+	w.Flush()
+
+	endpoints := cfg.GetEndpoints()
+	fmt.Println()
+	if len(endpoints) == 0 {
+		fmt.Println("No endpoints configured; LocalPulse will scan for them.")
+		return 0
+	}
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENDPOINT\tNAME\tSOURCE")
+	for _, ep := range endpoints {
+		name := ep.Name
+		if name == "" {
+			name = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", ep.URL, name, cfg.EndpointSource(ep.URL))
+	}
+	w.Flush()
+	return 0
+}
+
+func runConfigValidate(files []string, loadOpts []config.LoadOption) int {
+	if len(files) == 0 {
+		userPath, projectPath, err := config.ActiveFiles(loadOpts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		for _, path := range []string{userPath, projectPath} {
+			if path != "" {
+				files = append(files, path)
+			}
+		}
+		if len(files) == 0 {
+			fmt.Println("No config files found; defaults are in use.")
+			return 0
+		}
+	}
+
+	status := 0
+	for _, path := range files {
+		if err := config.ValidateFile(path, loadOpts...); err != nil {
+			status = 1
+			var verr *config.ValidationError
+			if errors.As(err, &verr) {
+				for _, fe := range verr.Errors {
+					fmt.Fprintln(os.Stderr, fe.Error())
+				}
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			}
+			continue
+		}
+		fmt.Printf("%s: OK\n", path)
+	}
+	return status
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -8,38 +9,127 @@ import (
 
 	"github.com/Brattlof/localpulse/app"
 	"github.com/Brattlof/localpulse/config"
+	"github.com/Brattlof/localpulse/ui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 var version = "dev"
 
 func main() {
-	if len(os.Args) > 1 {
-		switch os.Args[1] {
-		case "--version", "-v":
-			fmt.Printf("LocalPulse v%s\n", version)
-			os.Exit(0)
-		case "--help", "-h":
-			printHelp()
-			os.Exit(0)
+	opts := newCLIOptions()
+
+	args, err := parseFlags("localpulse", os.Args[1:], opts)
+	if err != nil {
+		os.Exit(usageError(err))
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "config":
+			os.Exit(runConfigCommand(args[1:], opts))
+		case "run":
+			os.Exit(runServerCommand(args[1:], opts))
+		case "wait-for":
+			os.Exit(runWaitFor(args[1:], opts))
+		case "check":
+			os.Exit(runCheck(args[1:], opts))
+		case "help":
+			opts.help = true
+		case "version":
+			opts.version = true
+		default:
+			os.Exit(usageError(fmt.Errorf("unknown command %q", args[0])))
 		}
 	}
 
-	cfg, err := config.Load()
+	if opts.help {
+		printHelp()
+		os.Exit(0)
+	}
+	if opts.version {
+		fmt.Printf("LocalPulse v%s\n", version)
+		os.Exit(0)
+	}
+
+	cfg, ok := loadConfig(opts)
+	if !ok {
+		os.Exit(1)
+	}
+	if opts.plainMode() {
+		os.Exit(runPlain(cfg, opts, nil))
+	}
+	os.Exit(runTUI(cfg, nil))
+}
+
+func loadConfig(opts *cliOptions) (*config.Config, bool) {
+	cfg, err := config.Load(opts.loadOpts...)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Could not load config: %v\n", err)
-		cfg = config.DefaultConfig()
+		fmt.Fprintf(os.Stderr, "Error: invalid config:\n%v\n", err)
+		fmt.Fprintln(os.Stderr, "Fix the file above or run 'localpulse config validate' for details.")
+		return nil, false
 	}
+	return cfg, true
+}
 
-	setupSignalHandler(cfg)
+func runTUI(cfg *config.Config, cleanup func(), modelOpts ...app.ModelOption) int {
+	shutdown := func() {
+		if cleanup != nil {
+			cleanup()
+		}
+		if err := cfg.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not save config: %v\n", err)
+		}
+	}
+	setupSignalHandler(shutdown)
 
-	m := app.NewModel(cfg)
+	m := app.NewModel(cfg, modelOpts...)
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if fm, ok := final.(app.Model); ok {
+		fm.Close()
+	}
+	shutdown()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	return 0
+}
+
+func runPlain(cfg *config.Config, opts *cliOptions, cleanup func(), plainOpts ...app.PlainOption) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+
+	format := app.PlainText
+	if opts.ndjson {
+		format = app.PlainNDJSON
+	}
+	plainOpts = append([]app.PlainOption{
+		app.WithPlainFormat(format),
+		app.WithColor(!ui.NoColor()),
+		app.WithStatsInterval(opts.statsInterval),
+	}, plainOpts...)
+
+	plain := app.NewPlain(cfg, os.Stdout, plainOpts...)
+	err := plain.Run(ctx)
+
+	if cleanup != nil {
+		cleanup()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
 	}
+	if plain.Down() > 0 {
+		return 1
+	}
+	return 0
 }
 
 func printHelp() {
@@ -47,10 +137,68 @@ func printHelp() {
 
 USAGE:
     localpulse [OPTIONS]
+    localpulse run --port PORT [--path PATH] [--no-restart] -- COMMAND...
+    localpulse wait-for [--max-wait 60s] [--poll 500ms] [--allow-slow]
+                        [-q] [TARGET...]
+    localpulse check [-n COUNT] [--format table|json|junit] [-o FILE]
+                     [--expect-status LIST] [--max-latency DUR]
+                     [--expect-body TEXT] [URL...]
+    localpulse config show [OPTIONS]
+    localpulse config validate [FILE...]
 
 OPTIONS:
-    -h, --help      Show this help message
-    -v, --version   Show version information
+    -c, --config PATH           Use this project config file instead of
+                                searching for localpulse.json/.localpulse.yaml
+                                upwards from the current directory
+        --host HOST             Host to scan for local services
+    -p, --ports LIST            Comma-separated ports to scan
+        --socket-dirs LIST      Comma-separated directories to scan for Unix
+                                sockets
+    -e, --endpoint URL          Monitor this endpoint (repeatable)
+    -i, --interval SECS         Health check interval
+    -r, --rps N                 Requests per second during load tests
+    -t, --timeout SECS          Request timeout
+        --slow-threshold MS     Responses slower than this are slow
+        --down-threshold MS     Responses slower than this are down
+        --accept-status LIST    Statuses that count as up, e.g. 2xx,401
+        --concurrency N         Maximum concurrent requests per endpoint
+        --window SECS           Metrics window
+        --flap-threshold N      Status changes within the flap window that
+                                mark an endpoint as flapping
+        --flap-window SECS      Window for counting status changes
+        --log-sample-rate RATE  Share of successful requests shown in the log
+        --theme NAME            Color theme: auto, dark or light
+        --process SPEC          Watch the server process under test: a PID,
+                                a command name, or :PORT for whatever is
+                                listening on that port
+        --scenarios FILE        Load multi-step scenarios from FILE
+        --tls-ca-file FILE      Trust this CA in addition to the system roots
+        --tls-cert-file FILE    Client certificate for mutual TLS
+        --tls-key-file FILE     Key for --tls-cert-file
+        --tls-min-version VER   Minimum TLS version: 1.0, 1.1, 1.2 or 1.3
+        --tls-insecure          Skip certificate verification
+        --report-untrusted-tls  List HTTPS ports with unverifiable
+                                certificates as down instead of skipping them
+        --cert-warn-days N      Warn about certificates expiring within N days
+        --plain                 Print status changes and stats as plain text
+                                instead of the full-screen UI (automatic when
+                                stdout is not a terminal)
+        --ndjson                Like --plain, one JSON object per line
+        --duration DUR          In plain mode, stop after DUR and print the
+                                summary (default: until Ctrl+C)
+        --stats-interval DUR    In plain mode, how often to print per-endpoint
+                                stats (default 10s)
+    -h, --help                  Show this help message
+    -v, --version               Show version information
+
+ENVIRONMENT:
+    Every option above can also be set with a LOCALPULSE_* variable named
+    after its config key, e.g. LOCALPULSE_LOAD_TEST_RPS=50,
+    LOCALPULSE_DEFAULT_PORTS=3000,8080 or LOCALPULSE_ENDPOINTS=url1,url2.
+    LOCALPULSE_CONFIG sets the project config file.
+    NO_COLOR disables colors and replaces status emoji with words.
+
+    Precedence: flag > environment > project config > user config > defaults
 
 KEYBOARD SHORTCUTS:
     Tab/Shift+Tab   Focus panels
@@ -59,23 +207,145 @@ KEYBOARD SHORTCUTS:
     x               Stop load testing
     +/=             Increase RPS
     -               Decrease RPS
+    >/<             Raise/lower the selected endpoint's fixed RPS
+    ]/[             Raise/lower the selected endpoint's weight
     a               Add endpoint manually
     d               Delete selected endpoint
+    P               Attach to (or detach from) the process listening
+                    on the selected endpoint's port
+    R               Restart the server started with 'localpulse run'
     Enter           Toggle load testing for selected endpoint
+    m               Expand/collapse the system panel
     q/Ctrl+C        Quit
 
+LOG PANEL (when focused):
+    /               Search log messages
+    e               Show errors only
+    c               Cycle status class filter (2xx/3xx/4xx/5xx)
+    f               Cycle endpoint filter
+    p/Space         Pause/resume the live log
+    ↑↓/PgUp/PgDn    Scroll back (pauses the log)
+    End/G           Jump to latest and resume
+    w               Save log to file
+    Esc             Clear filters
+
 EXAMPLES:
     localpulse              Start monitoring
-    localpulse --version    Show version`)
+    localpulse --version    Show version
+    localpulse --config ./team/localpulse.yaml
+    localpulse --ports 3000,5173 --rps 50 --theme light
+    localpulse -e http://localhost:8080/health
+    localpulse --process :3000
+    localpulse --ndjson --duration 5m > health.ndjson
+    localpulse run --port 3000 -- npm run dev
+    localpulse wait-for --max-wait 30s :5432 http://localhost:3000/health
+    localpulse check -n 5 --max-latency 300ms
+    localpulse check --format junit -o localpulse.xml
+    localpulse config show --rps 50
+    localpulse config validate
+
+RUN:
+    'localpulse run' starts COMMAND in its own process group, waits until
+    PORT accepts connections, adds http://HOST:PORT/PATH as an endpoint and
+    shows the command's stdout/stderr in a Server log pane (Tab to focus).
+    Crashes are restarted with backoff unless --no-restart is given; the
+    whole process group is terminated when LocalPulse quits.
+
+WAIT-FOR:
+    'localpulse wait-for' blocks until every TARGET is healthy and exits 0,
+    or exits 1 with a summary of what never came up. A TARGET is a URL
+    (healthy when it answers with an accepted status within the slow
+    threshold, 200-399 within 500ms unless the endpoint's accept_status,
+    slow_threshold_ms or timeout say otherwise), a PORT or HOST:PORT
+    (healthy when it accepts connections). Without targets it waits for
+    every configured endpoint. --allow-slow also accepts slow responses.
+
+PLAIN MODE:
+    With --plain, --ndjson or when stdout is not a terminal, LocalPulse
+    checks every endpoint each interval and prints one line per status
+    change, a stats line per endpoint every --stats-interval and a summary
+    on exit. The exit code is 1 if any endpoint is down at the end. NDJSON
+    events carry an "event" field: start, scan, endpoint, status, stats,
+    server, output, alert, then summary followed by one endpoint_summary
+    per endpoint.
+
+CHECK:
+    'localpulse check' sends COUNT requests to every URL (or every
+    configured endpoint), prints the result as a table, JSON or JUnit XML
+    and exits 1 if any endpoint is down. A request fails on a network
+    error, a status outside accept_status (or --expect-status), a
+    response slower than --max-latency or a body missing --expect-body.
+    Slow endpoints are reported but do not fail the check.
+
+ALERTS:
+    Rules under "alerts" in a config file ring the terminal bell, send a
+    desktop notification or run a shell command when an endpoint is down
+    for N checks, slow for N seconds or above an error rate. See README.
+
+WEBHOOKS:
+    Entries under "webhooks" POST status changes and alerts as JSON, Slack
+    messages or a custom Go template, with retries and backoff. See README.
+
+SCENARIOS:
+    Entries under "scenarios" (in the config or in --scenarios FILE) chain
+    requests, extracting values from JSON, headers, cookies or a regex
+    into ${variables} for later steps. Each one shows up as an endpoint
+    and runs as a whole flow during load tests. See README.
+
+REQUEST TEMPLATES:
+    Endpoints can set a method, headers and a body with ${column}
+    placeholders filled from a CSV or JSON file listed under "feeders"
+    (sequential, random or unique rows), plus ${uuid}, ${timestamp},
+    ${randint(min,max)} and ${randstr(n)}. See README.
+
+AUTHENTICATION:
+    Profiles under "auth" add bearer, basic or OAuth2 client-credentials
+    auth and per-worker cookie jars to endpoints. Secrets are read from
+    the environment variables named by token_env, password_env and
+    client_secret_env and are never saved. See README.
+
+TLS:
+    tls_ca_file, tls_cert_file/tls_key_file (mTLS), tls_min_version and
+    tls_insecure apply to all endpoints and can be overridden per
+    endpoint, which also takes tls_server_name. Insecure endpoints are
+    marked INSECURE. Set report_untrusted_tls to list HTTPS ports whose
+    certificate does not verify instead of skipping them. Certificates
+    are inspected on discovery and every 10 minutes; ones expiring within
+    cert_warn_days (default 14), expired or issued for another host are
+    flagged in the endpoint list. See README.
+
+UNIX SOCKETS:
+    Endpoints may be unix:///path/to.sock:/http/path. Sockets in the
+    directories listed under socket_dirs are discovered on scan. See README.
+
+HTTP/2:
+    Per endpoint, protocol selects auto, http1, h2 or h2c (cleartext
+    HTTP/2) and connections multiplexes requests over that many
+    connections. The negotiated protocol and the server's stream limit
+    are shown in the endpoint details. See README.
+
+WEBSOCKETS:
+    ws:// and wss:// endpoints open one connection per virtual user and
+    send the endpoint's messages (default "ping") at the load test rate;
+    a reply containing match completes each round trip. ${seq} and ${vu}
+    correlate messages with replies. Connect time, round-trip latency,
+    messages/s and disconnects are shown in the endpoint details.
+
+CONFIG FILES:
+    ~/.localpulse.json (or .yaml/.toml)
+                                    Personal settings
+    localpulse.json / .yaml / .toml Project settings, found in the current
+                                    directory or any parent; layered over
+                                    the personal settings`)
 }
 
-func setupSignalHandler(cfg *config.Config) {
+func setupSignalHandler(shutdown func()) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		<-sigChan
-		cfg.Save()
+		shutdown()
 		os.Exit(0)
 	}()
 }
@@ -0,0 +1,342 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type AlertCondition string
+
+const (
+	AlertDown      AlertCondition = "down"
+	AlertSlow      AlertCondition = "slow"
+	AlertErrorRate AlertCondition = "error_rate"
+)
+
+type AlertAction string
+
+const (
+	ActionBell    AlertAction = "bell"
+	ActionNotify  AlertAction = "notify"
+	ActionCommand AlertAction = "command"
+)
+
+type AlertRule struct {
+	Name      string
+	Endpoint  string
+	When      AlertCondition
+	For       int
+	Threshold float64
+	Actions   []AlertAction
+	Command   string
+	Cooldown  time.Duration
+}
+
+func (r AlertRule) label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return string(r.When)
+}
+
+func (r AlertRule) matches(ep *Endpoint) bool {
+	return r.Endpoint == "" || r.Endpoint == ep.URL || r.Endpoint == ep.Name
+}
+
+type AlertEvent struct {
+	Rule       string
+	Condition  AlertCondition
+	Resolved   bool
+	URL        string
+	Name       string
+	Status     EndpointStatus
+	Previous   EndpointStatus
+	Value      float64
+	Message    string
+	Suppressed int
+	Time       time.Time
+}
+
+func (e AlertEvent) State() string {
+	if e.Resolved {
+		return "resolved"
+	}
+	return "firing"
+}
+
+type alertState struct {
+	downs     int
+	slowSince time.Time
+	firing    bool
+	notified  bool
+	lastFired time.Time
+	prev      EndpointStatus
+	skipped   int
+}
+
+type Alerter struct {
+	mu sync.Mutex
+
+	rules  []AlertRule
+	states map[string]*alertState
+
+	bell     io.Writer
+	notify   func(title, body string) error
+	command  func(command string, env []string) error
+	now      func() time.Time
+	failures []string
+}
+
+type AlerterOption func(*Alerter)
+
+func WithBell(w io.Writer) AlerterOption {
+	return func(a *Alerter) {
+		a.bell = w
+	}
+}
+
+func WithNotifier(fn func(title, body string) error) AlerterOption {
+	return func(a *Alerter) {
+		a.notify = fn
+	}
+}
+
+func WithCommandRunner(fn func(command string, env []string) error) AlerterOption {
+	return func(a *Alerter) {
+		a.command = fn
+	}
+}
+
+func WithAlertClock(now func() time.Time) AlerterOption {
+	return func(a *Alerter) {
+		a.now = now
+	}
+}
+
+func NewAlerter(rules []AlertRule, opts ...AlerterOption) *Alerter {
+	a := &Alerter{
+		rules:   rules,
+		states:  make(map[string]*alertState),
+		bell:    os.Stderr,
+		notify:  notifySend,
+		command: runShellCommand,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func (a *Alerter) SetRules(rules []AlertRule) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rules = rules
+	a.states = make(map[string]*alertState)
+}
+
+func (a *Alerter) Observe(ep *Endpoint, stats Stats) []AlertEvent {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	var events []AlertEvent
+
+	for i, rule := range a.rules {
+		if !rule.matches(ep) {
+			continue
+		}
+
+		key := strconv.Itoa(i) + "|" + ep.URL
+		st := a.states[key]
+		if st == nil {
+			st = &alertState{prev: StatusUnknown}
+			a.states[key] = st
+		}
+
+		active, value, message := a.evaluate(rule, st, ep, stats, now)
+		event := AlertEvent{
+			Rule:      rule.label(),
+			Condition: rule.When,
+			URL:       ep.URL,
+			Name:      ep.Name,
+			Status:    ep.Status,
+			Previous:  st.prev,
+			Value:     value,
+			Message:   message,
+			Time:      now,
+		}
+		st.prev = ep.Status
+
+		switch {
+		case active && !st.firing:
+			st.firing = true
+			if !st.lastFired.IsZero() && now.Sub(st.lastFired) < rule.Cooldown {
+				st.notified = false
+				st.skipped++
+				continue
+			}
+			st.notified = true
+			st.lastFired = now
+			event.Suppressed = st.skipped
+			st.skipped = 0
+			events = append(events, event)
+			a.dispatch(rule, event)
+		case !active && st.firing:
+			st.firing = false
+			if !st.notified {
+				continue
+			}
+			st.notified = false
+			event.Resolved = true
+			event.Message = describeEndpoint(ep) + " recovered (" + string(ep.Status) + ")"
+			events = append(events, event)
+			a.dispatch(rule, event)
+		}
+	}
+
+	return events
+}
+
+func (a *Alerter) evaluate(rule AlertRule, st *alertState, ep *Endpoint, stats Stats, now time.Time) (bool, float64, string) {
+	name := describeEndpoint(ep)
+
+	switch rule.When {
+	case AlertDown:
+		if ep.Status == StatusDown {
+			st.downs++
+		} else {
+			st.downs = 0
+		}
+		need := rule.For
+		if need < 1 {
+			need = 1
+		}
+		msg := fmt.Sprintf("%s down for %d checks", name, st.downs)
+		if st.downs == 1 {
+			msg = name + " is down"
+		}
+		return st.downs >= need, float64(st.downs), msg
+
+	case AlertSlow:
+		if ep.Status != StatusSlow {
+			st.slowSince = time.Time{}
+			return false, 0, ""
+		}
+		if st.slowSince.IsZero() {
+			st.slowSince = now
+		}
+		slowFor := now.Sub(st.slowSince)
+		msg := fmt.Sprintf("%s slow for %s (%s)", name, slowFor.Round(time.Second), ep.LastLatency.Round(time.Millisecond))
+		return slowFor >= time.Duration(rule.For)*time.Second, slowFor.Seconds(), msg
+
+	case AlertErrorRate:
+		if stats.TotalRequests == 0 {
+			return st.firing, stats.ErrorRate, ""
+		}
+		msg := fmt.Sprintf("%s error rate %.1f%% above %.1f%%", name, stats.ErrorRate, rule.Threshold)
+		return stats.ErrorRate > rule.Threshold, stats.ErrorRate, msg
+	}
+	return false, 0, ""
+}
+
+func (a *Alerter) dispatch(rule AlertRule, event AlertEvent) {
+	for _, action := range rule.Actions {
+		switch action {
+		case ActionBell:
+			if !event.Resolved && a.bell != nil {
+				io.WriteString(a.bell, "\a")
+			}
+		case ActionNotify:
+			title := "LocalPulse: " + event.Rule
+			if event.Resolved {
+				title += " resolved"
+			}
+			go a.run("notify-send", func() error { return a.notify(title, event.Message) })
+		case ActionCommand:
+			env := append(os.Environ(), AlertEnv(event)...)
+			command := rule.Command
+			go a.run(command, func() error { return a.command(command, env) })
+		}
+	}
+}
+
+func (a *Alerter) run(what string, fn func() error) {
+	if err := fn(); err != nil {
+		a.mu.Lock()
+		a.failures = append(a.failures, what+": "+err.Error())
+		a.mu.Unlock()
+	}
+}
+
+func (a *Alerter) DrainFailures() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	failures := a.failures
+	a.failures = nil
+	return failures
+}
+
+func AlertEnv(e AlertEvent) []string {
+	return []string{
+		"LOCALPULSE_ALERT_RULE=" + e.Rule,
+		"LOCALPULSE_ALERT_CONDITION=" + string(e.Condition),
+		"LOCALPULSE_ALERT_STATE=" + e.State(),
+		"LOCALPULSE_ALERT_URL=" + e.URL,
+		"LOCALPULSE_ALERT_NAME=" + e.Name,
+		"LOCALPULSE_ALERT_STATUS=" + string(e.Status),
+		"LOCALPULSE_ALERT_PREVIOUS=" + string(e.Previous),
+		"LOCALPULSE_ALERT_VALUE=" + strconv.FormatFloat(e.Value, 'f', -1, 64),
+		"LOCALPULSE_ALERT_MESSAGE=" + e.Message,
+		"LOCALPULSE_ALERT_TIME=" + e.Time.Format(time.RFC3339),
+	}
+}
+
+func describeEndpoint(ep *Endpoint) string {
+	if ep.Name != "" {
+		return ep.Name
+	}
+	return ep.URL
+}
+
+func notifySend(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "notify-send", "--app-name=LocalPulse", title, body).Run()
+}
+
+func runShellCommand(command string, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, truncateOutput(out))
+		}
+		return err
+	}
+	return nil
+}
+
+func truncateOutput(out []byte) string {
+	if len(out) > 200 {
+		out = out[:200]
+	}
+	return string(out)
+}
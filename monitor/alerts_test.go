@@ -0,0 +1,185 @@
+package monitor
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestAlerter(rules []AlertRule, opts ...AlerterOption) (*Alerter, *fakeClock, *bytes.Buffer) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	bell := &bytes.Buffer{}
+	opts = append([]AlerterOption{
+		WithAlertClock(clock.Now),
+		WithBell(bell),
+		WithNotifier(func(string, string) error { return nil }),
+		WithCommandRunner(func(string, []string) error { return nil }),
+	}, opts...)
+	return NewAlerter(rules, opts...), clock, bell
+}
+
+func observeStatus(a *Alerter, ep *Endpoint, status EndpointStatus) []AlertEvent {
+	ep.Status = status
+	return a.Observe(ep, Stats{})
+}
+
+func TestAlerter_DownForChecks(t *testing.T) {
+	a, _, bell := newTestAlerter([]AlertRule{{When: AlertDown, For: 3, Actions: []AlertAction{ActionBell}}})
+	ep := &Endpoint{URL: "http://localhost:3000/", Name: "api"}
+
+	for i := 0; i < 2; i++ {
+		if events := observeStatus(a, ep, StatusDown); len(events) != 0 {
+			t.Fatalf("check %d: got %d events, want none before 3 checks", i+1, len(events))
+		}
+	}
+
+	events := observeStatus(a, ep, StatusDown)
+	if len(events) != 1 || events[0].Resolved {
+		t.Fatalf("third down check: events = %+v, want one firing event", events)
+	}
+	if !strings.Contains(events[0].Message, "down for 3 checks") {
+		t.Errorf("Message = %q, want it to mention 3 checks", events[0].Message)
+	}
+	if bell.String() != "\a" {
+		t.Errorf("bell = %q, want one BEL", bell.String())
+	}
+
+	if events := observeStatus(a, ep, StatusDown); len(events) != 0 {
+		t.Errorf("still down: got %d events, want none while already firing", len(events))
+	}
+
+	events = observeStatus(a, ep, StatusHealthy)
+	if len(events) != 1 || !events[0].Resolved {
+		t.Fatalf("recovery: events = %+v, want one resolved event", events)
+	}
+	if bell.String() != "\a" {
+		t.Errorf("bell rang on recovery: %q", bell.String())
+	}
+}
+
+func TestAlerter_CooldownSuppressesFlapping(t *testing.T) {
+	a, clock, _ := newTestAlerter([]AlertRule{{When: AlertDown, Cooldown: time.Minute, Actions: []AlertAction{ActionBell}}})
+	ep := &Endpoint{URL: "http://localhost:3000/"}
+
+	fired := 0
+	for i := 0; i < 5; i++ {
+		for _, e := range observeStatus(a, ep, StatusDown) {
+			if !e.Resolved {
+				fired++
+			}
+		}
+		observeStatus(a, ep, StatusHealthy)
+		clock.Advance(5 * time.Second)
+	}
+	if fired != 1 {
+		t.Errorf("fired %d times while flapping within the cooldown, want 1", fired)
+	}
+
+	clock.Advance(time.Minute)
+	events := observeStatus(a, ep, StatusDown)
+	if len(events) != 1 {
+		t.Fatalf("after cooldown: got %d events, want 1", len(events))
+	}
+	if events[0].Suppressed != 4 {
+		t.Errorf("Suppressed = %d, want 4", events[0].Suppressed)
+	}
+}
+
+func TestAlerter_SlowForSeconds(t *testing.T) {
+	a, clock, _ := newTestAlerter([]AlertRule{{When: AlertSlow, For: 10, Actions: []AlertAction{ActionBell}}})
+	ep := &Endpoint{URL: "http://localhost:3000/"}
+
+	if events := observeStatus(a, ep, StatusSlow); len(events) != 0 {
+		t.Fatalf("got %d events on first slow check, want none", len(events))
+	}
+	clock.Advance(5 * time.Second)
+	if events := observeStatus(a, ep, StatusSlow); len(events) != 0 {
+		t.Fatalf("got %d events after 5s slow, want none", len(events))
+	}
+	clock.Advance(5 * time.Second)
+	if events := observeStatus(a, ep, StatusSlow); len(events) != 1 {
+		t.Fatalf("got %d events after 10s slow, want 1", len(events))
+	}
+}
+
+func TestAlerter_ErrorRate(t *testing.T) {
+	a, _, _ := newTestAlerter([]AlertRule{{When: AlertErrorRate, Threshold: 10, Actions: []AlertAction{ActionBell}}})
+	ep := &Endpoint{URL: "http://localhost:3000/", Status: StatusHealthy}
+
+	if events := a.Observe(ep, Stats{TotalRequests: 100, ErrorRate: 5}); len(events) != 0 {
+		t.Fatalf("5%% errors: got %d events, want none", len(events))
+	}
+	events := a.Observe(ep, Stats{TotalRequests: 100, ErrorRate: 25})
+	if len(events) != 1 || events[0].Value != 25 {
+		t.Fatalf("25%% errors: events = %+v, want one event with value 25", events)
+	}
+}
+
+func TestAlerter_EndpointFilter(t *testing.T) {
+	a, _, _ := newTestAlerter([]AlertRule{{Endpoint: "api", When: AlertDown, Actions: []AlertAction{ActionBell}}})
+
+	if events := observeStatus(a, &Endpoint{URL: "http://localhost:3000/", Name: "web"}, StatusDown); len(events) != 0 {
+		t.Errorf("non-matching endpoint: got %d events, want none", len(events))
+	}
+	if events := observeStatus(a, &Endpoint{URL: "http://localhost:4000/", Name: "api"}, StatusDown); len(events) != 1 {
+		t.Errorf("matching endpoint: got %d events, want 1", len(events))
+	}
+}
+
+func TestAlerter_CommandEnvironment(t *testing.T) {
+	var mu sync.Mutex
+	var gotCommand string
+	var gotEnv []string
+	done := make(chan struct{})
+
+	a, _, _ := newTestAlerter(
+		[]AlertRule{{Name: "api down", When: AlertDown, Actions: []AlertAction{ActionCommand}, Command: "./page.sh"}},
+		WithCommandRunner(func(command string, env []string) error {
+			mu.Lock()
+			gotCommand, gotEnv = command, env
+			mu.Unlock()
+			close(done)
+			return nil
+		}),
+	)
+	observeStatus(a, &Endpoint{URL: "http://localhost:3000/", Name: "api"}, StatusDown)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("command was not run")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotCommand != "./page.sh" {
+		t.Errorf("command = %q, want ./page.sh", gotCommand)
+	}
+	want := map[string]bool{
+		"LOCALPULSE_ALERT_RULE=api down":              false,
+		"LOCALPULSE_ALERT_STATE=firing":               false,
+		"LOCALPULSE_ALERT_URL=http://localhost:3000/": false,
+		"LOCALPULSE_ALERT_STATUS=down":                false,
+		"LOCALPULSE_ALERT_PREVIOUS=unknown":           false,
+	}
+	for _, kv := range gotEnv {
+		if _, ok := want[kv]; ok {
+			want[kv] = true
+		}
+	}
+	for kv, seen := range want {
+		if !seen {
+			t.Errorf("environment is missing %s", kv)
+		}
+	}
+}
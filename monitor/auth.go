@@ -0,0 +1,159 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type AuthType string
+
+const (
+	AuthBearer AuthType = "bearer"
+	AuthBasic  AuthType = "basic"
+	AuthOAuth2 AuthType = "oauth2"
+)
+
+type Auth struct {
+	Type     AuthType
+	Token    string
+	Username string
+	Password string
+	OAuth2   *TokenSource
+	Cookies  bool
+}
+
+func (a *Auth) Prepare(ctx context.Context) error {
+	if a == nil || a.Type != AuthOAuth2 || a.OAuth2 == nil {
+		return nil
+	}
+	_, err := a.OAuth2.Token(ctx)
+	return err
+}
+
+func (a *Auth) apply(ctx context.Context, req *http.Request) error {
+	if a == nil {
+		return nil
+	}
+	switch a.Type {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case AuthBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case AuthOAuth2:
+		if a.OAuth2 == nil {
+			return fmt.Errorf("oauth2 auth has no token source")
+		}
+		token, err := a.OAuth2.Token(ctx)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+type TokenSource struct {
+	mu sync.Mutex
+
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	token  string
+	expiry time.Time
+}
+
+func NewTokenSource(tokenURL, clientID, clientSecret string, scopes []string) *TokenSource {
+	return &TokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && (s.expiry.IsZero() || time.Now().Before(s.expiry)) {
+		return s.token, nil
+	}
+	token, lifetime, err := s.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("oauth2 token from %s: %w", s.tokenURL, err)
+	}
+	s.token = token
+	s.expiry = time.Time{}
+	if lifetime > 0 {
+		s.expiry = time.Now().Add(lifetime * 9 / 10)
+	}
+	return s.token, nil
+}
+
+func (s *TokenSource) Expiry() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expiry
+}
+
+func (s *TokenSource) fetch(ctx context.Context) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	var body struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+		Error       string      `json:"error"`
+		Description string      `json:"error_description"`
+	}
+	jsonErr := json.Unmarshal(data, &body)
+	if resp.StatusCode != http.StatusOK {
+		msg := fmt.Sprintf("status %d", resp.StatusCode)
+		if body.Error != "" {
+			msg += ": " + body.Error
+		}
+		if body.Description != "" {
+			msg += " (" + body.Description + ")"
+		}
+		return "", 0, fmt.Errorf("%s", msg)
+	}
+	if jsonErr != nil {
+		return "", 0, fmt.Errorf("invalid token response: %v", jsonErr)
+	}
+	if body.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response has no access_token")
+	}
+
+	var lifetime time.Duration
+	if seconds, err := body.ExpiresIn.Float64(); err == nil && seconds > 0 {
+		lifetime = time.Duration(seconds * float64(time.Second))
+	}
+	return body.AccessToken, lifetime, nil
+}
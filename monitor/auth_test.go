@@ -0,0 +1,135 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTokenServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "client_credentials" || id != "cli" || secret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_client","error_description":"bad credentials"}`))
+			return
+		}
+		n := calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok-` + string(rune('0'+n)) + `","token_type":"Bearer","expires_in":3600,"scope":"` + r.FormValue("scope") + `"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTokenSource_CachesAndRefreshes(t *testing.T) {
+	var calls atomic.Int32
+	srv := newTokenServer(t, &calls)
+	ts := NewTokenSource(srv.URL, "cli", "s3cret", []string{"read", "write"})
+
+	for i := 0; i < 3; i++ {
+		token, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token != "tok-1" {
+			t.Errorf("Token() = %q, want the cached tok-1", token)
+		}
+	}
+	if until := time.Until(ts.Expiry()); until < 50*time.Minute || until > time.Hour {
+		t.Errorf("token expires in %v, want a little under an hour", until)
+	}
+
+	ts.mu.Lock()
+	ts.expiry = time.Now().Add(-time.Second)
+	ts.mu.Unlock()
+	if token, _ := ts.Token(context.Background()); token != "tok-2" || calls.Load() != 2 {
+		t.Errorf("Token() after expiry = %q with %d fetches, want tok-2 after 2", token, calls.Load())
+	}
+}
+
+func TestTokenSource_Error(t *testing.T) {
+	var calls atomic.Int32
+	srv := newTokenServer(t, &calls)
+	auth := &Auth{Type: AuthOAuth2, OAuth2: NewTokenSource(srv.URL, "cli", "wrong", nil)}
+
+	err := auth.Prepare(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "status 401: invalid_client (bad credentials)") {
+		t.Errorf("Prepare() error = %v, want the token endpoint's error", err)
+	}
+}
+
+func TestLoadTester_Auth(t *testing.T) {
+	var calls atomic.Int32
+	tokens := newTokenServer(t, &calls)
+
+	tests := []struct {
+		name string
+		auth *Auth
+		want string
+	}{
+		{"bearer", &Auth{Type: AuthBearer, Token: "static"}, "Bearer static"},
+		{"basic", &Auth{Type: AuthBasic, Username: "dev", Password: "pw"}, "Basic ZGV2OnB3"},
+		{"oauth2", &Auth{Type: AuthOAuth2, OAuth2: NewTokenSource(tokens.URL, "cli", "s3cret", nil)}, "Bearer tok-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wrong atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != tt.want {
+					wrong.Add(1)
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer srv.Close()
+
+			ep, _ := NewEndpoint(srv.URL)
+			ep.Auth = tt.auth
+			metrics := NewMetrics(100)
+			lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+			lt.Start()
+			lt.SendBurst(4)
+			time.Sleep(200 * time.Millisecond)
+			lt.Stop()
+
+			if got := metrics.GetStats(); got.TotalRequests != 4 || wrong.Load() != 0 {
+				t.Errorf("%d requests, %d with the wrong Authorization header", got.TotalRequests, wrong.Load())
+			}
+		})
+	}
+}
+
+func TestLoadTester_CookieJarPerWorker(t *testing.T) {
+	var sessions, anonymous atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			anonymous.Add(1)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "vu-" + string(rune('0'+sessions.Add(1)))})
+		}
+	}))
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(srv.URL)
+	ep.Auth = &Auth{Cookies: true}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(3))
+	lt.Start()
+	for i := 0; i < 12; i++ {
+		lt.SendRequest()
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+	lt.Stop()
+
+	if got := metrics.GetStats().TotalRequests; got != 12 {
+		t.Fatalf("TotalRequests = %d, want 12", got)
+	}
+	if n := anonymous.Load(); n < 1 || n > 3 {
+		t.Errorf("%d requests arrived without a session cookie, want at most one per worker", n)
+	}
+}
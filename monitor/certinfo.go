@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CertInfo struct {
+	Subject    string    `json:"subject"`
+	SANs       []string  `json:"sans,omitempty"`
+	Issuer     string    `json:"issuer"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	KeyType    string    `json:"key_type"`
+	Version    string    `json:"version"`
+	Cipher     string    `json:"cipher"`
+	ALPN       string    `json:"alpn,omitempty"`
+	ServerName string    `json:"server_name"`
+	HostError  string    `json:"host_error,omitempty"`
+	SelfSigned bool      `json:"self_signed,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+func (c *CertInfo) DaysLeft(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
+
+func (c *CertInfo) Expired(now time.Time) bool {
+	return now.After(c.NotAfter) || now.Before(c.NotBefore)
+}
+
+func (c *CertInfo) Expiring(now time.Time, within time.Duration) bool {
+	return !c.Expired(now) && c.NotAfter.Sub(now) <= within
+}
+
+type CertIssue string
+
+const (
+	CertOK           CertIssue = ""
+	CertExpired      CertIssue = "expired"
+	CertHostMismatch CertIssue = "host mismatch"
+	CertExpiring     CertIssue = "expiring"
+)
+
+func (c *CertInfo) Issue(now time.Time, warn time.Duration) CertIssue {
+	switch {
+	case c.Expired(now):
+		return CertExpired
+	case c.HostError != "":
+		return CertHostMismatch
+	case warn > 0 && c.Expiring(now, warn):
+		return CertExpiring
+	}
+	return CertOK
+}
+
+func InspectCert(ctx context.Context, rawURL string, opts TLSOptions) (*CertInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && u.Scheme != "wss" {
+		return nil, fmt.Errorf("%s is not an HTTPS URL", rawURL)
+	}
+	host := u.Hostname()
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(host, "443")
+	}
+
+	cfg, err := opts.Config()
+	if err != nil {
+		return nil, err
+	}
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+	cfg.ServerName = serverName
+	cfg.InsecureSkipVerify = true
+	cfg.NextProtos = []string{"h2", "http/1.1"}
+
+	dialer := &tls.Dialer{Config: cfg}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s sent no certificate", addr)
+	}
+	leaf := state.PeerCertificates[0]
+
+	info := &CertInfo{
+		Subject:    leaf.Subject.String(),
+		Issuer:     leaf.Issuer.String(),
+		NotBefore:  leaf.NotBefore,
+		NotAfter:   leaf.NotAfter,
+		KeyType:    keyType(leaf),
+		Version:    tls.VersionName(state.Version),
+		Cipher:     tls.CipherSuiteName(state.CipherSuite),
+		ALPN:       state.NegotiatedProtocol,
+		ServerName: serverName,
+		SelfSigned: leaf.Subject.String() == leaf.Issuer.String() && leaf.CheckSignatureFrom(leaf) == nil,
+		CheckedAt:  time.Now(),
+	}
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	if err := leaf.VerifyHostname(serverName); err != nil {
+		info.HostError = "not valid for " + serverName
+	}
+	return info, nil
+}
+
+func keyType(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return strings.TrimSuffix(cert.PublicKeyAlgorithm.String(), "Unknown")
+}
+
+type certTarget struct {
+	url  string
+	opts TLSOptions
+}
+
+func InspectEndpoints(ctx context.Context, endpoints []*Endpoint, timeout time.Duration) map[string]*CertInfo {
+	return inspectCerts(ctx, endpoints, timeout, func(ep *Endpoint) TLSOptions { return ep.TLS })
+}
+
+func inspectCerts(ctx context.Context, endpoints []*Endpoint, timeout time.Duration, optsFor func(*Endpoint) TLSOptions) map[string]*CertInfo {
+	targets := make(map[certTarget][]string)
+	for _, ep := range endpoints {
+		u, err := url.Parse(ep.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "wss") {
+			continue
+		}
+		t := certTarget{url: "https://" + u.Host, opts: optsFor(ep)}
+		targets[t] = append(targets[t], ep.URL)
+	}
+
+	certs := make(map[string]*CertInfo)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for t, urls := range targets {
+		wg.Add(1)
+		go func(t certTarget, urls []string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			info, err := InspectCert(ctx, t.url, t.opts)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			for _, u := range urls {
+				certs[u] = info
+			}
+			mu.Unlock()
+		}(t, urls)
+	}
+	wg.Wait()
+	return certs
+}
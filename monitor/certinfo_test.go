@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInspectCert(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	info, err := InspectCert(context.Background(), srv.URL, TLSOptions{})
+	if err != nil {
+		t.Fatalf("InspectCert() error = %v", err)
+	}
+	if !slices.Contains(info.SANs, "example.com") || !slices.Contains(info.SANs, "127.0.0.1") {
+		t.Errorf("SANs = %v, want example.com and 127.0.0.1", info.SANs)
+	}
+	if info.Subject == "" || info.Issuer == "" || info.KeyType == "" || info.Cipher == "" {
+		t.Errorf("InspectCert() = %+v, want subject, issuer, key type and cipher", info)
+	}
+	if info.Version != "TLS 1.3" || info.ALPN != "http/1.1" || info.ServerName != "127.0.0.1" || info.HostError != "" {
+		t.Errorf("version %q, ALPN %q, server name %q, host error %q", info.Version, info.ALPN, info.ServerName, info.HostError)
+	}
+	if info.DaysLeft(time.Now()) < 365 {
+		t.Errorf("DaysLeft() = %d, want the long-lived test certificate", info.DaysLeft(time.Now()))
+	}
+
+	mismatch, err := InspectCert(context.Background(), srv.URL, TLSOptions{ServerName: "api.local"})
+	if err != nil || mismatch.HostError != "not valid for api.local" || mismatch.Issue(time.Now(), 0) != CertHostMismatch {
+		t.Errorf("InspectCert(api.local) = %+v, %v, want a host mismatch", mismatch, err)
+	}
+
+	if _, err := InspectCert(context.Background(), "http://127.0.0.1:1", TLSOptions{}); err == nil {
+		t.Error("InspectCert(http URL): expected error")
+	}
+}
+
+func TestCertInfo_Expiry(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		daysLeft int
+		expiring bool
+		expired  bool
+	}{
+		{"valid", now.Add(60 * 24 * time.Hour), 60, false, false},
+		{"expiring", now.Add(5*24*time.Hour + time.Hour), 5, true, false},
+		{"expires today", now.Add(2 * time.Hour), 0, true, false},
+		{"expired", now.Add(-36 * time.Hour), -2, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CertInfo{NotBefore: now.Add(-90 * 24 * time.Hour), NotAfter: tt.notAfter}
+			if got := c.DaysLeft(now); got != tt.daysLeft {
+				t.Errorf("DaysLeft() = %d, want %d", got, tt.daysLeft)
+			}
+			if got := c.Expiring(now, 14*24*time.Hour); got != tt.expiring {
+				t.Errorf("Expiring() = %v, want %v", got, tt.expiring)
+			}
+			if got := c.Expired(now); got != tt.expired {
+				t.Errorf("Expired() = %v, want %v", got, tt.expired)
+			}
+			want := CertOK
+			switch {
+			case tt.expired:
+				want = CertExpired
+			case tt.expiring:
+				want = CertExpiring
+			}
+			if got := c.Issue(now, 14*24*time.Hour); got != want {
+				t.Errorf("Issue() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestScanner_DiscoverEndpointsInspectsCerts(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	s := NewScanner(WithHost("127.0.0.1"), WithPorts([]int{extractPort(srv)}))
+	if err := s.SetTLS(TLSOptions{CAFile: writeServerCA(t, srv)}); err != nil {
+		t.Fatalf("SetTLS() error = %v", err)
+	}
+	eps := s.DiscoverEndpoints(context.Background())
+	if len(eps) == 0 {
+		t.Fatal("DiscoverEndpoints() found nothing")
+	}
+	for _, ep := range eps {
+		if ep.IsHTTPS && (ep.Cert == nil || !slices.Contains(ep.Cert.SANs, "example.com")) {
+			t.Errorf("%s: Cert = %+v, want the server certificate", ep.URL, ep.Cert)
+		}
+	}
+
+	plain, _ := NewEndpoint("http://127.0.0.1:1/")
+	if certs := InspectEndpoints(context.Background(), []*Endpoint{plain}, time.Second); len(certs) != 0 {
+		t.Errorf("InspectEndpoints(http) = %v, want nothing", certs)
+	}
+}
@@ -0,0 +1,212 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CheckAssertions struct {
+	Statuses     []int
+	MaxLatency   time.Duration
+	BodyContains string
+}
+
+type CheckResult struct {
+	Name        string
+	URL         string
+	Status      EndpointStatus
+	Requests    int
+	Failed      int
+	MinLatency  time.Duration
+	AvgLatency  time.Duration
+	MaxLatency  time.Duration
+	StatusCodes map[int]int
+	LastCode    int
+	Last        Observation
+	Failures    []string
+	Duration    time.Duration
+}
+
+type checkOptions struct {
+	count      int
+	timeout    time.Duration
+	assertions CheckAssertions
+}
+
+type CheckOption func(*checkOptions)
+
+func WithCheckCount(count int) CheckOption {
+	return func(o *checkOptions) {
+		if count > 0 {
+			o.count = count
+		}
+	}
+}
+
+func WithCheckTimeout(timeout time.Duration) CheckOption {
+	return func(o *checkOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+func WithAssertions(a CheckAssertions) CheckOption {
+	return func(o *checkOptions) {
+		o.assertions = a
+	}
+}
+
+func CheckEndpoints(ctx context.Context, endpoints []*Endpoint, opts ...CheckOption) []CheckResult {
+	options := checkOptions{count: 1, timeout: 5 * time.Second}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	client := &http.Client{}
+	results := make([]CheckResult, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *Endpoint) {
+			defer wg.Done()
+			results[i] = checkEndpoint(ctx, client, ep, options)
+		}(i, ep)
+	}
+	wg.Wait()
+	return results
+}
+
+func checkEndpoint(ctx context.Context, client *http.Client, ep *Endpoint, options checkOptions) CheckResult {
+	result := CheckResult{
+		Name:        ep.Name,
+		URL:         ep.URL,
+		Status:      StatusUnknown,
+		StatusCodes: make(map[int]int),
+	}
+	start := time.Now()
+
+	policy := ep.EffectivePolicy()
+	if ep.Policy.Timeout <= 0 {
+		policy.Timeout = options.timeout
+	}
+
+	var total time.Duration
+	for i := 0; i < options.count; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+
+		code, proto, latency, err := checkRequest(ctx, ep.httpClient(client), ep, policy, options.assertions)
+		result.Last = observe(policy, latency, err)
+		result.Last.Proto = proto
+
+		result.Requests++
+		total += latency
+		if code > 0 {
+			result.StatusCodes[code]++
+			result.LastCode = code
+		}
+		if result.Requests == 1 || latency < result.MinLatency {
+			result.MinLatency = latency
+		}
+		if latency > result.MaxLatency {
+			result.MaxLatency = latency
+		}
+		if err != nil {
+			result.Failed++
+			result.Failures = append(result.Failures, err.Error())
+		}
+		result.Status = worseStatus(result.Status, result.Last.Status)
+	}
+
+	if result.Requests > 0 {
+		result.AvgLatency = total / time.Duration(result.Requests)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func checkRequest(ctx context.Context, client *http.Client, ep *Endpoint, policy CheckPolicy, a CheckAssertions) (int, string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+	start := time.Now()
+
+	if ep.IsWebSocket() {
+		latency, err := checkWebSocket(ctx, ep)
+		if err != nil {
+			return 0, "", latency, err
+		}
+		if a.MaxLatency > 0 && latency > a.MaxLatency {
+			return http.StatusSwitchingProtocols, ProtoWebSocket, latency, fmt.Errorf("connect time %s exceeds %s", latency.Round(time.Millisecond), a.MaxLatency)
+		}
+		return http.StatusSwitchingProtocols, ProtoWebSocket, latency, nil
+	}
+
+	req, err := newEndpointRequest(ctx, ep, 0)
+	if err != nil {
+		return 0, "", 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", time.Since(start), err
+	}
+	defer resp.Body.Close()
+	proto := resp.Proto
+
+	var body []byte
+	if a.BodyContains != "" {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+	latency := time.Since(start)
+
+	if len(a.Statuses) > 0 {
+		if !containsInt(a.Statuses, resp.StatusCode) {
+			return resp.StatusCode, proto, latency, fmt.Errorf("status %d, expected %s", resp.StatusCode, joinInts(a.Statuses))
+		}
+	} else if err := policy.CheckStatus(resp.StatusCode); err != nil {
+		return resp.StatusCode, proto, latency, err
+	}
+
+	if a.MaxLatency > 0 && latency > a.MaxLatency {
+		return resp.StatusCode, proto, latency, fmt.Errorf("latency %s exceeds %s", latency.Round(time.Millisecond), a.MaxLatency)
+	}
+	if a.BodyContains != "" && !strings.Contains(string(body), a.BodyContains) {
+		return resp.StatusCode, proto, latency, fmt.Errorf("body does not contain %q", a.BodyContains)
+	}
+	return resp.StatusCode, proto, latency, nil
+}
+
+func worseStatus(a, b EndpointStatus) EndpointStatus {
+	rank := map[EndpointStatus]int{StatusUnknown: 0, StatusHealthy: 1, StatusSlow: 2, StatusDown: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,137 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newCheckServer(t *testing.T, status int, body string) *Endpoint {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	ep, err := NewEndpoint(srv.URL)
+	if err != nil {
+		t.Fatalf("NewEndpoint() error = %v", err)
+	}
+	return ep
+}
+
+func TestCheckEndpoints_Healthy(t *testing.T) {
+	ep := newCheckServer(t, http.StatusOK, "ok")
+
+	results := CheckEndpoints(context.Background(), []*Endpoint{ep}, WithCheckCount(3))
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1", len(results))
+	}
+
+	r := results[0]
+	if r.Status != StatusHealthy {
+		t.Errorf("Status = %v, want %v (failures: %v)", r.Status, StatusHealthy, r.Failures)
+	}
+	if r.Requests != 3 || r.Failed != 0 {
+		t.Errorf("Requests/Failed = %d/%d, want 3/0", r.Requests, r.Failed)
+	}
+	if r.StatusCodes[200] != 3 {
+		t.Errorf("StatusCodes = %v, want 200×3", r.StatusCodes)
+	}
+	if r.MinLatency > r.AvgLatency || r.AvgLatency > r.MaxLatency {
+		t.Errorf("latencies out of order: min %v avg %v max %v", r.MinLatency, r.AvgLatency, r.MaxLatency)
+	}
+}
+
+func TestCheckEndpoints_ServerErrorIsDown(t *testing.T) {
+	ep := newCheckServer(t, http.StatusInternalServerError, "")
+
+	r := CheckEndpoints(context.Background(), []*Endpoint{ep})[0]
+	if r.Status != StatusDown {
+		t.Errorf("Status = %v, want %v", r.Status, StatusDown)
+	}
+	if r.Failed != 1 || len(r.Failures) != 1 {
+		t.Errorf("Failed = %d, Failures = %v, want one failure", r.Failed, r.Failures)
+	}
+	if r.LastCode != http.StatusInternalServerError {
+		t.Errorf("LastCode = %d, want 500", r.LastCode)
+	}
+}
+
+func TestCheckEndpoints_Assertions(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		assertions CheckAssertions
+		want       EndpointStatus
+		wantErr    string
+	}{
+		{"expected status", http.StatusNotFound, "", CheckAssertions{Statuses: []int{404}}, StatusHealthy, ""},
+		{"unexpected status", http.StatusOK, "", CheckAssertions{Statuses: []int{201, 204}}, StatusDown, "expected 201 or 204"},
+		{"body match", http.StatusOK, `{"status":"ok"}`, CheckAssertions{BodyContains: `"ok"`}, StatusHealthy, ""},
+		{"body mismatch", http.StatusOK, "degraded", CheckAssertions{BodyContains: "ok"}, StatusDown, "body does not contain"},
+		{"max latency", http.StatusOK, "", CheckAssertions{MaxLatency: time.Nanosecond}, StatusDown, "exceeds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := newCheckServer(t, tt.status, tt.body)
+
+			r := CheckEndpoints(context.Background(), []*Endpoint{ep}, WithAssertions(tt.assertions))[0]
+			if r.Status != tt.want {
+				t.Errorf("Status = %v, want %v (failures: %v)", r.Status, tt.want, r.Failures)
+			}
+			if tt.wantErr != "" && (len(r.Failures) == 0 || !strings.Contains(r.Failures[0], tt.wantErr)) {
+				t.Errorf("Failures = %v, want one containing %q", r.Failures, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckEndpoints_Unreachable(t *testing.T) {
+	ep, _ := NewEndpoint("http://127.0.0.1:1")
+
+	r := CheckEndpoints(context.Background(), []*Endpoint{ep}, WithCheckCount(2), WithCheckTimeout(time.Second))[0]
+	if r.Status != StatusDown {
+		t.Errorf("Status = %v, want %v", r.Status, StatusDown)
+	}
+	if r.Failed != 2 || len(r.StatusCodes) != 0 {
+		t.Errorf("Failed = %d, StatusCodes = %v, want 2 failures and no codes", r.Failed, r.StatusCodes)
+	}
+}
+
+func TestCheckEndpoints_Policy(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(slow.Close)
+
+	tests := []struct {
+		name   string
+		policy CheckPolicy
+		want   EndpointStatus
+	}{
+		{"4xx rejected by default", CheckPolicy{}, StatusDown},
+		{"accepted status", CheckPolicy{Accept: []StatusRange{{Min: 401, Max: 401}}}, StatusHealthy},
+		{"slow threshold", CheckPolicy{SlowThreshold: 10 * time.Millisecond, Accept: []StatusRange{{Min: 401, Max: 401}}}, StatusSlow},
+		{"timeout", CheckPolicy{Timeout: 10 * time.Millisecond, Accept: []StatusRange{{Min: 401, Max: 401}}}, StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, _ := NewEndpoint(slow.URL)
+			ep.Policy = tt.policy
+
+			r := CheckEndpoints(context.Background(), []*Endpoint{ep})[0]
+			if r.Status != tt.want {
+				t.Errorf("Status = %v, want %v (failures: %v)", r.Status, tt.want, r.Failures)
+			}
+		})
+	}
+}
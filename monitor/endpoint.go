@@ -1,7 +1,11 @@
 package monitor
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -16,19 +20,41 @@ const (
 )
 
 type Endpoint struct {
-	URL         string         `json:"url"`
-	Name        string         `json:"name"`
-	Status      EndpointStatus `json:"status"`
-	LastCheck   time.Time      `json:"last_check"`
-	LastLatency time.Duration  `json:"last_latency"`
-	IsHTTPS     bool           `json:"is_https"`
-	IsActive    bool           `json:"is_active"`
+	URL         string           `json:"url"`
+	Name        string           `json:"name"`
+	Status      EndpointStatus   `json:"status"`
+	LastCheck   time.Time        `json:"last_check"`
+	LastLatency time.Duration    `json:"last_latency"`
+	IsHTTPS     bool             `json:"is_https"`
+	IsActive    bool             `json:"is_active"`
+	Timeline    *Timeline        `json:"-"`
+	Policy      CheckPolicy      `json:"-"`
+	Scenario    *Scenario        `json:"-"`
+	Request     *RequestTemplate `json:"-"`
+	Auth        *Auth            `json:"-"`
+	TLS         TLSOptions       `json:"-"`
+	TLSError    string           `json:"tls_error,omitempty"`
+	Cert        *CertInfo        `json:"cert,omitempty"`
+	Socket      string           `json:"socket,omitempty"`
+	Protocol    Protocol         `json:"protocol,omitempty"`
+	Connections int              `json:"connections,omitempty"`
+	Proto       string           `json:"proto,omitempty"`
+	Streams     *StreamLimits    `json:"streams,omitempty"`
+	WebSocket   *WebSocketScript `json:"-"`
+
+	tlsConfig *tls.Config
+	transport http.RoundTripper
 }
 
 func NewEndpoint(rawURL string) (*Endpoint, error) {
-	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
+	if strings.HasPrefix(rawURL, "unix://") {
+		return newUnixEndpoint(rawURL)
+	}
+	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") &&
+		!strings.HasPrefix(rawURL, "ws://") && !strings.HasPrefix(rawURL, "wss://") {
 		rawURL = "http://" + rawURL
 	}
+	secure := strings.HasPrefix(rawURL, "https://") || strings.HasPrefix(rawURL, "wss://")
 
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
@@ -36,7 +62,7 @@ func NewEndpoint(rawURL string) (*Endpoint, error) {
 	}
 
 	if parsed.Port() == "" {
-		if parsed.Scheme == "https" {
+		if secure {
 			parsed.Host = parsed.Host + ":443"
 		} else {
 			parsed.Host = parsed.Host + ":80"
@@ -53,24 +79,171 @@ func NewEndpoint(rawURL string) (*Endpoint, error) {
 		Name:     name,
 		Status:   StatusUnknown,
 		IsActive: true,
-		IsHTTPS:  parsed.Scheme == "https",
+		IsHTTPS:  secure,
+		Timeline: NewTimeline(),
 	}, nil
 }
 
-func (e *Endpoint) DetermineStatus(latency time.Duration, err error) EndpointStatus {
+func newUnixEndpoint(rawURL string) (*Endpoint, error) {
+	socket, path, err := ParseUnixURL(rawURL)
 	if err != nil {
-		return StatusDown
+		return nil, err
+	}
+	name := filepath.Base(socket)
+	if path != "/" {
+		name += path
+	}
+	ep := &Endpoint{
+		URL:      UnixURL(socket, path),
+		Name:     name,
+		Status:   StatusUnknown,
+		IsActive: true,
+		Timeline: NewTimeline(),
+		Socket:   socket,
+	}
+	ep.resetTransport()
+	return ep, nil
+}
+
+func ScenarioURL(name string) string {
+	return "scenario:" + name
+}
+
+func NewScenarioEndpoint(s *Scenario) *Endpoint {
+	return &Endpoint{
+		URL:      ScenarioURL(s.Name),
+		Name:     s.Name,
+		Status:   StatusUnknown,
+		IsActive: true,
+		Timeline: NewTimeline(),
+		Scenario: s,
+		Auth:     s.Auth,
 	}
-	if latency > 500*time.Millisecond {
-		return StatusSlow
+}
+
+func (e *Endpoint) IsWebSocket() bool {
+	return strings.HasPrefix(e.URL, "ws://") || strings.HasPrefix(e.URL, "wss://")
+}
+
+func (e *Endpoint) EffectivePolicy() CheckPolicy {
+	return DefaultPolicy().Merge(e.Policy)
+}
+
+func (e *Endpoint) DetermineStatus(latency time.Duration, err error) EndpointStatus {
+	return e.EffectivePolicy().Classify(latency, err)
+}
+
+type Observation struct {
+	Time     time.Time
+	Latency  time.Duration
+	Status   EndpointStatus
+	TLSError string
+	Proto    string
+}
+
+func observe(policy CheckPolicy, latency time.Duration, err error) Observation {
+	return Observation{
+		Time:     time.Now(),
+		Latency:  latency,
+		Status:   policy.Classify(latency, err),
+		TLSError: UntrustedCert(err),
 	}
-	return StatusHealthy
 }
 
 func (e *Endpoint) Update(latency time.Duration, err error) {
-	e.LastCheck = time.Now()
-	e.LastLatency = latency
-	e.Status = e.DetermineStatus(latency, err)
+	e.Apply(observe(e.EffectivePolicy(), latency, err))
+}
+
+func (e *Endpoint) Apply(o Observation) {
+	if o.Time.IsZero() || o.Time.Before(e.LastCheck) {
+		return
+	}
+	e.LastCheck = o.Time
+	e.LastLatency = o.Latency
+	e.Status = o.Status
+	e.TLSError = o.TLSError
+	if o.Proto != "" {
+		e.Proto = o.Proto
+	}
+}
+
+func (e *Endpoint) settings() *Endpoint {
+	return &Endpoint{
+		URL:         e.URL,
+		Name:        e.Name,
+		IsHTTPS:     e.IsHTTPS,
+		Policy:      e.Policy,
+		Scenario:    e.Scenario,
+		Request:     e.Request,
+		Auth:        e.Auth,
+		TLS:         e.TLS,
+		Socket:      e.Socket,
+		Protocol:    e.Protocol,
+		Connections: e.Connections,
+		WebSocket:   e.WebSocket,
+		tlsConfig:   e.tlsConfig,
+	}
+}
+
+func (e *Endpoint) SetTLS(opts TLSOptions) error {
+	if opts == e.TLS && (opts.IsZero() || e.tlsConfig != nil) {
+		return nil
+	}
+	var cfg *tls.Config
+	if !opts.IsZero() {
+		var err error
+		if cfg, err = opts.Config(); err != nil {
+			return err
+		}
+	}
+	e.TLS, e.tlsConfig = opts, cfg
+	e.resetTransport()
+	return nil
+}
+
+func (e *Endpoint) SetProtocol(p Protocol, connections int) error {
+	if err := p.Check(e.URL); err != nil {
+		return err
+	}
+	if connections < 0 {
+		return fmt.Errorf("connections must not be negative")
+	}
+	if p == ProtocolAuto {
+		p = ""
+	}
+	if p == e.Protocol && connections == e.Connections {
+		return nil
+	}
+	e.Protocol, e.Connections = p, connections
+	e.Streams = nil
+	e.resetTransport()
+	return nil
+}
+
+func (e *Endpoint) resetTransport() {
+	if t, ok := e.transport.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+	if e.Socket == "" && e.tlsConfig == nil && e.Protocol == "" {
+		e.transport = nil
+		return
+	}
+	transport := newTLSTransport(e.tlsConfig)
+	e.Protocol.apply(transport)
+	if e.Socket != "" {
+		e.transport = newUnixTransport(transport)
+	} else {
+		e.transport = transport
+	}
+}
+
+func (e *Endpoint) httpClient(base *http.Client) *http.Client {
+	if e.transport == nil {
+		return base
+	}
+	client := *base
+	client.Transport = e.transport
+	return &client
 }
 
 func (e *Endpoint) StatusIcon() string {
@@ -85,3 +258,16 @@ func (e *Endpoint) StatusIcon() string {
 		return "⚪"
 	}
 }
+
+func (e *Endpoint) StatusLabel() string {
+	switch e.Status {
+	case StatusHealthy:
+		return "UP  "
+	case StatusSlow:
+		return "SLOW"
+	case StatusDown:
+		return "DOWN"
+	default:
+		return "--  "
+	}
+}
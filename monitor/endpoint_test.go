@@ -151,6 +151,27 @@ func TestEndpoint_StatusIcon(t *testing.T) {
 	}
 }
 
+func TestEndpoint_StatusLabel(t *testing.T) {
+	tests := []struct {
+		status EndpointStatus
+		want   string
+	}{
+		{StatusHealthy, "UP  "},
+		{StatusSlow, "SLOW"},
+		{StatusDown, "DOWN"},
+		{StatusUnknown, "--  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			ep := &Endpoint{Status: tt.status}
+			if got := ep.StatusLabel(); got != tt.want {
+				t.Errorf("StatusLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 var assertAnError = error(&testError{})
 
 type testError struct{}
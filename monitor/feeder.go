@@ -0,0 +1,155 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type FeedMode string
+
+const (
+	FeedSequential FeedMode = "sequential"
+	FeedRandom     FeedMode = "random"
+	FeedUnique     FeedMode = "unique"
+)
+
+type Feeder struct {
+	mu sync.Mutex
+
+	name string
+	rows []map[string]string
+	mode FeedMode
+	next int
+}
+
+func NewFeeder(name string, rows []map[string]string, mode FeedMode) (*Feeder, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("feeder %s has no rows", name)
+	}
+	switch mode {
+	case "":
+		mode = FeedSequential
+	case FeedSequential, FeedRandom, FeedUnique:
+	default:
+		return nil, fmt.Errorf("unknown feed mode %q, want sequential, random or unique", mode)
+	}
+	return &Feeder{name: name, rows: rows, mode: mode}, nil
+}
+
+func LoadFeeder(name, path string, mode FeedMode) (*Feeder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = parseCSVRows(data)
+	case ".json":
+		rows, err = parseJSONRows(data)
+	default:
+		return nil, fmt.Errorf("%s: feeder files must be .csv or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewFeeder(name, rows, mode)
+}
+
+func parseCSVRows(data []byte) ([]map[string]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			row[strings.TrimSpace(name)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONRows(data []byte) ([]map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var items []map[string]any
+	if err := dec.Decode(&items); err != nil {
+		return nil, fmt.Errorf("want an array of objects: %v", err)
+	}
+
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string, len(item))
+		for key, value := range item {
+			switch v := value.(type) {
+			case string:
+				row[key] = v
+			case json.Number:
+				row[key] = v.String()
+			case nil:
+				row[key] = ""
+			default:
+				encoded, _ := json.Marshal(v)
+				row[key] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (f *Feeder) Name() string {
+	return f.name
+}
+
+func (f *Feeder) Len() int {
+	return len(f.rows)
+}
+
+func (f *Feeder) Mode() FeedMode {
+	return f.mode
+}
+
+func (f *Feeder) Next(vu int) (map[string]string, error) {
+	switch f.mode {
+	case FeedRandom:
+		return f.rows[rand.IntN(len(f.rows))], nil
+	case FeedUnique:
+		if vu < 0 || vu >= len(f.rows) {
+			return nil, fmt.Errorf("feeder %s has %d rows, not enough for worker %d", f.name, len(f.rows), vu+1)
+		}
+		return f.rows[vu], nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	row := f.rows[f.next]
+	f.next = (f.next + 1) % len(f.rows)
+	return row, nil
+}
+
+func (f *Feeder) Equal(other *Feeder) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return f.name == other.name && f.mode == other.mode &&
+		slices.EqualFunc(f.rows, other.rows, maps.Equal[map[string]string])
+}
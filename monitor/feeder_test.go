@@ -0,0 +1,99 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFeeder_Formats(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "users.csv")
+	jsonPath := filepath.Join(dir, "users.json")
+	os.WriteFile(csvPath, []byte("id,name\n1,ada\n2,\"grace, h\"\n"), 0600)
+	os.WriteFile(jsonPath, []byte(`[{"id": 1, "name": "ada", "tags": ["x"]}, {"id": 2, "name": null}]`), 0600)
+
+	for _, path := range []string{csvPath, jsonPath} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			f, err := LoadFeeder("users", path, FeedSequential)
+			if err != nil {
+				t.Fatalf("LoadFeeder() error = %v", err)
+			}
+			if f.Len() != 2 {
+				t.Fatalf("Len() = %d, want 2", f.Len())
+			}
+			row, _ := f.Next(0)
+			if row["id"] != "1" || row["name"] != "ada" {
+				t.Errorf("first row = %v", row)
+			}
+		})
+	}
+
+	f, _ := LoadFeeder("users", jsonPath, "")
+	if row, _ := f.Next(0); row["tags"] != `["x"]` {
+		t.Errorf("tags = %q, want the JSON-encoded list", row["tags"])
+	}
+	if _, err := LoadFeeder("users", filepath.Join(dir, "users.txt"), ""); err == nil {
+		t.Error("unsupported extension: expected error")
+	}
+}
+
+func TestFeeder_Modes(t *testing.T) {
+	rows := []map[string]string{{"id": "a"}, {"id": "b"}, {"id": "c"}}
+
+	seq, _ := NewFeeder("ids", rows, FeedSequential)
+	var got []string
+	for i := 0; i < 4; i++ {
+		row, _ := seq.Next(i)
+		got = append(got, row["id"])
+	}
+	if want := []string{"a", "b", "c", "a"}; !equalStrings(got, want) {
+		t.Errorf("sequential = %v, want %v", got, want)
+	}
+
+	unique, _ := NewFeeder("ids", rows, FeedUnique)
+	for vu, want := range []string{"a", "b", "c"} {
+		for i := 0; i < 2; i++ {
+			if row, _ := unique.Next(vu); row["id"] != want {
+				t.Errorf("unique worker %d got %s, want %s", vu, row["id"], want)
+			}
+		}
+	}
+	if _, err := unique.Next(3); err == nil {
+		t.Error("unique feeder with more workers than rows: expected error")
+	}
+
+	random, _ := NewFeeder("ids", rows, FeedRandom)
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		row, _ := random.Next(0)
+		seen[row["id"]] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("random feeder returned %v in 200 draws, want all three rows", seen)
+	}
+
+	reloaded, _ := NewFeeder("ids", []map[string]string{{"id": "a"}, {"id": "b"}, {"id": "c"}}, FeedSequential)
+	if !seq.Equal(reloaded) || seq.Equal(unique) || seq.Equal(nil) || !(*Feeder)(nil).Equal(nil) {
+		t.Error("Equal() should compare name, mode and rows")
+	}
+
+	if _, err := NewFeeder("ids", rows, "shuffle"); err == nil {
+		t.Error("unknown mode: expected error")
+	}
+	if _, err := NewFeeder("ids", nil, FeedRandom); err == nil {
+		t.Error("no rows: expected error")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
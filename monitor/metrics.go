@@ -11,9 +11,12 @@ type RequestResult struct {
 	Timestamp    time.Time
 	Latency      time.Duration
 	StatusCode   int
+	Protocol     string
 	Size         int64
 	IsError      bool
 	ErrorMessage string
+	ConnectTime  time.Duration
+	Disconnected bool
 }
 
 type Metrics struct {
@@ -24,6 +27,10 @@ type Metrics struct {
 	TotalBytes    int64
 	TotalLatency  time.Duration
 
+	TotalConnects    int64
+	TotalConnectTime time.Duration
+	TotalDisconnects int64
+
 	RecentResults    []RequestResult
 	maxRecentResults int
 
@@ -58,9 +65,17 @@ func (m *Metrics) Record(result RequestResult) {
 
 	if result.IsError {
 		m.TotalErrors++
-	} else {
+	}
+	if result.StatusCode > 0 {
 		m.StatusCodeCounts[result.StatusCode]++
-		m.TotalBytes += result.Size
+	}
+	m.TotalBytes += result.Size
+	if result.ConnectTime > 0 {
+		m.TotalConnects++
+		m.TotalConnectTime += result.ConnectTime
+	}
+	if result.Disconnected {
+		m.TotalDisconnects++
 	}
 
 	m.Latencies = append(m.Latencies, result.Latency)
@@ -82,6 +97,9 @@ func (m *Metrics) Reset() {
 	m.TotalErrors = 0
 	m.TotalBytes = 0
 	m.TotalLatency = 0
+	m.TotalConnects = 0
+	m.TotalConnectTime = 0
+	m.TotalDisconnects = 0
 	m.RecentResults = m.RecentResults[:0]
 	m.Latencies = m.Latencies[:0]
 	m.StatusCodeCounts = make(map[int]int64)
@@ -103,6 +121,9 @@ type Stats struct {
 	StatusCode2xx int64
 	StatusCode4xx int64
 	StatusCode5xx int64
+	AvgConnect    time.Duration
+	Connects      int64
+	Disconnects   int64
 }
 
 func (m *Metrics) GetStats() Stats {
@@ -112,6 +133,11 @@ func (m *Metrics) GetStats() Stats {
 	stats := Stats{
 		TotalRequests: m.TotalRequests,
 		TotalErrors:   m.TotalErrors,
+		Connects:      m.TotalConnects,
+		Disconnects:   m.TotalDisconnects,
+	}
+	if m.TotalConnects > 0 {
+		stats.AvgConnect = m.TotalConnectTime / time.Duration(m.TotalConnects)
 	}
 
 	if m.TotalRequests > 0 {
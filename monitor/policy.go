@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+func (r StatusRange) Contains(code int) bool {
+	return code >= r.Min && code <= r.Max
+}
+
+func (r StatusRange) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	if r.Min%100 == 0 && r.Max == r.Min+99 {
+		return strconv.Itoa(r.Min/100) + "xx"
+	}
+	return strconv.Itoa(r.Min) + "-" + strconv.Itoa(r.Max)
+}
+
+func ParseStatusRanges(spec string) ([]StatusRange, error) {
+	var ranges []StatusRange
+	for _, part := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == ' ' }) {
+		r, err := parseStatusRange(part)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no status codes in %q", spec)
+	}
+	return ranges, nil
+}
+
+func parseStatusRange(part string) (StatusRange, error) {
+	lower := strings.ToLower(part)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") && lower[0] >= '1' && lower[0] <= '5' {
+		class := int(lower[0]-'0') * 100
+		return StatusRange{Min: class, Max: class + 99}, nil
+	}
+
+	lo, hi, isRange := strings.Cut(part, "-")
+	min, err := parseStatusCode(lo)
+	if err != nil {
+		return StatusRange{}, err
+	}
+	max := min
+	if isRange {
+		if max, err = parseStatusCode(hi); err != nil {
+			return StatusRange{}, err
+		}
+		if max < min {
+			return StatusRange{}, fmt.Errorf("status range %q is reversed", part)
+		}
+	}
+	return StatusRange{Min: min, Max: max}, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", s)
+	}
+	return code, nil
+}
+
+func FormatStatusRanges(ranges []StatusRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+type CheckPolicy struct {
+	SlowThreshold time.Duration
+	DownThreshold time.Duration
+	Timeout       time.Duration
+	Accept        []StatusRange
+}
+
+func DefaultPolicy() CheckPolicy {
+	return CheckPolicy{
+		SlowThreshold: 500 * time.Millisecond,
+		Timeout:       5 * time.Second,
+		Accept:        []StatusRange{{Min: 200, Max: 399}},
+	}
+}
+
+func (p CheckPolicy) Merge(o CheckPolicy) CheckPolicy {
+	if o.SlowThreshold > 0 {
+		p.SlowThreshold = o.SlowThreshold
+	}
+	if o.DownThreshold > 0 {
+		p.DownThreshold = o.DownThreshold
+	}
+	if o.Timeout > 0 {
+		p.Timeout = o.Timeout
+	}
+	if len(o.Accept) > 0 {
+		p.Accept = o.Accept
+	}
+	return p
+}
+
+func (p CheckPolicy) Accepts(code int) bool {
+	for _, r := range p.Accept {
+		if r.Contains(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CheckPolicy) CheckStatus(code int) error {
+	if p.Accepts(code) {
+		return nil
+	}
+	return fmt.Errorf("status %d, expected %s", code, FormatStatusRanges(p.Accept))
+}
+
+func (p CheckPolicy) Classify(latency time.Duration, err error) EndpointStatus {
+	switch {
+	case err != nil:
+		return StatusDown
+	case p.DownThreshold > 0 && latency > p.DownThreshold:
+		return StatusDown
+	case latency > p.SlowThreshold:
+		return StatusSlow
+	}
+	return StatusHealthy
+}
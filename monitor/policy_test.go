@@ -0,0 +1,78 @@
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "200", want: "200"},
+		{spec: "200-299,304", want: "2xx,304"},
+		{spec: "2xx, 3XX 401", want: "2xx,3xx,401"},
+		{spec: "200-204", want: "200-204"},
+		{spec: "", wantErr: true},
+		{spec: "6xx", wantErr: true},
+		{spec: "299-200", wantErr: true},
+		{spec: "abc", wantErr: true},
+		{spec: "99", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			ranges, err := ParseStatusRanges(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStatusRanges(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if got := FormatStatusRanges(ranges); !tt.wantErr && got != tt.want {
+				t.Errorf("ParseStatusRanges(%q) = %s, want %s", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPolicy_Classify(t *testing.T) {
+	policy := CheckPolicy{SlowThreshold: 300 * time.Millisecond, DownThreshold: 2 * time.Second}
+
+	tests := []struct {
+		name    string
+		latency time.Duration
+		err     error
+		want    EndpointStatus
+	}{
+		{"within budget", 200 * time.Millisecond, nil, StatusHealthy},
+		{"over slow threshold", 400 * time.Millisecond, nil, StatusSlow},
+		{"over down threshold", 3 * time.Second, nil, StatusDown},
+		{"error", 10 * time.Millisecond, errors.New("refused"), StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Classify(tt.latency, tt.err); got != tt.want {
+				t.Errorf("Classify(%v, %v) = %v, want %v", tt.latency, tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPolicy_MergeAndAccepts(t *testing.T) {
+	policy := DefaultPolicy().Merge(CheckPolicy{Timeout: 30 * time.Second})
+	if policy.SlowThreshold != 500*time.Millisecond || policy.Timeout != 30*time.Second {
+		t.Errorf("Merge() = %+v, want default slow threshold and 30s timeout", policy)
+	}
+	if !policy.Accepts(204) || !policy.Accepts(302) || policy.Accepts(404) || policy.Accepts(500) {
+		t.Errorf("default policy accepts %v, want 200-399", policy.Accept)
+	}
+	if err := policy.CheckStatus(404); err == nil || err.Error() != "status 404, expected 200-399" {
+		t.Errorf("CheckStatus(404) = %v", err)
+	}
+
+	ep := &Endpoint{Policy: CheckPolicy{SlowThreshold: 5 * time.Second}}
+	if got := ep.DetermineStatus(2*time.Second, nil); got != StatusHealthy {
+		t.Errorf("DetermineStatus(2s) with a 5s budget = %v, want healthy", got)
+	}
+}
@@ -0,0 +1,217 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+var ErrProcessExited = errors.New("process exited")
+
+type ProcessMetrics struct {
+	PID        int32
+	Name       string
+	Processes  int
+	CPUPercent float64
+	RSS        uint64
+	Threads    int32
+	FDs        int32
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadRate   float64
+	WriteRate  float64
+	SampledAt  time.Time
+}
+
+type ProcessMonitor struct {
+	mu sync.Mutex
+
+	pid   int32
+	name  string
+	procs map[int32]*process.Process
+
+	lastRead  uint64
+	lastWrite uint64
+	lastAt    time.Time
+}
+
+func NewProcessMonitor(pid int32) (*ProcessMonitor, error) {
+	root, err := process.NewProcess(pid)
+	if err != nil {
+		return nil, fmt.Errorf("process %d: %w", pid, err)
+	}
+	name, _ := root.Name()
+
+	return &ProcessMonitor{
+		pid:   pid,
+		name:  name,
+		procs: map[int32]*process.Process{pid: root},
+	}, nil
+}
+
+func ResolveProcess(spec string) (int32, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case spec == "":
+		return 0, errors.New("empty process spec")
+	case strings.HasPrefix(spec, ":"):
+		port, err := strconv.Atoi(spec[1:])
+		if err != nil || port < 1 || port > 65535 {
+			return 0, fmt.Errorf("invalid port %q", spec[1:])
+		}
+		return FindProcessByPort(port)
+	}
+
+	if pid, err := strconv.ParseInt(spec, 10, 32); err == nil {
+		if pid <= 0 {
+			return 0, fmt.Errorf("invalid pid %d", pid)
+		}
+		return int32(pid), nil
+	}
+	return FindProcessByName(spec)
+}
+
+func FindProcessByPort(port int) (int32, error) {
+	conns, err := psnet.Connections("tcp")
+	if err != nil {
+		return 0, fmt.Errorf("listing connections: %w", err)
+	}
+	for _, c := range conns {
+		if c.Status == "LISTEN" && int(c.Laddr.Port) == port && c.Pid > 0 {
+			return c.Pid, nil
+		}
+	}
+	return 0, fmt.Errorf("no process found listening on port %d", port)
+}
+
+func FindProcessByName(name string) (int32, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return 0, fmt.Errorf("listing processes: %w", err)
+	}
+
+	var best *process.Process
+	var bestCreated int64
+	for _, p := range procs {
+		pname, err := p.Name()
+		if err != nil || !strings.EqualFold(pname, name) {
+			continue
+		}
+		created, _ := p.CreateTime()
+		if best == nil || created < bestCreated {
+			best, bestCreated = p, created
+		}
+	}
+	if best == nil {
+		return 0, fmt.Errorf("no process named %q", name)
+	}
+	return best.Pid, nil
+}
+
+func (pm *ProcessMonitor) PID() int32 {
+	return pm.pid
+}
+
+func (pm *ProcessMonitor) Name() string {
+	return pm.name
+}
+
+func (pm *ProcessMonitor) Sample() (ProcessMetrics, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if running, err := pm.procs[pm.pid].IsRunning(); err != nil || !running {
+		return ProcessMetrics{PID: pm.pid, Name: pm.name}, ErrProcessExited
+	}
+
+	pm.refreshTree()
+
+	metrics := ProcessMetrics{
+		PID:       pm.pid,
+		Name:      pm.name,
+		Processes: len(pm.procs),
+		SampledAt: time.Now(),
+	}
+
+	for _, p := range pm.procs {
+		if cpu, err := p.Percent(0); err == nil {
+			metrics.CPUPercent += cpu
+		}
+		if mem, err := p.MemoryInfo(); err == nil {
+			metrics.RSS += mem.RSS
+		}
+		if threads, err := p.NumThreads(); err == nil {
+			metrics.Threads += threads
+		}
+		if fds, err := p.NumFDs(); err == nil {
+			metrics.FDs += fds
+		}
+		if io, err := p.IOCounters(); err == nil {
+			metrics.ReadBytes += io.ReadBytes
+			metrics.WriteBytes += io.WriteBytes
+		}
+	}
+
+	if !pm.lastAt.IsZero() {
+		elapsed := metrics.SampledAt.Sub(pm.lastAt).Seconds()
+		if elapsed > 0 {
+			metrics.ReadRate = rate(metrics.ReadBytes, pm.lastRead, elapsed)
+			metrics.WriteRate = rate(metrics.WriteBytes, pm.lastWrite, elapsed)
+		}
+	}
+	pm.lastRead, pm.lastWrite, pm.lastAt = metrics.ReadBytes, metrics.WriteBytes, metrics.SampledAt
+
+	return metrics, nil
+}
+
+func (pm *ProcessMonitor) refreshTree() {
+	pids, err := process.Pids()
+	if err != nil {
+		return
+	}
+
+	children := make(map[int32][]int32)
+	for _, pid := range pids {
+		p, ok := pm.procs[pid]
+		if !ok {
+			p = &process.Process{Pid: pid}
+		}
+		ppid, err := p.Ppid()
+		if err != nil {
+			continue
+		}
+		children[ppid] = append(children[ppid], pid)
+	}
+
+	tree := map[int32]*process.Process{pm.pid: pm.procs[pm.pid]}
+	queue := []int32{pm.pid}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[pid] {
+			if _, seen := tree[child]; seen {
+				continue
+			}
+			p, ok := pm.procs[child]
+			if !ok {
+				p = &process.Process{Pid: child}
+			}
+			tree[child] = p
+			queue = append(queue, child)
+		}
+	}
+	pm.procs = tree
+}
+
+func rate(current, previous uint64, seconds float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
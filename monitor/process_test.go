@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProcessMonitor_Sample(t *testing.T) {
+	pm, err := NewProcessMonitor(int32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("NewProcessMonitor() error = %v", err)
+	}
+
+	first, err := pm.Sample()
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if first.RSS == 0 {
+		t.Error("RSS should not be zero")
+	}
+	if first.Threads < 1 {
+		t.Errorf("Threads = %d, want at least 1", first.Threads)
+	}
+	if first.Processes < 1 {
+		t.Errorf("Processes = %d, want at least 1", first.Processes)
+	}
+}
+
+func TestProcessMonitor_IncludesChildren(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sleep")
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start child: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	pm, err := NewProcessMonitor(int32(os.Getpid()))
+	if err != nil {
+		t.Fatalf("NewProcessMonitor() error = %v", err)
+	}
+	metrics, err := pm.Sample()
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+	if metrics.Processes < 2 {
+		t.Errorf("Processes = %d, want the child to be included", metrics.Processes)
+	}
+}
+
+func TestProcessMonitor_Exited(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses true")
+	}
+
+	cmd := exec.Command("true")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start child: %v", err)
+	}
+	pm, err := NewProcessMonitor(int32(cmd.Process.Pid))
+	if err != nil {
+		t.Fatalf("NewProcessMonitor() error = %v", err)
+	}
+	cmd.Wait()
+	time.Sleep(10 * time.Millisecond)
+
+	if _, err := pm.Sample(); err != ErrProcessExited {
+		t.Errorf("Sample() error = %v, want ErrProcessExited", err)
+	}
+}
+
+func TestResolveProcess(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		spec    string
+		want    int32
+		wantErr bool
+	}{
+		{"1234", 1234, false},
+		{":" + strconv.Itoa(port), int32(os.Getpid()), false},
+		{":99999", 0, true},
+		{"", 0, true},
+		{"-5", 0, true},
+		{"no-such-process-name", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveProcess(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolveProcess(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ResolveProcess(%q) = %d, want %d", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Protocol string
+
+const (
+	ProtocolAuto  Protocol = "auto"
+	ProtocolHTTP1 Protocol = "http1"
+	ProtocolH2    Protocol = "h2"
+	ProtocolH2C   Protocol = "h2c"
+)
+
+func ParseProtocol(s string) (Protocol, error) {
+	switch p := Protocol(strings.ToLower(strings.TrimSpace(s))); p {
+	case "", ProtocolAuto:
+		return ProtocolAuto, nil
+	case ProtocolHTTP1, ProtocolH2, ProtocolH2C:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown protocol %q, want auto, http1, h2 or h2c", s)
+}
+
+func (p Protocol) Check(rawURL string) error {
+	secure := strings.HasPrefix(rawURL, "https://")
+	switch {
+	case p == ProtocolH2 && !secure:
+		return fmt.Errorf("h2 needs an https URL; use h2c for cleartext HTTP/2")
+	case p == ProtocolH2C && secure:
+		return fmt.Errorf("h2c is cleartext HTTP/2; use h2 for https URLs")
+	}
+	return nil
+}
+
+func (p Protocol) apply(t *http.Transport) {
+	var protocols http.Protocols
+	switch p {
+	case ProtocolHTTP1:
+		protocols.SetHTTP1(true)
+	case ProtocolH2:
+		protocols.SetHTTP2(true)
+	case ProtocolH2C:
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	}
+	t.Protocols = &protocols
+}
+
+func ProtoName(proto string) string {
+	switch proto {
+	case "HTTP/2.0":
+		return "h2"
+	case "HTTP/1.1":
+		return "http/1.1"
+	case "HTTP/1.0":
+		return "http/1.0"
+	}
+	return strings.ToLower(proto)
+}
+
+type StreamLimits struct {
+	MaxConcurrentStreams uint32
+	InitialWindowSize    uint32
+	MaxFrameSize         uint32
+	Limited              bool
+}
+
+var ErrNoHTTP2 = errors.New("server does not speak HTTP/2")
+
+const (
+	http2Preface       = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+	http2FrameSettings = 0x4
+	http2FlagAck       = 0x1
+)
+
+func ProbeStreams(ctx context.Context, ep *Endpoint) (StreamLimits, error) {
+	var limits StreamLimits
+	if err := ep.Protocol.Check(ep.URL); err != nil {
+		return limits, err
+	}
+	if ep.Protocol == ProtocolHTTP1 || (ep.Protocol != ProtocolH2C && !ep.IsHTTPS) || ep.IsWebSocket() {
+		return limits, ErrNoHTTP2
+	}
+
+	conn, err := dialHTTP2(ctx, ep)
+	if err != nil {
+		return limits, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+	}
+
+	if _, err := io.WriteString(conn, http2Preface+"\x00\x00\x00\x04\x00\x00\x00\x00\x00"); err != nil {
+		return limits, err
+	}
+	for {
+		var header [9]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return limits, fmt.Errorf("%w: %v", ErrNoHTTP2, err)
+		}
+		length := uint32(header[0])<<16 | uint32(header[1])<<8 | uint32(header[2])
+		if length > 1<<20 {
+			return limits, ErrNoHTTP2
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return limits, err
+		}
+		if header[3] != http2FrameSettings || header[4]&http2FlagAck != 0 {
+			continue
+		}
+		for i := 0; i+6 <= len(payload); i += 6 {
+			value := binary.BigEndian.Uint32(payload[i+2:])
+			switch binary.BigEndian.Uint16(payload[i:]) {
+			case 0x3:
+				limits.MaxConcurrentStreams, limits.Limited = value, true
+			case 0x4:
+				limits.InitialWindowSize = value
+			case 0x5:
+				limits.MaxFrameSize = value
+			}
+		}
+		return limits, nil
+	}
+}
+
+func dialHTTP2(ctx context.Context, ep *Endpoint) (net.Conn, error) {
+	var dialer net.Dialer
+	if ep.Socket != "" {
+		return dialer.DialContext(ctx, "unix", ep.Socket)
+	}
+	u, err := url.Parse(ep.URL)
+	if err != nil {
+		return nil, err
+	}
+	if !ep.IsHTTPS {
+		return dialer.DialContext(ctx, "tcp", u.Host)
+	}
+
+	cfg := &tls.Config{}
+	if ep.tlsConfig != nil {
+		cfg = ep.tlsConfig.Clone()
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = u.Hostname()
+	}
+	cfg.NextProtos = []string{"h2"}
+	conn, err := (&tls.Dialer{Config: cfg}).DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	if conn.(*tls.Conn).ConnectionState().NegotiatedProtocol != "h2" {
+		conn.Close()
+		return nil, ErrNoHTTP2
+	}
+	return conn, nil
+}
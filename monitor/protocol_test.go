@@ -0,0 +1,222 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newHTTP2Server(t *testing.T, tls bool, streams int, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: streams}
+	if tls {
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+	} else {
+		srv.Config.Protocols = new(http.Protocols)
+		srv.Config.Protocols.SetHTTP1(true)
+		srv.Config.Protocols.SetUnencryptedHTTP2(true)
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestParseProtocol(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Protocol
+		wantErr bool
+	}{
+		{"", ProtocolAuto, false},
+		{"H2C", ProtocolH2C, false},
+		{" http1 ", ProtocolHTTP1, false},
+		{"http3", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseProtocol(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseProtocol(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+
+	ep, _ := NewEndpoint("http://localhost:3000/")
+	if err := ep.SetProtocol(ProtocolH2, 0); err == nil || !strings.Contains(err.Error(), "https") {
+		t.Errorf("SetProtocol(h2) on http URL error = %v", err)
+	}
+	secure, _ := NewEndpoint("https://localhost:8443/")
+	if err := secure.SetProtocol(ProtocolH2C, 0); err == nil {
+		t.Error("SetProtocol(h2c) on https URL: expected error")
+	}
+}
+
+func TestLoadTester_Protocols(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) { w.Header().Set("X-Proto", r.Proto) }
+	tlsSrv := newHTTP2Server(t, true, 0, handler)
+	plainSrv := newHTTP2Server(t, false, 0, handler)
+
+	tests := []struct {
+		name     string
+		srv      *httptest.Server
+		protocol Protocol
+		want     string
+	}{
+		{"auto over TLS negotiates h2", tlsSrv, ProtocolAuto, "HTTP/2.0"},
+		{"http1 over TLS", tlsSrv, ProtocolHTTP1, "HTTP/1.1"},
+		{"h2", tlsSrv, ProtocolH2, "HTTP/2.0"},
+		{"auto cleartext", plainSrv, ProtocolAuto, "HTTP/1.1"},
+		{"h2c", plainSrv, ProtocolH2C, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, _ := NewEndpoint(tt.srv.URL)
+			if err := ep.SetTLS(TLSOptions{CAFile: caFor(t, tt.srv)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ep.SetProtocol(tt.protocol, 0); err != nil {
+				t.Fatal(err)
+			}
+			metrics := NewMetrics(100)
+			lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+			lt.Start()
+			lt.SendBurst(4)
+			time.Sleep(200 * time.Millisecond)
+			lt.Stop()
+
+			results := metrics.GetRecentResults(4)
+			if len(results) != 4 {
+				t.Fatalf("%d results, want 4", len(results))
+			}
+			for _, r := range results {
+				if r.IsError || r.Protocol != tt.want {
+					t.Errorf("result protocol %q (error %q), want %s", r.Protocol, r.ErrorMessage, tt.want)
+				}
+			}
+			if obs, ok := lt.Observation(); !ok || obs.Proto != tt.want {
+				t.Errorf("observed Proto = %q, want %s", obs.Proto, tt.want)
+			}
+
+			last := CheckEndpoints(context.Background(), []*Endpoint{ep})[0].Last
+			if last.Status != StatusHealthy || last.Proto != tt.want {
+				t.Errorf("health check status %v over %q, want healthy over %s", last.Status, last.Proto, tt.want)
+			}
+		})
+	}
+}
+
+func caFor(t *testing.T, srv *httptest.Server) string {
+	if srv.TLS == nil {
+		return ""
+	}
+	return writeServerCA(t, srv)
+}
+
+func TestLoadTester_Connections(t *testing.T) {
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+	srv := newHTTP2Server(t, false, 0, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	ep, _ := NewEndpoint(srv.URL)
+	if err := ep.SetProtocol(ProtocolH2C, 2); err != nil {
+		t.Fatal(err)
+	}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(8))
+	lt.Start()
+	lt.SendBurst(32)
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	if got := metrics.GetStats(); got.TotalRequests != 32 || got.TotalErrors != 0 {
+		t.Errorf("requests = %d, errors = %d, want 32 clean requests", got.TotalRequests, got.TotalErrors)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) < 1 || len(conns) > 2 {
+		t.Errorf("%d connections, want at most 2 multiplexed connections", len(conns))
+	}
+}
+
+func TestProbeStreams(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	tlsSrv := newHTTP2Server(t, true, 7, handler)
+	plainSrv := newHTTP2Server(t, false, 12, handler)
+	http1Srv := httptest.NewServer(http.HandlerFunc(handler))
+	defer http1Srv.Close()
+
+	secure, _ := NewEndpoint(tlsSrv.URL)
+	secure.SetTLS(TLSOptions{CAFile: writeServerCA(t, tlsSrv)})
+	if limits, err := ProbeStreams(context.Background(), secure); err != nil || !limits.Limited || limits.MaxConcurrentStreams != 7 {
+		t.Errorf("ProbeStreams(h2) = %+v, %v, want 7 streams", limits, err)
+	}
+
+	cleartext, _ := NewEndpoint(plainSrv.URL)
+	cleartext.SetProtocol(ProtocolH2C, 0)
+	if limits, err := ProbeStreams(context.Background(), cleartext); err != nil || limits.MaxConcurrentStreams != 12 {
+		t.Errorf("ProbeStreams(h2c) = %+v, %v, want 12 streams", limits, err)
+	}
+
+	for _, url := range []string{plainSrv.URL, http1Srv.URL} {
+		ep, _ := NewEndpoint(url)
+		if _, err := ProbeStreams(context.Background(), ep); !errors.Is(err, ErrNoHTTP2) {
+			t.Errorf("ProbeStreams(%s) error = %v, want ErrNoHTTP2", url, err)
+		}
+	}
+	ep, _ := NewEndpoint(http1Srv.URL)
+	ep.SetProtocol(ProtocolH2C, 0)
+	if _, err := ProbeStreams(context.Background(), ep); !errors.Is(err, ErrNoHTTP2) {
+		t.Errorf("ProbeStreams(http1 server as h2c) error = %v, want ErrNoHTTP2", err)
+	}
+}
+
+func TestLoadGenerator_ReloadProtocol(t *testing.T) {
+	srv := newHTTP2Server(t, false, 0, func(w http.ResponseWriter, r *http.Request) {})
+
+	ep, _ := NewEndpoint(srv.URL)
+	metrics := NewMetrics(100)
+	lg := NewLoadGenerator(WithConcurrency(2))
+	lg.AddTester(ep, metrics)
+	run := func() string {
+		lg.Start(100)
+		time.Sleep(100 * time.Millisecond)
+		lg.Stop()
+		results := metrics.GetRecentResults(1)
+		if len(results) == 0 {
+			t.Fatal("no requests sent")
+		}
+		return results[0].Protocol
+	}
+
+	if got := run(); got != "HTTP/1.1" {
+		t.Fatalf("before reload protocol = %q, want HTTP/1.1", got)
+	}
+	if err := ep.SetProtocol(ProtocolH2C, 1); err != nil {
+		t.Fatal(err)
+	}
+	lg.AddTester(ep, metrics)
+	if got := run(); got != "HTTP/2.0" {
+		t.Errorf("after AddTester protocol = %q, want HTTP/2.0", got)
+	}
+
+	if err := ep.SetProtocol(ProtocolHTTP1, 0); err != nil {
+		t.Fatal(err)
+	}
+	lg.Start(100)
+	lg.Reload(ep.URL)
+	time.Sleep(100 * time.Millisecond)
+	lg.Stop()
+	if results := metrics.GetRecentResults(1); results[0].Protocol != "HTTP/1.1" {
+		t.Errorf("after Reload while running protocol = %q, want HTTP/1.1", results[0].Protocol)
+	}
+}
@@ -0,0 +1,416 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OutputLine struct {
+	Time   time.Time
+	Stream string
+	Text   string
+}
+
+type ServerEventKind string
+
+const (
+	ServerStarted    ServerEventKind = "started"
+	ServerReady      ServerEventKind = "ready"
+	ServerCrashed    ServerEventKind = "crashed"
+	ServerRestarting ServerEventKind = "restarting"
+	ServerStopped    ServerEventKind = "stopped"
+)
+
+type ServerEvent struct {
+	Time    time.Time
+	Kind    ServerEventKind
+	PID     int
+	Message string
+}
+
+type ServerRunner struct {
+	mu sync.Mutex
+
+	command     []string
+	dir         string
+	env         []string
+	host        string
+	port        int
+	restart     bool
+	stopTimeout time.Duration
+	maxPending  int
+
+	cmd       *exec.Cmd
+	running   bool
+	started   bool
+	stopping  bool
+	restartRq bool
+	restarts  int
+	crashes   int
+	lastStart time.Time
+
+	output  []OutputLine
+	dropped int64
+	events  []ServerEvent
+
+	stopCh chan struct{}
+	done   chan struct{}
+}
+
+type ServerRunnerOption func(*ServerRunner)
+
+func WithServerPort(host string, port int) ServerRunnerOption {
+	return func(r *ServerRunner) {
+		r.host = host
+		r.port = port
+	}
+}
+
+func WithServerDir(dir string) ServerRunnerOption {
+	return func(r *ServerRunner) {
+		r.dir = dir
+	}
+}
+
+func WithServerEnv(env []string) ServerRunnerOption {
+	return func(r *ServerRunner) {
+		r.env = env
+	}
+}
+
+func WithAutoRestart(restart bool) ServerRunnerOption {
+	return func(r *ServerRunner) {
+		r.restart = restart
+	}
+}
+
+func WithStopTimeout(timeout time.Duration) ServerRunnerOption {
+	return func(r *ServerRunner) {
+		r.stopTimeout = timeout
+	}
+}
+
+func NewServerRunner(command []string, opts ...ServerRunnerOption) *ServerRunner {
+	r := &ServerRunner{
+		command:     command,
+		host:        "localhost",
+		restart:     true,
+		stopTimeout: 5 * time.Second,
+		maxPending:  1000,
+		stopCh:      make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *ServerRunner) Command() string {
+	return strings.Join(r.command, " ")
+}
+
+func (r *ServerRunner) Port() int {
+	return r.port
+}
+
+func (r *ServerRunner) Start() error {
+	if len(r.command) == 0 {
+		return errors.New("no command to run")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return errors.New("server already started")
+	}
+	if err := r.spawn(); err != nil {
+		return err
+	}
+	r.started = true
+	go r.supervise()
+	return nil
+}
+
+func (r *ServerRunner) spawn() error {
+	cmd := exec.Command(r.command[0], r.command[1:]...)
+	cmd.Dir = r.dir
+	if r.env != nil {
+		cmd.Env = r.env
+	}
+	setProcessGroup(cmd)
+
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return err
+	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdoutR.Close()
+		stderrR.Close()
+		return fmt.Errorf("starting %s: %w", r.command[0], err)
+	}
+
+	go r.capture("stdout", stdoutR)
+	go r.capture("stderr", stderrR)
+
+	r.cmd = cmd
+	r.running = true
+	r.lastStart = time.Now()
+	r.event(ServerStarted, cmd.Process.Pid, "started "+r.Command())
+	return nil
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+func (r *ServerRunner) capture(stream string, rd io.ReadCloser) {
+	defer rd.Close()
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := strings.TrimRight(ansiPattern.ReplaceAllString(scanner.Text(), ""), "\r")
+
+		r.mu.Lock()
+		r.output = append(r.output, OutputLine{Time: time.Now(), Stream: stream, Text: text})
+		if len(r.output) > r.maxPending {
+			over := len(r.output) - r.maxPending
+			r.output = r.output[over:]
+			r.dropped += int64(over)
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *ServerRunner) supervise() {
+	defer close(r.done)
+
+	backoff := time.Second
+	for {
+		r.mu.Lock()
+		cmd := r.cmd
+		r.mu.Unlock()
+
+		err := cmd.Wait()
+
+		r.mu.Lock()
+		r.running = false
+		pid := cmd.Process.Pid
+		uptime := time.Since(r.lastStart)
+
+		if r.stopping {
+			r.event(ServerStopped, pid, "stopped")
+			r.mu.Unlock()
+			return
+		}
+
+		delay := time.Duration(0)
+		if r.restartRq {
+			r.restartRq = false
+			r.event(ServerRestarting, pid, "restarting")
+		} else {
+			r.crashes++
+			r.event(ServerCrashed, pid, "exited unexpectedly: "+describeExit(err))
+			if !r.restart {
+				r.mu.Unlock()
+				return
+			}
+			if uptime > 30*time.Second {
+				backoff = time.Second
+			}
+			delay = backoff
+			backoff *= 2
+			if backoff > 30*time.Second {
+				backoff = 30 * time.Second
+			}
+			r.event(ServerRestarting, pid, "restarting in "+delay.String())
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-r.stopCh:
+			r.mu.Lock()
+			r.event(ServerStopped, pid, "stopped")
+			r.mu.Unlock()
+			return
+		}
+
+		r.mu.Lock()
+		if r.stopping {
+			r.event(ServerStopped, pid, "stopped")
+			r.mu.Unlock()
+			return
+		}
+		r.restarts++
+		if err := r.spawn(); err != nil {
+			r.event(ServerCrashed, 0, err.Error())
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Unlock()
+	}
+}
+
+func describeExit(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ProcessState.String()
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return "exit status 0"
+}
+
+func (r *ServerRunner) event(kind ServerEventKind, pid int, message string) {
+	r.events = append(r.events, ServerEvent{Time: time.Now(), Kind: kind, PID: pid, Message: message})
+}
+
+func (r *ServerRunner) WaitReady(ctx context.Context) error {
+	if r.port <= 0 {
+		return errors.New("no port to wait for")
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if CheckPort(r.host, r.port, 500*time.Millisecond) {
+			r.mu.Lock()
+			if r.running {
+				r.event(ServerReady, r.cmd.Process.Pid, fmt.Sprintf("accepting connections on port %d", r.port))
+				r.mu.Unlock()
+				return nil
+			}
+			r.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("port %d not ready: %w", r.port, ctx.Err())
+		case <-r.done:
+			return errors.New("server exited before accepting connections")
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ServerRunner) Restart() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started || r.stopping {
+		return errors.New("server is not running")
+	}
+	if !r.running {
+		return errors.New("server is already restarting")
+	}
+	r.restartRq = true
+	return terminateGroup(r.cmd.Process)
+}
+
+func (r *ServerRunner) Stop() error {
+	r.mu.Lock()
+	if !r.started {
+		r.mu.Unlock()
+		return nil
+	}
+	if !r.stopping {
+		r.stopping = true
+		close(r.stopCh)
+	}
+	cmd := r.cmd
+	running := r.running
+	r.mu.Unlock()
+
+	if running {
+		terminateGroup(cmd.Process)
+	}
+
+	select {
+	case <-r.done:
+		return nil
+	case <-time.After(r.stopTimeout):
+	}
+
+	if err := killGroup(cmd.Process); err != nil {
+		return err
+	}
+	<-r.done
+	return nil
+}
+
+func (r *ServerRunner) PID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running || r.cmd == nil || r.cmd.Process == nil {
+		return 0
+	}
+	return r.cmd.Process.Pid
+}
+
+func (r *ServerRunner) IsRunning() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running
+}
+
+func (r *ServerRunner) Restarts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.restarts
+}
+
+func (r *ServerRunner) Crashes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.crashes
+}
+
+func (r *ServerRunner) DrainOutput() []OutputLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.output) == 0 {
+		return nil
+	}
+	lines := r.output
+	r.output = make([]OutputLine, 0, len(lines))
+	return lines
+}
+
+func (r *ServerRunner) DrainEvents() []ServerEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func (r *ServerRunner) Done() <-chan struct{} {
+	return r.done
+}
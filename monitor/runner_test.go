@@ -0,0 +1,184 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+}
+
+func waitForEvent(t *testing.T, r *ServerRunner, kind ServerEventKind) []ServerEvent {
+	t.Helper()
+	var seen []ServerEvent
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		seen = append(seen, r.DrainEvents()...)
+		for _, ev := range seen {
+			if ev.Kind == kind {
+				return seen
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("no %s event, got %+v", kind, seen)
+	return nil
+}
+
+func TestServerRunner_CapturesOutput(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewServerRunner([]string{"sh", "-c", "echo hello; echo \"\x1b[31moops\x1b[0m\" >&2; sleep 5"})
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	var lines []OutputLine
+	deadline := time.Now().Add(3 * time.Second)
+	for len(lines) < 2 && time.Now().Before(deadline) {
+		lines = append(lines, r.DrainOutput()...)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	streams := map[string]string{}
+	for _, l := range lines {
+		streams[l.Stream] = l.Text
+	}
+	if streams["stdout"] != "hello" {
+		t.Errorf("stdout = %q, want hello", streams["stdout"])
+	}
+	if streams["stderr"] != "oops" {
+		t.Errorf("stderr = %q, want oops with colors stripped", streams["stderr"])
+	}
+}
+
+func TestServerRunner_WaitReadyAndStop(t *testing.T) {
+	skipOnWindows(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	r := NewServerRunner([]string{"sh", "-c", "sleep 30 & wait"}, WithServerPort("127.0.0.1", port))
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := r.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Stop() took %v, process group was not terminated", elapsed)
+	}
+	if r.IsRunning() {
+		t.Error("IsRunning() = true after Stop()")
+	}
+	if r.Crashes() != 0 {
+		t.Errorf("Crashes() = %d, a requested stop is not a crash", r.Crashes())
+	}
+}
+
+func TestServerRunner_Crash(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewServerRunner([]string{"sh", "-c", "exit 3"}, WithAutoRestart(false), WithServerPort("127.0.0.1", 1))
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	waitForEvent(t, r, ServerCrashed)
+	if r.Crashes() != 1 {
+		t.Errorf("Crashes() = %d, want 1", r.Crashes())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.WaitReady(ctx); err == nil {
+		t.Error("WaitReady() should fail once the server has exited")
+	}
+	r.Stop()
+}
+
+func TestServerRunner_WaitReadyIgnoresStaleServer(t *testing.T) {
+	skipOnWindows(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	r := NewServerRunner([]string{"sh", "-c", "exit 1"}, WithAutoRestart(false), WithServerPort("127.0.0.1", ln.Addr().(*net.TCPAddr).Port))
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+	waitForEvent(t, r, ServerCrashed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := r.WaitReady(ctx); err == nil {
+		t.Error("WaitReady() reported ready for a port held by another process after the server exited")
+	}
+}
+
+func TestKillGroup_AlreadyExited(t *testing.T) {
+	skipOnWindows(t)
+
+	cmd := exec.Command("true")
+	setProcessGroup(cmd)
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := killGroup(cmd.Process); err != nil {
+		t.Errorf("killGroup() on an exited process = %v, want nil", err)
+	}
+}
+
+func TestServerRunner_Restart(t *testing.T) {
+	skipOnWindows(t)
+
+	r := NewServerRunner([]string{"sleep", "30"})
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer r.Stop()
+
+	firstPID := r.PID()
+	if err := r.Restart(); err != nil {
+		t.Fatalf("Restart() error = %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for r.Restarts() == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if r.Restarts() != 1 {
+		t.Fatalf("Restarts() = %d, want 1", r.Restarts())
+	}
+	if r.PID() == firstPID {
+		t.Error("PID() did not change after restart")
+	}
+	if r.Crashes() != 0 {
+		t.Errorf("Crashes() = %d, a requested restart is not a crash", r.Crashes())
+	}
+}
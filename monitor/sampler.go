@@ -0,0 +1,100 @@
+package monitor
+
+import "sync"
+
+type RequestSample struct {
+	URL    string
+	Name   string
+	Result RequestResult
+}
+
+type RequestSampler struct {
+	mu sync.Mutex
+
+	rate       float64
+	acc        float64
+	pending    []RequestSample
+	maxPending int
+	dropped    int64
+}
+
+func NewRequestSampler(rate float64, maxPending int) *RequestSampler {
+	if maxPending <= 0 {
+		maxPending = 500
+	}
+	s := &RequestSampler{
+		pending:    make([]RequestSample, 0, maxPending),
+		maxPending: maxPending,
+	}
+	s.SetRate(rate)
+	return s
+}
+
+func (s *RequestSampler) SetRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rate < 0 {
+		rate = 0
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	s.rate = rate
+	s.acc = 0
+}
+
+func (s *RequestSampler) Rate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rate
+}
+
+func (s *RequestSampler) Offer(ep *Endpoint, result RequestResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !IsFailedResult(result) {
+		s.acc += s.rate
+		if s.acc < 1-1e-9 {
+			return
+		}
+		s.acc -= 1
+	}
+
+	sample := RequestSample{Result: result}
+	if ep != nil {
+		sample.URL = ep.URL
+		sample.Name = ep.Name
+	}
+
+	s.pending = append(s.pending, sample)
+	if len(s.pending) > s.maxPending {
+		over := len(s.pending) - s.maxPending
+		s.pending = s.pending[over:]
+		s.dropped += int64(over)
+	}
+}
+
+func (s *RequestSampler) Drain() []RequestSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+	result := make([]RequestSample, len(s.pending))
+	copy(result, s.pending)
+	s.pending = s.pending[:0]
+	return result
+}
+
+func (s *RequestSampler) Dropped() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+func IsFailedResult(result RequestResult) bool {
+	return result.IsError || result.StatusCode >= 400
+}
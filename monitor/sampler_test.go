@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestSampler_Rate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate float64
+		want int
+	}{
+		{"everything", 1, 100},
+		{"one in ten", 0.1, 10},
+		{"half", 0.5, 50},
+		{"nothing", 0, 0},
+		{"clamped above one", 2, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewRequestSampler(tt.rate, 1000)
+			for i := 0; i < 100; i++ {
+				s.Offer(nil, RequestResult{StatusCode: 200, Latency: time.Millisecond})
+			}
+			if got := len(s.Drain()); got != tt.want {
+				t.Errorf("sampled %d results, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestSampler_ErrorsAlwaysKept(t *testing.T) {
+	s := NewRequestSampler(0, 100)
+	ep, _ := NewEndpoint("http://localhost:3000/api")
+
+	s.Offer(ep, RequestResult{IsError: true, ErrorMessage: "connection refused"})
+	s.Offer(ep, RequestResult{StatusCode: 503})
+	s.Offer(ep, RequestResult{StatusCode: 200})
+
+	samples := s.Drain()
+	if len(samples) != 2 {
+		t.Fatalf("sampled %d results, want 2", len(samples))
+	}
+	if samples[0].URL != ep.URL || samples[0].Name != ep.Name {
+		t.Errorf("sample endpoint = %q/%q, want %q/%q", samples[0].URL, samples[0].Name, ep.URL, ep.Name)
+	}
+	if len(s.Drain()) != 0 {
+		t.Error("Drain() should empty the pending buffer")
+	}
+}
+
+func TestRequestSampler_Overflow(t *testing.T) {
+	s := NewRequestSampler(1, 5)
+	for i := 0; i < 8; i++ {
+		s.Offer(nil, RequestResult{StatusCode: 200 + i})
+	}
+
+	samples := s.Drain()
+	if len(samples) != 5 {
+		t.Fatalf("pending = %d, want 5", len(samples))
+	}
+	if samples[0].Result.StatusCode != 203 {
+		t.Errorf("oldest kept = %d, want 203", samples[0].Result.StatusCode)
+	}
+	if s.Dropped() != 3 {
+		t.Errorf("Dropped() = %d, want 3", s.Dropped())
+	}
+}
+
+func TestLoadTester_WithSampler(t *testing.T) {
+	ep, _ := NewEndpoint("http://localhost:59999")
+	sampler := NewRequestSampler(0, 100)
+
+	lt := NewLoadTester(ep, NewMetrics(100), WithConcurrency(1), WithClientTimeout(100*time.Millisecond), WithSampler(sampler))
+	if err := lt.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lt.SendBurst(1)
+	time.Sleep(200 * time.Millisecond)
+	lt.Stop()
+
+	if len(sampler.Drain()) != 1 {
+		t.Error("failed request should have been sampled")
+	}
+}
@@ -2,6 +2,7 @@ package monitor
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,20 +16,27 @@ var DefaultPorts = []int{3000, 3001, 8080, 8000, 5000, 9000, 4000, 4200, 5173, 1
 var CommonPaths = []string{"/", "/health", "/api", "/status", "/ping", "/api/health", "/healthz", "/ready"}
 
 type ScanResult struct {
-	Port    int
-	URL     string
-	IsHTTPS bool
-	Status  EndpointStatus
-	Latency time.Duration
-	Error   error
+	Port      int
+	URL       string
+	IsHTTPS   bool
+	Status    EndpointStatus
+	Latency   time.Duration
+	Error     error
+	Untrusted string
 }
 
 type Scanner struct {
+	mu sync.RWMutex
+
 	ports     []int
 	host      string
 	timeout   time.Duration
 	client    *http.Client
 	httpsOnly bool
+	policy    CheckPolicy
+	untrusted bool
+	tls       TLSOptions
+	sockets   []string
 }
 
 type ScannerOption func(*Scanner)
@@ -52,20 +60,44 @@ func WithTimeout(timeout time.Duration) ScannerOption {
 	}
 }
 
+func WithPolicy(policy CheckPolicy) ScannerOption {
+	return func(s *Scanner) {
+		s.policy = DefaultPolicy().Merge(policy)
+		if policy.Timeout > 0 {
+			s.timeout = policy.Timeout
+			s.client.Timeout = policy.Timeout
+		}
+	}
+}
+
 func WithHTTPSOnly(httpsOnly bool) ScannerOption {
 	return func(s *Scanner) {
 		s.httpsOnly = httpsOnly
 	}
 }
 
+func WithReportUntrusted(report bool) ScannerOption {
+	return func(s *Scanner) {
+		s.untrusted = report
+	}
+}
+
+func WithSocketDirs(dirs []string) ScannerOption {
+	return func(s *Scanner) {
+		s.sockets = dirs
+	}
+}
+
 func NewScanner(opts ...ScannerOption) *Scanner {
 	timeout := 2 * time.Second
 	s := &Scanner{
 		ports:   DefaultPorts,
 		host:    "localhost",
 		timeout: timeout,
+		policy:  DefaultPolicy(),
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: newUnixTransport(newTLSTransport(nil)),
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
 				return http.ErrUseLastResponse
 			},
@@ -77,11 +109,97 @@ func NewScanner(opts ...ScannerOption) *Scanner {
 	return s
 }
 
+func (s *Scanner) SetPolicy(policy CheckPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = DefaultPolicy().Merge(policy)
+	if policy.Timeout > 0 {
+		client := *s.client
+		client.Timeout = policy.Timeout
+		s.client = &client
+		s.timeout = policy.Timeout
+	}
+}
+
+func (s *Scanner) SetTLS(opts TLSOptions) error {
+	var cfg *tls.Config
+	if !opts.IsZero() {
+		var err error
+		if cfg, err = opts.Config(); err != nil {
+			return err
+		}
+	}
+	transport := newUnixTransport(newTLSTransport(cfg))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	client := *s.client
+	client.Transport = transport
+	s.client = &client
+	s.tls = opts
+	return nil
+}
+
+func (s *Scanner) SetReportUntrusted(report bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.untrusted = report
+}
+
+func (s *Scanner) TLS() TLSOptions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tls
+}
+
+func (s *Scanner) httpClient() (*http.Client, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client, s.untrusted
+}
+
+func (s *Scanner) probeTimeout() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.timeout
+}
+
+func (s *Scanner) Policy() CheckPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+func (s *Scanner) SetPorts(ports []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ports = append([]int(nil), ports...)
+}
+
+func (s *Scanner) SetSocketDirs(dirs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sockets = append([]string(nil), dirs...)
+}
+
+func (s *Scanner) SocketDirs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.sockets...)
+}
+
+func (s *Scanner) Ports() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]int(nil), s.ports...)
+}
+
 func (s *Scanner) Scan(ctx context.Context) []ScanResult {
 	var wg sync.WaitGroup
-	results := make(chan ScanResult, len(s.ports)*2)
+	ports := s.Ports()
+	results := make(chan ScanResult, len(ports)*2)
 
-	for _, port := range s.ports {
+	for _, port := range ports {
 		wg.Add(2)
 
 		go func(port int) {
@@ -134,7 +252,8 @@ func (s *Scanner) probePort(ctx context.Context, port int, https bool) *ScanResu
 
 	req.Header.Set("User-Agent", "LocalPulse/1.0")
 
-	resp, err := s.client.Do(req)
+	client, reportUntrusted := s.httpClient()
+	resp, err := client.Do(req)
 	latency := time.Since(start)
 
 	if err != nil {
@@ -147,8 +266,16 @@ func (s *Scanner) probePort(ctx context.Context, port int, https bool) *ScanResu
 		if isTimeout(err) {
 			return nil
 		}
-		if isTLSError(err) && https {
-			return nil
+		if reason := UntrustedCert(err); reason != "" && https && reportUntrusted {
+			return &ScanResult{
+				Port:      port,
+				URL:       url,
+				IsHTTPS:   true,
+				Status:    StatusDown,
+				Latency:   latency,
+				Error:     err,
+				Untrusted: reason,
+			}
 		}
 		return nil
 	}
@@ -158,7 +285,7 @@ func (s *Scanner) probePort(ctx context.Context, port int, https bool) *ScanResu
 		Port:    port,
 		URL:     url,
 		IsHTTPS: https,
-		Status:  statusFromResponse(resp, latency),
+		Status:  s.statusFromResponse(resp, latency),
 		Latency: latency,
 	}
 }
@@ -169,18 +296,16 @@ func (s *Scanner) DiscoverEndpoints(ctx context.Context) []*Endpoint {
 	endpoints := make(map[string]*Endpoint)
 
 	for _, result := range results {
-		if result.Status == StatusDown {
-			continue
-		}
-
-		ep, err := NewEndpoint(result.URL)
-		if err != nil {
+		if result.Untrusted != "" {
+			if ep, err := NewEndpoint(result.URL); err == nil {
+				ep.Status = StatusDown
+				ep.LastLatency = result.Latency
+				ep.LastCheck = time.Now()
+				ep.TLSError = result.Untrusted
+				endpoints[result.URL] = ep
+			}
 			continue
 		}
-		ep.Status = result.Status
-		ep.LastLatency = result.Latency
-		ep.LastCheck = time.Now()
-
 		for _, path := range CommonPaths {
 			fullURL := result.URL + path
 			if _, exists := endpoints[fullURL]; !exists {
@@ -189,7 +314,7 @@ func (s *Scanner) DiscoverEndpoints(ctx context.Context) []*Endpoint {
 					continue
 				}
 				probeResult := s.probePath(ctx, fullURL)
-				if probeResult != nil {
+				if probeResult != nil && probeResult.Status != StatusDown {
 					probeEp.Status = probeResult.Status
 					probeEp.LastLatency = probeResult.Latency
 					probeEp.LastCheck = time.Now()
@@ -198,17 +323,87 @@ func (s *Scanner) DiscoverEndpoints(ctx context.Context) []*Endpoint {
 			}
 		}
 
+		if result.Status == StatusDown {
+			continue
+		}
+		ep, err := NewEndpoint(result.URL)
+		if err != nil {
+			continue
+		}
+		ep.Status = result.Status
+		ep.LastLatency = result.Latency
+		ep.LastCheck = time.Now()
 		endpoints[result.URL] = ep
 	}
 
+	if !s.httpsOnly {
+		for _, ep := range s.discoverSockets(ctx) {
+			endpoints[ep.URL] = ep
+		}
+	}
+
 	var list []*Endpoint
 	for _, ep := range endpoints {
 		list = append(list, ep)
 	}
+	opts := s.TLS()
+	for url, cert := range inspectCerts(ctx, list, s.probeTimeout(), func(*Endpoint) TLSOptions { return opts }) {
+		endpoints[url].Cert = cert
+	}
 
 	return list
 }
 
+func (s *Scanner) discoverSockets(ctx context.Context) []*Endpoint {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var found []*Endpoint
+	for _, socket := range FindSockets(s.SocketDirs()) {
+		wg.Add(1)
+		go func(socket string) {
+			defer wg.Done()
+			root := s.probePath(ctx, UnixURL(socket, "/"))
+			if root == nil {
+				return
+			}
+			var eps []*Endpoint
+			for _, path := range CommonPaths {
+				result := root
+				if path != "/" {
+					result = s.probePath(ctx, UnixURL(socket, path))
+				}
+				if result == nil || result.Status == StatusDown {
+					continue
+				}
+				if ep := scannedEndpoint(result); ep != nil {
+					eps = append(eps, ep)
+				}
+			}
+			if len(eps) == 0 {
+				if ep := scannedEndpoint(root); ep != nil {
+					eps = append(eps, ep)
+				}
+			}
+			mu.Lock()
+			found = append(found, eps...)
+			mu.Unlock()
+		}(socket)
+	}
+	wg.Wait()
+	return found
+}
+
+func scannedEndpoint(result *ScanResult) *Endpoint {
+	ep, err := NewEndpoint(result.URL)
+	if err != nil {
+		return nil
+	}
+	ep.Status = result.Status
+	ep.LastLatency = result.Latency
+	ep.LastCheck = time.Now()
+	return ep
+}
+
 func (s *Scanner) probePath(ctx context.Context, url string) *ScanResult {
 	start := time.Now()
 
@@ -219,7 +414,8 @@ func (s *Scanner) probePath(ctx context.Context, url string) *ScanResult {
 
 	req.Header.Set("User-Agent", "LocalPulse/1.0")
 
-	resp, err := s.client.Do(req)
+	client, _ := s.httpClient()
+	resp, err := client.Do(req)
 	latency := time.Since(start)
 
 	if err != nil {
@@ -229,19 +425,14 @@ func (s *Scanner) probePath(ctx context.Context, url string) *ScanResult {
 
 	return &ScanResult{
 		URL:     url,
-		Status:  statusFromResponse(resp, latency),
+		Status:  s.statusFromResponse(resp, latency),
 		Latency: latency,
 	}
 }
 
-func statusFromResponse(resp *http.Response, latency time.Duration) EndpointStatus {
-	if resp.StatusCode >= 500 {
-		return StatusDown
-	}
-	if latency > 500*time.Millisecond {
-		return StatusSlow
-	}
-	return StatusHealthy
+func (s *Scanner) statusFromResponse(resp *http.Response, latency time.Duration) EndpointStatus {
+	policy := s.Policy()
+	return policy.Classify(latency, policy.CheckStatus(resp.StatusCode))
 }
 
 func isConnectionRefused(err error) bool {
@@ -255,12 +446,6 @@ func isTimeout(err error) bool {
 		strings.Contains(err.Error(), "context deadline exceeded")
 }
 
-func isTLSError(err error) bool {
-	return strings.Contains(err.Error(), "certificate") ||
-		strings.Contains(err.Error(), "TLS") ||
-		strings.Contains(err.Error(), "x509")
-}
-
 func CheckPort(host string, port int, timeout time.Duration) bool {
 	address := net.JoinHostPort(host, fmt.Sprintf("%d", port))
 	conn, err := net.DialTimeout("tcp", address, timeout)
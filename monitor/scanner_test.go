@@ -150,6 +150,44 @@ func TestScanner_DiscoverEndpoints(t *testing.T) {
 	}
 }
 
+func TestScanner_DiscoverEndpointsPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			time.Sleep(20 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		case "/api":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	discover := func(policy CheckPolicy) map[string]EndpointStatus {
+		s := NewScanner(WithPorts([]int{extractPort(srv)}), WithHost("127.0.0.1"), WithPolicy(policy))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		found := make(map[string]EndpointStatus)
+		for _, ep := range s.DiscoverEndpoints(ctx) {
+			u, _ := url.Parse(ep.URL)
+			found[u.Path] = ep.Status
+		}
+		return found
+	}
+
+	found := discover(CheckPolicy{})
+	if len(found) != 1 || found["/health"] != StatusHealthy {
+		t.Errorf("default policy found %v, want only /health", found)
+	}
+
+	found = discover(CheckPolicy{SlowThreshold: 10 * time.Millisecond, Accept: []StatusRange{{Min: 200, Max: 299}, {Min: 401, Max: 401}}})
+	if len(found) != 2 || found["/health"] != StatusSlow || found["/api"] != StatusHealthy {
+		t.Errorf("custom policy found %v, want /health slow and /api healthy", found)
+	}
+}
+
 func TestScanner_WithPorts(t *testing.T) {
 	ports := []int{1000, 2000, 3000}
 	s := NewScanner(WithPorts(ports))
@@ -173,6 +211,20 @@ func TestScanner_WithTimeout(t *testing.T) {
 	}
 }
 
+func TestScanner_SetPolicyTimeout(t *testing.T) {
+	s := NewScanner(WithPolicy(CheckPolicy{Timeout: 5 * time.Second}))
+	before, _ := s.httpClient()
+
+	s.SetPolicy(CheckPolicy{Timeout: 250 * time.Millisecond})
+	client, _ := s.httpClient()
+	if s.probeTimeout() != 250*time.Millisecond || client.Timeout != 250*time.Millisecond {
+		t.Errorf("after SetPolicy timeout = %v, client timeout = %v, want 250ms", s.probeTimeout(), client.Timeout)
+	}
+	if before.Timeout != 5*time.Second {
+		t.Errorf("SetPolicy changed the client of in-flight scans to %v", before.Timeout)
+	}
+}
+
 func TestScanner_ContextCancellation(t *testing.T) {
 	s := NewScanner(WithPorts(DefaultPorts))
 
@@ -221,3 +273,13 @@ func TestQuickScan(t *testing.T) {
 		t.Errorf("QuickScan() did not find open port %d", port)
 	}
 }
+
+func TestScanner_SetPorts(t *testing.T) {
+	s := NewScanner(WithPorts([]int{3000}))
+	s.SetPorts([]int{8080, 9090})
+
+	got := s.Ports()
+	if len(got) != 2 || got[0] != 8080 || got[1] != 9090 {
+		t.Errorf("Ports() = %v, want [8080 9090]", got)
+	}
+}
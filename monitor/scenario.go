@@ -0,0 +1,270 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExtractSource string
+
+const (
+	ExtractJSON   ExtractSource = "json"
+	ExtractHeader ExtractSource = "header"
+	ExtractRegex  ExtractSource = "regex"
+	ExtractCookie ExtractSource = "cookie"
+)
+
+type Extraction struct {
+	Var    string
+	Source ExtractSource
+	Expr   string
+	re     *regexp.Regexp
+}
+
+func ParseExtraction(name, spec string) (Extraction, error) {
+	source, expr, ok := strings.Cut(spec, ":")
+	if !ok || strings.TrimSpace(expr) == "" {
+		return Extraction{}, fmt.Errorf("%q must look like json:path, header:Name, regex:pattern or cookie:name", spec)
+	}
+
+	e := Extraction{Var: name, Source: ExtractSource(strings.ToLower(strings.TrimSpace(source))), Expr: expr}
+	switch e.Source {
+	case ExtractJSON, ExtractHeader, ExtractCookie:
+		e.Expr = strings.TrimSpace(expr)
+	case ExtractRegex:
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return Extraction{}, fmt.Errorf("invalid regex: %v", err)
+		}
+		e.re = re
+	default:
+		return Extraction{}, fmt.Errorf("unknown source %q, want json, header, regex or cookie", source)
+	}
+	return e, nil
+}
+
+func (e Extraction) extract(resp *http.Response, body []byte) (string, error) {
+	switch e.Source {
+	case ExtractJSON:
+		return extractJSON(body, e.Expr)
+	case ExtractHeader:
+		if v := resp.Header.Get(e.Expr); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("no %s header", e.Expr)
+	case ExtractCookie:
+		for _, c := range resp.Cookies() {
+			if c.Name == e.Expr {
+				return c.Value, nil
+			}
+		}
+		return "", fmt.Errorf("no %s cookie", e.Expr)
+	case ExtractRegex:
+		re := e.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(e.Expr); err != nil {
+				return "", err
+			}
+		}
+		m := re.FindSubmatch(body)
+		switch {
+		case m == nil:
+			return "", fmt.Errorf("regex %q did not match", e.Expr)
+		case len(m) > 1:
+			return string(m[1]), nil
+		}
+		return string(m[0]), nil
+	}
+	return "", fmt.Errorf("unknown source %q", e.Source)
+}
+
+func extractJSON(body []byte, path string) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", fmt.Errorf("response is not JSON: %v", err)
+	}
+
+	for _, key := range splitJSONPath(path) {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return "", fmt.Errorf("json path %s not found", path)
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return "", fmt.Errorf("json path %s not found", path)
+			}
+			value = v[i]
+		default:
+			return "", fmt.Errorf("json path %s not found", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", fmt.Errorf("json path %s is null", path)
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	var keys []string
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+type ScenarioStep struct {
+	Name    string
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+	Extract []Extraction
+}
+
+type Scenario struct {
+	Name   string
+	Vars   map[string]string
+	Steps  []ScenarioStep
+	Feeder *Feeder
+	Auth   *Auth
+}
+
+type StepResult struct {
+	Name   string
+	Result RequestResult
+}
+
+type IterationResult struct {
+	Result RequestResult
+	Steps  []StepResult
+	Vars   map[string]string
+}
+
+func (s *Scenario) StepName(i int) string {
+	if i < len(s.Steps) && s.Steps[i].Name != "" {
+		return s.Steps[i].Name
+	}
+	return "step " + strconv.Itoa(i+1)
+}
+
+func (s *Scenario) Run(ctx context.Context, client *http.Client, policy CheckPolicy, vu int) IterationResult {
+	vars := make(map[string]string, len(s.Vars))
+	for k, v := range s.Vars {
+		vars[k] = v
+	}
+
+	session := *client
+	session.Jar, _ = cookiejar.New(nil)
+
+	it := IterationResult{
+		Result: RequestResult{Timestamp: time.Now()},
+		Vars:   vars,
+	}
+	if s.Feeder != nil {
+		row, err := s.Feeder.Next(vu)
+		if err != nil {
+			it.Result.IsError = true
+			it.Result.ErrorMessage = err.Error()
+			return it
+		}
+		for k, v := range row {
+			vars[k] = v
+		}
+	}
+	for i, step := range s.Steps {
+		result := runStep(ctx, &session, step, vars, policy, s.Auth)
+		name := s.StepName(i)
+		it.Steps = append(it.Steps, StepResult{Name: name, Result: result})
+
+		it.Result.Latency += result.Latency
+		it.Result.Size += result.Size
+		it.Result.StatusCode = result.StatusCode
+		if result.IsError {
+			it.Result.IsError = true
+			it.Result.ErrorMessage = name + ": " + result.ErrorMessage
+			break
+		}
+	}
+	return it
+}
+
+func runStep(ctx context.Context, client *http.Client, step ScenarioStep, vars map[string]string, policy CheckPolicy, auth *Auth) RequestResult {
+	result := RequestResult{Timestamp: time.Now()}
+	fail := func(err error) RequestResult {
+		result.IsError = true
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	tmpl := RequestTemplate{Method: step.Method, URL: step.URL, Headers: step.Headers, Body: step.Body}
+	req, err := tmpl.build(ctx, vars)
+	if err != nil {
+		return fail(err)
+	}
+	if err := auth.apply(ctx, req); err != nil {
+		return fail(err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Latency = time.Since(start)
+		return fail(err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	result.Latency = time.Since(start)
+	result.StatusCode = resp.StatusCode
+	result.Size = int64(len(data))
+
+	if err := policy.CheckStatus(resp.StatusCode); err != nil {
+		return fail(err)
+	}
+	for _, e := range step.Extract {
+		value, err := e.extract(resp, data)
+		if err != nil {
+			return fail(fmt.Errorf("extract %s: %w", e.Var, err))
+		}
+		vars[e.Var] = value
+	}
+	return result
+}
+
+type StepStats struct {
+	Name  string
+	Stats Stats
+}
@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newShopServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"user":"alice"`) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "s-42"})
+		w.Write([]byte(`{"data":{"token":"t-123","roles":["admin"]}}`))
+	})
+	mux.HandleFunc("POST /items", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Location", "/items/7")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"items":[{"id":7,"csrf":"<input name=csrf value=abc>"}]}`))
+	})
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("sid"); err != nil || c.Value != "s-42" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"id":` + r.PathValue("id") + `}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func mustExtract(t *testing.T, name, spec string) Extraction {
+	t.Helper()
+	e, err := ParseExtraction(name, spec)
+	if err != nil {
+		t.Fatalf("ParseExtraction(%q) error = %v", spec, err)
+	}
+	return e
+}
+
+func shopScenario(t *testing.T, base string) *Scenario {
+	return &Scenario{
+		Name: "checkout",
+		Vars: map[string]string{"base": base, "user": "alice"},
+		Steps: []ScenarioStep{
+			{
+				Name:    "login",
+				Method:  "post",
+				URL:     "${base}/login",
+				Body:    `{"user":"${user}"}`,
+				Extract: []Extraction{mustExtract(t, "token", "json:data.token"), mustExtract(t, "sid", "cookie:sid")},
+			},
+			{
+				Name:    "create",
+				Method:  "POST",
+				URL:     "${base}/items",
+				Headers: map[string]string{"Authorization": "Bearer ${token}"},
+				Extract: []Extraction{
+					mustExtract(t, "id", "json:$.items[0].id"),
+					mustExtract(t, "location", "header:Location"),
+					mustExtract(t, "csrf", "regex:value=(\\w+)"),
+				},
+			},
+			{Name: "read", URL: "${base}${location}"},
+		},
+	}
+}
+
+func TestScenario_Run(t *testing.T) {
+	srv := newShopServer(t)
+	sc := shopScenario(t, srv.URL)
+
+	it := sc.Run(context.Background(), &http.Client{}, DefaultPolicy(), 0)
+	if it.Result.IsError {
+		t.Fatalf("iteration failed: %s", it.Result.ErrorMessage)
+	}
+	if len(it.Steps) != 3 || it.Steps[2].Name != "read" || it.Steps[2].Result.StatusCode != http.StatusOK {
+		t.Fatalf("steps = %+v", it.Steps)
+	}
+
+	want := map[string]string{"token": "t-123", "sid": "s-42", "id": "7", "location": "/items/7", "csrf": "abc"}
+	for name, value := range want {
+		if it.Vars[name] != value {
+			t.Errorf("var %s = %q, want %q", name, it.Vars[name], value)
+		}
+	}
+	if it.Result.Latency < it.Steps[0].Result.Latency {
+		t.Errorf("iteration latency %v is below the first step's %v", it.Result.Latency, it.Steps[0].Result.Latency)
+	}
+}
+
+func TestScenario_StopsAtFailedStep(t *testing.T) {
+	srv := newShopServer(t)
+	sc := shopScenario(t, srv.URL)
+	sc.Vars["user"] = "mallory"
+
+	it := sc.Run(context.Background(), &http.Client{}, DefaultPolicy(), 0)
+	if !it.Result.IsError || len(it.Steps) != 1 {
+		t.Fatalf("result = %+v with %d steps, want a failure after login", it.Result, len(it.Steps))
+	}
+	if want := "login: status 401, expected 200-399"; it.Result.ErrorMessage != want {
+		t.Errorf("ErrorMessage = %q, want %q", it.Result.ErrorMessage, want)
+	}
+}
+
+func TestScenario_Errors(t *testing.T) {
+	srv := newShopServer(t)
+
+	tests := []struct {
+		name    string
+		step    ScenarioStep
+		wantErr string
+	}{
+		{"undefined variable", ScenarioStep{URL: srv.URL + "/items/${id}"}, "undefined variable ${id}"},
+		{"missing json path", ScenarioStep{Method: "POST", URL: srv.URL + "/login", Body: `{"user":"alice"}`, Extract: []Extraction{mustExtract(t, "x", "json:data.missing")}}, "extract x: json path data.missing not found"},
+		{"missing header", ScenarioStep{Method: "POST", URL: srv.URL + "/login", Body: `{"user":"alice"}`, Extract: []Extraction{mustExtract(t, "x", "header:Location")}}, "extract x: no Location header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &Scenario{Steps: []ScenarioStep{tt.step}}
+			it := sc.Run(context.Background(), &http.Client{}, DefaultPolicy(), 0)
+			if want := "step 1: " + tt.wantErr; it.Result.ErrorMessage != want {
+				t.Errorf("ErrorMessage = %q, want %q", it.Result.ErrorMessage, want)
+			}
+		})
+	}
+}
+
+func TestParseExtraction(t *testing.T) {
+	for _, spec := range []string{"json", "json:", "xpath://a", "regex:("} {
+		if _, err := ParseExtraction("v", spec); err == nil {
+			t.Errorf("ParseExtraction(%q): expected error", spec)
+		}
+	}
+	e := mustExtract(t, "v", "Header: X-Request-Id")
+	if e.Source != ExtractHeader || e.Expr != "X-Request-Id" {
+		t.Errorf("ParseExtraction() = %+v", e)
+	}
+}
+
+func TestLoadTester_ScenarioStepStats(t *testing.T) {
+	srv := newShopServer(t)
+	ep := NewScenarioEndpoint(shopScenario(t, srv.URL))
+	metrics := NewMetrics(100)
+
+	lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+	if err := lt.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lt.SendBurst(4)
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	if got := metrics.GetStats(); got.TotalRequests != 4 || got.TotalErrors != 0 {
+		t.Errorf("iterations = %d, errors = %d, want 4 clean iterations", got.TotalRequests, got.TotalErrors)
+	}
+	steps := lt.StepStats()
+	if len(steps) != 3 {
+		t.Fatalf("StepStats() returned %d steps, want 3", len(steps))
+	}
+	for _, step := range steps {
+		if step.Stats.TotalRequests != 4 {
+			t.Errorf("step %s ran %d times, want 4", step.Name, step.Stats.TotalRequests)
+		}
+	}
+	if obs, _ := lt.Observation(); ep.URL != "scenario:checkout" || obs.Status != StatusHealthy {
+		t.Errorf("endpoint = %s (%v), want scenario:checkout healthy", ep.URL, obs.Status)
+	}
+}
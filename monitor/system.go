@@ -1,35 +1,101 @@
 package monitor
 
 import (
+	"os"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
 )
 
 type SystemMetrics struct {
 	CPUPercent float64
+	CPUPerCore []float64
 	RAMUsed    uint64
 	RAMTotal   uint64
 	RAMPercent float64
+	SwapUsed   uint64
+	SwapTotal  uint64
+
+	Load1  float64
+	Load5  float64
+	Load15 float64
+
+	LoopbackRxRate float64
+	LoopbackTxRate float64
+	DiskReadRate   float64
+	DiskWriteRate  float64
+
+	SelfCPUPercent float64
+	SelfRSS        uint64
+	Goroutines     int
+
+	SampledAt time.Time
 }
 
 type SystemMonitor struct {
-	previousCPU float64
+	mu sync.Mutex
+
+	interval  time.Duration
+	last      SystemMetrics
+	self      *process.Process
+	loopbacks map[string]bool
+
+	lastNetRx     uint64
+	lastNetTx     uint64
+	lastDiskRead  uint64
+	lastDiskWrite uint64
+	lastCounters  time.Time
 }
 
-func NewSystemMonitor() *SystemMonitor {
-	return &SystemMonitor{}
+type SystemMonitorOption func(*SystemMonitor)
+
+func WithRefreshInterval(interval time.Duration) SystemMonitorOption {
+	return func(sm *SystemMonitor) {
+		sm.interval = interval
+	}
+}
+
+func NewSystemMonitor(opts ...SystemMonitorOption) *SystemMonitor {
+	sm := &SystemMonitor{
+		interval:  time.Second,
+		loopbacks: loopbackInterfaces(),
+	}
+	sm.self, _ = process.NewProcess(int32(os.Getpid()))
+
+	for _, opt := range opts {
+		opt(sm)
+	}
+	return sm
 }
 
 func (sm *SystemMonitor) GetMetrics() SystemMetrics {
-	metrics := SystemMetrics{}
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
 
-	cpuPercents, err := cpu.Percent(0, false)
-	if err == nil && len(cpuPercents) > 0 {
-		metrics.CPUPercent = cpuPercents[0]
+	now := time.Now()
+	if !sm.last.SampledAt.IsZero() && now.Sub(sm.last.SampledAt) < sm.interval {
+		return sm.last
+	}
+
+	metrics := SystemMetrics{SampledAt: now}
+
+	if perCore, err := cpu.Percent(0, true); err == nil {
+		metrics.CPUPerCore = perCore
+	}
+	if total, err := cpu.Percent(0, false); err == nil && len(total) > 0 {
+		metrics.CPUPercent = total[0]
+	} else if len(metrics.CPUPerCore) > 0 {
+		metrics.CPUPercent = average(metrics.CPUPerCore)
 	} else {
-		metrics.CPUPercent = sm.estimateCPU()
+		metrics.CPUPercent = sm.last.CPUPercent
 	}
 
 	vmStat, err := mem.VirtualMemory()
@@ -47,14 +113,112 @@ func (sm *SystemMonitor) GetMetrics() SystemMetrics {
 		}
 	}
 
-	sm.previousCPU = metrics.CPUPercent
+	if swap, err := mem.SwapMemory(); err == nil {
+		metrics.SwapUsed = swap.Used
+		metrics.SwapTotal = swap.Total
+	}
+
+	if avg, err := load.Avg(); err == nil {
+		metrics.Load1, metrics.Load5, metrics.Load15 = avg.Load1, avg.Load5, avg.Load15
+	}
+
+	sm.sampleCounters(&metrics)
+
+	if sm.self != nil {
+		if pct, err := sm.self.Percent(0); err == nil {
+			metrics.SelfCPUPercent = pct
+		}
+		if mi, err := sm.self.MemoryInfo(); err == nil {
+			metrics.SelfRSS = mi.RSS
+		}
+	}
+	metrics.Goroutines = runtime.NumGoroutine()
+
+	sm.last = metrics
 	return metrics
 }
 
-func (sm *SystemMonitor) estimateCPU() float64 {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	return sm.previousCPU
+func (sm *SystemMonitor) sampleCounters(metrics *SystemMetrics) {
+	var rx, tx uint64
+	if counters, err := psnet.IOCounters(true); err == nil {
+		for _, c := range counters {
+			if sm.loopbacks[c.Name] {
+				rx += c.BytesRecv
+				tx += c.BytesSent
+			}
+		}
+	}
+
+	var read, write uint64
+	if counters, err := disk.IOCounters(); err == nil {
+		names := make([]string, 0, len(counters))
+		for name := range counters {
+			names = append(names, name)
+		}
+		for name, c := range counters {
+			if isDiskPartition(name, names) {
+				continue
+			}
+			read += c.ReadBytes
+			write += c.WriteBytes
+		}
+	}
+
+	if !sm.lastCounters.IsZero() {
+		elapsed := metrics.SampledAt.Sub(sm.lastCounters).Seconds()
+		if elapsed > 0 {
+			metrics.LoopbackRxRate = rate(rx, sm.lastNetRx, elapsed)
+			metrics.LoopbackTxRate = rate(tx, sm.lastNetTx, elapsed)
+			metrics.DiskReadRate = rate(read, sm.lastDiskRead, elapsed)
+			metrics.DiskWriteRate = rate(write, sm.lastDiskWrite, elapsed)
+		}
+	}
+
+	sm.lastNetRx, sm.lastNetTx = rx, tx
+	sm.lastDiskRead, sm.lastDiskWrite = read, write
+	sm.lastCounters = metrics.SampledAt
+}
+
+func loopbackInterfaces() map[string]bool {
+	names := make(map[string]bool)
+	ifaces, err := psnet.Interfaces()
+	if err == nil {
+		for _, iface := range ifaces {
+			for _, flag := range iface.Flags {
+				if flag == "loopback" {
+					names[iface.Name] = true
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		names["lo"] = true
+		names["lo0"] = true
+	}
+	return names
+}
+
+func isDiskPartition(name string, all []string) bool {
+	if strings.HasPrefix(name, "loop") || strings.HasPrefix(name, "ram") {
+		return true
+	}
+	for _, other := range all {
+		if other != name && strings.HasPrefix(name, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
 }
 
 func (sm *SystemMonitor) getSystemRAM() uint64 {
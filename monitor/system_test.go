@@ -3,6 +3,7 @@ package monitor
 import (
 	"runtime"
 	"testing"
+	"time"
 )
 
 func TestSystemMonitor_GetMetrics(t *testing.T) {
@@ -34,3 +35,57 @@ func TestGetGoVersion(t *testing.T) {
 		t.Error("GetGoVersion() returned empty string")
 	}
 }
+
+func TestSystemMonitor_Details(t *testing.T) {
+	sm := NewSystemMonitor(WithRefreshInterval(0))
+	sm.GetMetrics()
+	time.Sleep(50 * time.Millisecond)
+	metrics := sm.GetMetrics()
+
+	if n := len(metrics.CPUPerCore); n != 0 && n != runtime.NumCPU() {
+		t.Errorf("len(CPUPerCore) = %d, want %d", n, runtime.NumCPU())
+	}
+	if metrics.Goroutines < 1 {
+		t.Errorf("Goroutines = %d, want at least 1", metrics.Goroutines)
+	}
+	if metrics.SelfRSS == 0 {
+		t.Error("SelfRSS should not be zero")
+	}
+	for name, v := range map[string]float64{
+		"LoopbackRxRate": metrics.LoopbackRxRate,
+		"LoopbackTxRate": metrics.LoopbackTxRate,
+		"DiskReadRate":   metrics.DiskReadRate,
+		"DiskWriteRate":  metrics.DiskWriteRate,
+	} {
+		if v < 0 {
+			t.Errorf("%s = %v, should not be negative", name, v)
+		}
+	}
+}
+
+func TestSystemMonitor_RefreshInterval(t *testing.T) {
+	sm := NewSystemMonitor(WithRefreshInterval(time.Hour))
+	first := sm.GetMetrics()
+	second := sm.GetMetrics()
+
+	if !first.SampledAt.Equal(second.SampledAt) {
+		t.Error("GetMetrics() sampled again within the refresh interval")
+	}
+}
+
+func TestIsDiskPartition(t *testing.T) {
+	names := []string{"sda", "sda1", "sda2", "nvme0n1", "nvme0n1p1", "loop0", "dm-0"}
+	tests := map[string]bool{
+		"sda":       false,
+		"sda1":      true,
+		"nvme0n1":   false,
+		"nvme0n1p1": true,
+		"loop0":     true,
+		"dm-0":      false,
+	}
+	for name, want := range tests {
+		if got := isDiskPartition(name, names); got != want {
+			t.Errorf("isDiskPartition(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var varPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?:\(([^)]*)\))?\}`)
+
+var generators = map[string]func(args []string) (string, error){
+	"uuid": func(args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("uuid takes no arguments")
+		}
+		var b [16]byte
+		rand.Read(b[:])
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	},
+	"timestamp": func(args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("timestamp takes no arguments")
+		}
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	},
+	"randint": func(args []string) (string, error) {
+		if len(args) != 2 {
+			return "", fmt.Errorf("randint needs a minimum and a maximum, e.g. randint(1,100)")
+		}
+		min, err1 := strconv.ParseInt(args[0], 10, 64)
+		max, err2 := strconv.ParseInt(args[1], 10, 64)
+		if err1 != nil || err2 != nil || max < min {
+			return "", fmt.Errorf("randint(%s) needs two integers, the second not below the first", strings.Join(args, ","))
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(max-min+1))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(min+n.Int64(), 10), nil
+	},
+	"randstr": func(args []string) (string, error) {
+		const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+		if len(args) != 1 {
+			return "", fmt.Errorf("randstr needs a length, e.g. randstr(12)")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > 4096 {
+			return "", fmt.Errorf("randstr(%s) needs a length between 1 and 4096", args[0])
+		}
+		b := make([]byte, n)
+		rand.Read(b)
+		for i := range b {
+			b[i] = alphabet[int(b[i])%len(alphabet)]
+		}
+		return string(b), nil
+	},
+}
+
+func splitArgs(s string) []string {
+	var args []string
+	for _, arg := range strings.Split(s, ",") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+func VarRefs(s string) []string {
+	var names []string
+	for _, m := range varPattern.FindAllStringSubmatch(s, -1) {
+		if _, builtin := generators[m[1]]; !builtin {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+func CheckTemplate(s string) error {
+	for _, m := range varPattern.FindAllStringSubmatch(s, -1) {
+		gen, builtin := generators[m[1]]
+		if !builtin {
+			if strings.Contains(m[0], "(") {
+				return fmt.Errorf("unknown generator %s, want uuid, timestamp, randint or randstr", m[1])
+			}
+			continue
+		}
+		if _, err := gen(splitArgs(m[2])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expandVars(s string, vars map[string]string) (string, error) {
+	var failed error
+	out := varPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := varPattern.FindStringSubmatch(ref)
+		if v, ok := vars[m[1]]; ok && !strings.Contains(ref, "(") {
+			return v
+		}
+		gen, ok := generators[m[1]]
+		if !ok {
+			if failed == nil {
+				failed = fmt.Errorf("undefined variable ${%s}", m[1])
+			}
+			return ""
+		}
+		v, err := gen(splitArgs(m[2]))
+		if err != nil && failed == nil {
+			failed = err
+		}
+		return v
+	})
+	if failed != nil {
+		return "", failed
+	}
+	return out, nil
+}
+
+func expandURL(s string, vars map[string]string) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, m := range varPattern.FindAllStringIndex(s, -1) {
+		value, err := expandVars(s[m[0]:m[1]], vars)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(escapeURLValue(s[:m[0]], value))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+func escapeURLValue(prefix, value string) string {
+	_, rest, ok := strings.Cut(prefix, "://")
+	switch {
+	case !ok || !strings.Contains(rest, "/"):
+		return value
+	case strings.ContainsAny(rest, "?#"):
+		return url.QueryEscape(value)
+	}
+	return strings.ReplaceAll(url.PathEscape(value), "%2F", "/")
+}
+
+type RequestTemplate struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+	Feeder  *Feeder
+}
+
+func (t *RequestTemplate) NewRequest(ctx context.Context, vu int) (*http.Request, error) {
+	vars := map[string]string{}
+	if t.Feeder != nil {
+		row, err := t.Feeder.Next(vu)
+		if err != nil {
+			return nil, err
+		}
+		vars = row
+	}
+	return t.build(ctx, vars)
+}
+
+func (t *RequestTemplate) handshakeHeaders(ctx context.Context, vu int) (http.Header, error) {
+	vars := map[string]string{}
+	if t.Feeder != nil {
+		row, err := t.Feeder.Next(vu)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(vars, row)
+	}
+	vars["vu"] = strconv.Itoa(vu)
+	req, err := t.build(ctx, vars)
+	if err != nil {
+		return nil, err
+	}
+	return req.Header, nil
+}
+
+func (t *RequestTemplate) build(ctx context.Context, vars map[string]string) (*http.Request, error) {
+	target, err := expandURL(t.URL, vars)
+	if err != nil {
+		return nil, err
+	}
+	body, err := expandVars(t.Body, vars)
+	if err != nil {
+		return nil, err
+	}
+	method := strings.ToUpper(t.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "LocalPulse/1.0")
+	req.Header.Set("Accept", "*/*")
+	if trimmed := strings.TrimSpace(body); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range t.Headers {
+		v, err := expandVars(value, vars)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, v)
+	}
+	return req, nil
+}
+
+func newEndpointRequest(ctx context.Context, ep *Endpoint, vu int) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if ep.Request != nil {
+		req, err = ep.Request.NewRequest(ctx, vu)
+	} else if req, err = http.NewRequestWithContext(ctx, http.MethodGet, ep.URL, nil); err == nil {
+		req.Header.Set("User-Agent", "LocalPulse/1.0")
+		req.Header.Set("Accept", "*/*")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ep.Auth.apply(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExpandVars_Generators(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want *regexp.Regexp
+	}{
+		{"/users/${uuid}", regexp.MustCompile(`^/users/[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"page=${randint(3, 5)}", regexp.MustCompile(`^page=[345]$`)},
+		{"q=${randstr(8)}", regexp.MustCompile(`^q=[A-Za-z0-9]{8}$`)},
+		{"t=${timestamp}", regexp.MustCompile(`^t=\d{10}$`)},
+		{"${name}-${id}", regexp.MustCompile(`^ada-7$`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tmpl, func(t *testing.T) {
+			got, err := expandVars(tt.tmpl, map[string]string{"name": "ada", "id": "7"})
+			if err != nil {
+				t.Fatalf("expandVars() error = %v", err)
+			}
+			if !tt.want.MatchString(got) {
+				t.Errorf("expandVars(%q) = %q", tt.tmpl, got)
+			}
+		})
+	}
+}
+
+func TestExpandURL_Escapes(t *testing.T) {
+	vars := map[string]string{"base": "http://example.com/api", "q": "a b&c#d", "path": "x/y z?"}
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"http://example.com/items/${q}", "http://example.com/items/a%20b&c%23d"},
+		{"http://example.com/items?q=${q}", "http://example.com/items?q=a+b%26c%23d"},
+		{"http://example.com/${path}", "http://example.com/x/y%20z%3F"},
+		{"${base}/items", "http://example.com/api/items"},
+		{"http://${q}", "http://a b&c#d"},
+	}
+	for _, tt := range tests {
+		got, err := expandURL(tt.tmpl, vars)
+		if err != nil {
+			t.Fatalf("expandURL(%q) error = %v", tt.tmpl, err)
+		}
+		if got != tt.want {
+			t.Errorf("expandURL(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTemplate(t *testing.T) {
+	valid := []string{"/plain", "${id}", "${uuid}", "${randint(1,100)}", "${randstr(4)}"}
+	for _, s := range valid {
+		if err := CheckTemplate(s); err != nil {
+			t.Errorf("CheckTemplate(%q) error = %v", s, err)
+		}
+	}
+	invalid := []string{"${randint(9,1)}", "${randint(1)}", "${randstr(0)}", "${uuid(4)}", "${shuffle(1)}"}
+	for _, s := range invalid {
+		if err := CheckTemplate(s); err == nil {
+			t.Errorf("CheckTemplate(%q): expected error", s)
+		}
+	}
+	if refs := VarRefs("${uuid}/${user_id}?t=${timestamp}"); len(refs) != 1 || refs[0] != "user_id" {
+		t.Errorf("VarRefs() = %v, want only user_id", refs)
+	}
+}
+
+func TestLoadTester_RequestTemplate(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Tenant")+" "+string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	feeder, _ := NewFeeder("users", []map[string]string{{"id": "1", "tenant": "a"}, {"id": "2", "tenant": "b"}}, FeedSequential)
+	ep, _ := NewEndpoint(srv.URL + "/users")
+	ep.Request = &RequestTemplate{
+		Method:  "PUT",
+		URL:     srv.URL + "/users/${id}?v=${randint(1,1)}",
+		Headers: map[string]string{"X-Tenant": "${tenant}"},
+		Body:    `{"id":${id}}`,
+		Feeder:  feeder,
+	}
+
+	lt := NewLoadTester(ep, NewMetrics(100), WithConcurrency(1))
+	if err := lt.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		lt.SendRequest()
+		time.Sleep(50 * time.Millisecond)
+	}
+	lt.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{`PUT /users/1?v=1 a {"id":1}`, `PUT /users/2?v=1 b {"id":2}`, `PUT /users/1?v=1 a {"id":1}`}
+	if !equalStrings(requests, want) {
+		t.Errorf("requests =\n%q\nwant\n%q", requests, want)
+	}
+}
+
+func TestCheckEndpoints_RequestTemplate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(srv.URL + "/search")
+	ep.Request = &RequestTemplate{Method: "POST", URL: srv.URL + "/search", Body: `{"q":"${randstr(5)}"}`}
+
+	results := CheckEndpoints(context.Background(), []*Endpoint{ep}, WithCheckCount(2))
+	if results[0].Failed != 0 || results[0].StatusCodes[http.StatusOK] != 2 {
+		t.Errorf("result = %+v, want two successful POSTs", results[0])
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,6 +29,86 @@ type LoadTester struct {
 	resultChan chan RequestResult
 
 	requestsSent atomic.Int64
+	sampler      atomic.Pointer[RequestSampler]
+	achieved     rateMeter
+	steps        []*Metrics
+	settings     atomic.Pointer[testerSettings]
+	observation  atomic.Pointer[Observation]
+}
+
+type testerSettings struct {
+	endpoint   *Endpoint
+	client     *http.Client
+	transports []http.RoundTripper
+}
+
+func (s *testerSettings) sameTransport(ep *Endpoint) bool {
+	old := s.endpoint
+	return old != nil && ep != nil && old.tlsConfig == ep.tlsConfig && old.Protocol == ep.Protocol &&
+		old.Connections == ep.Connections && old.Socket == ep.Socket
+}
+
+func (s *testerSettings) closeIdleConnections() {
+	for _, t := range append([]http.RoundTripper{s.client.Transport}, s.transports...) {
+		if t, ok := t.(interface{ CloseIdleConnections() }); ok {
+			t.CloseIdleConnections()
+		}
+	}
+}
+
+const rateMeterWindow = 5
+
+type rateMeter struct {
+	mu      sync.Mutex
+	start   time.Time
+	seconds [rateMeterWindow]int64
+	counts  [rateMeterWindow]int64
+}
+
+func (r *rateMeter) reset(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start = now
+	r.seconds = [rateMeterWindow]int64{}
+	r.counts = [rateMeterWindow]int64{}
+}
+
+func (r *rateMeter) add(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sec := now.Unix()
+	i := sec % rateMeterWindow
+	if r.seconds[i] != sec {
+		r.seconds[i] = sec
+		r.counts[i] = 0
+	}
+	r.counts[i]++
+}
+
+func (r *rateMeter) rate(now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.start.IsZero() {
+		return 0
+	}
+
+	sec := now.Unix()
+	var total int64
+	for i, s := range r.seconds {
+		if s > sec-rateMeterWindow && s <= sec {
+			total += r.counts[i]
+		}
+	}
+
+	from := time.Unix(sec-rateMeterWindow+1, 0)
+	if r.start.After(from) {
+		from = r.start
+	}
+	elapsed := now.Sub(from).Seconds()
+	if elapsed < 1 {
+		elapsed = 1
+	}
+	return float64(total) / elapsed
 }
 
 type LoadTesterOption func(*LoadTester)
@@ -55,17 +136,16 @@ func WithClientTimeout(timeout time.Duration) LoadTesterOption {
 	}
 }
 
+func WithSampler(sampler *RequestSampler) LoadTesterOption {
+	return func(lt *LoadTester) {
+		lt.sampler.Store(sampler)
+	}
+}
+
 func NewLoadTester(endpoint *Endpoint, metrics *Metrics, opts ...LoadTesterOption) *LoadTester {
 	timeout := 10 * time.Second
 	lt := &LoadTester{
-		client: &http.Client{
-			Timeout: timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 100,
-				IdleConnTimeout:     90 * time.Second,
-			},
-		},
+		client:      &http.Client{Timeout: timeout},
 		endpoint:    endpoint,
 		metrics:     metrics,
 		concurrency: 10,
@@ -74,12 +154,59 @@ func NewLoadTester(endpoint *Endpoint, metrics *Metrics, opts ...LoadTesterOptio
 		reqChan:     make(chan struct{}, 1000),
 		resultChan:  make(chan RequestResult, 1000),
 	}
+	if endpoint != nil && endpoint.Scenario != nil {
+		for range endpoint.Scenario.Steps {
+			lt.steps = append(lt.steps, NewMetrics(1000))
+		}
+	}
 	for _, opt := range opts {
 		opt(lt)
 	}
+	lt.Reload()
 	return lt
 }
 
+func (lt *LoadTester) Reload() {
+	var ep *Endpoint
+	if lt.endpoint != nil {
+		ep = lt.endpoint.settings()
+	}
+	next := &testerSettings{endpoint: ep}
+	old := lt.settings.Load()
+	if old != nil && old.sameTransport(ep) {
+		next.client, next.transports = old.client, old.transports
+	} else {
+		client := *lt.client
+		client.Transport = newTesterTransport(ep)
+		next.client = &client
+		if ep != nil {
+			for range ep.Connections {
+				next.transports = append(next.transports, newTesterTransport(ep))
+			}
+		}
+	}
+	lt.settings.Store(next)
+	if old != nil && old.client != next.client {
+		old.closeIdleConnections()
+	}
+}
+
+func newTesterTransport(ep *Endpoint) http.RoundTripper {
+	transport := &http.Transport{
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	if ep != nil {
+		transport.TLSClientConfig = ep.tlsConfig.Clone()
+		ep.Protocol.apply(transport)
+		if ep.Connections > 0 {
+			transport.MaxConnsPerHost = 1
+		}
+	}
+	return newUnixTransport(transport)
+}
+
 func (lt *LoadTester) Start() error {
 	lt.mu.Lock()
 	defer lt.mu.Unlock()
@@ -91,6 +218,7 @@ func (lt *LoadTester) Start() error {
 	lt.ctx, lt.cancel = context.WithCancel(context.Background())
 	lt.running.Store(true)
 	lt.requestsSent.Store(0)
+	lt.achieved.reset(time.Now())
 
 	for i := 0; i < lt.concurrency; i++ {
 		lt.wg.Add(1)
@@ -121,6 +249,12 @@ func (lt *LoadTester) Stop() {
 func (lt *LoadTester) worker(id int) {
 	defer lt.wg.Done()
 
+	var current *testerSettings
+	var client *http.Client
+	var jar http.CookieJar
+	ws := &wsSession{vu: id}
+	defer ws.close()
+
 	for {
 		select {
 		case <-lt.ctx.Done():
@@ -129,7 +263,34 @@ func (lt *LoadTester) worker(id int) {
 			if !ok {
 				return
 			}
-			result := lt.makeRequest()
+			if s := lt.settings.Load(); s != current {
+				current = s
+				client = current.client
+				if n := len(current.transports); n > 0 {
+					pinned := *client
+					pinned.Transport = current.transports[id%n]
+					client = &pinned
+				}
+				if ep := current.endpoint; ep != nil && ep.Scenario == nil && ep.Auth != nil && ep.Auth.Cookies {
+					if jar == nil {
+						jar, _ = cookiejar.New(nil)
+					}
+					session := *client
+					session.Jar = jar
+					client = &session
+				}
+				ws.endpoint, ws.timeout = current.endpoint, lt.timeout
+				if ep := current.endpoint; ep != nil && ep.Policy.Timeout > 0 {
+					ws.timeout = ep.Policy.Timeout
+				}
+			}
+
+			var result RequestResult
+			if ep := current.endpoint; ep != nil && ep.IsWebSocket() {
+				result = ws.exchange(lt.ctx)
+			} else {
+				result = lt.makeRequest(current.endpoint, client, id)
+			}
 			select {
 			case lt.resultChan <- result:
 			case <-lt.ctx.Done():
@@ -139,20 +300,34 @@ func (lt *LoadTester) worker(id int) {
 	}
 }
 
-func (lt *LoadTester) makeRequest() RequestResult {
+func (lt *LoadTester) makeRequest(ep *Endpoint, client *http.Client, vu int) RequestResult {
 	result := RequestResult{
 		Timestamp: time.Now(),
 	}
 
-	if lt.endpoint == nil {
+	if ep == nil {
 		result.IsError = true
 		result.ErrorMessage = "no endpoint configured"
 		return result
 	}
 
+	ctx := lt.ctx
+	policy := ep.EffectivePolicy()
+	if sc := ep.Scenario; sc != nil {
+		if ep.Policy.Timeout <= 0 {
+			policy.Timeout = lt.timeout
+		}
+		return lt.runScenario(ctx, client, sc, policy, vu)
+	}
+	if ep.Policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
 	start := time.Now()
 
-	req, err := http.NewRequestWithContext(lt.ctx, http.MethodGet, lt.endpoint.URL, nil)
+	req, err := newEndpointRequest(ctx, ep, vu)
 	if err != nil {
 		result.IsError = true
 		result.ErrorMessage = err.Error()
@@ -160,10 +335,7 @@ func (lt *LoadTester) makeRequest() RequestResult {
 		return result
 	}
 
-	req.Header.Set("User-Agent", "LocalPulse/1.0")
-	req.Header.Set("Accept", "*/*")
-
-	resp, err := lt.client.Do(req)
+	resp, err := client.Do(req)
 	result.Latency = time.Since(start)
 
 	if err != nil {
@@ -174,11 +346,38 @@ func (lt *LoadTester) makeRequest() RequestResult {
 	defer resp.Body.Close()
 
 	result.StatusCode = resp.StatusCode
+	result.Protocol = resp.Proto
 	result.Size, _ = io.Copy(io.Discard, resp.Body)
+	if err := policy.CheckStatus(resp.StatusCode); err != nil {
+		result.IsError = true
+		result.ErrorMessage = err.Error()
+	}
 
 	return result
 }
 
+func (lt *LoadTester) runScenario(ctx context.Context, client *http.Client, sc *Scenario, policy CheckPolicy, vu int) RequestResult {
+	it := sc.Run(ctx, client, policy, vu)
+	for i, step := range it.Steps {
+		if i < len(lt.steps) {
+			lt.steps[i].Record(step.Result)
+		}
+	}
+	return it.Result
+}
+
+func (lt *LoadTester) StepStats() []StepStats {
+	ep := lt.settings.Load().endpoint
+	if ep == nil || ep.Scenario == nil {
+		return nil
+	}
+	stats := make([]StepStats, 0, len(lt.steps))
+	for i, m := range lt.steps {
+		stats = append(stats, StepStats{Name: ep.Scenario.StepName(i), Stats: m.GetStats()})
+	}
+	return stats
+}
+
 func (lt *LoadTester) resultCollector() {
 	defer lt.wg.Done()
 
@@ -190,19 +389,32 @@ func (lt *LoadTester) resultCollector() {
 			if !ok {
 				return
 			}
+			lt.achieved.add(time.Now())
 			if lt.metrics != nil {
 				lt.metrics.Record(result)
 			}
-			lt.endpoint.Update(result.Latency, func() error {
-				if result.IsError {
-					return fmt.Errorf("request error: %s", result.ErrorMessage)
-				}
-				return nil
-			}())
+			current := lt.settings.Load().endpoint
+			if sampler := lt.sampler.Load(); sampler != nil {
+				sampler.Offer(current, result)
+			}
+			var err error
+			if result.IsError {
+				err = fmt.Errorf("request error: %s", result.ErrorMessage)
+			}
+			obs := observe(current.EffectivePolicy(), result.Latency, err)
+			obs.Proto = result.Protocol
+			lt.observation.Store(&obs)
 		}
 	}
 }
 
+func (lt *LoadTester) Observation() (Observation, bool) {
+	if obs := lt.observation.Load(); obs != nil {
+		return *obs, true
+	}
+	return Observation{}, false
+}
+
 func (lt *LoadTester) SendRequest() {
 	if !lt.running.Load() {
 		return
@@ -266,22 +478,48 @@ func (lt *LoadTester) RequestsSent() int64 {
 	return lt.requestsSent.Load()
 }
 
+func (lt *LoadTester) AchievedRate() float64 {
+	if !lt.running.Load() {
+		return 0
+	}
+	return lt.achieved.rate(time.Now())
+}
+
+type LoadRate struct {
+	RPS    float64
+	Weight float64
+}
+
+type EndpointRate struct {
+	URL      string
+	Target   float64
+	Achieved float64
+	RPS      float64
+	Weight   float64
+}
+
 type LoadGenerator struct {
 	mu sync.RWMutex
 
-	testers map[string]*LoadTester
-	rps     int
-	ticker  *time.Ticker
-	running atomic.Bool
-	ctx     context.Context
-	cancel  context.CancelFunc
-	wg      sync.WaitGroup
+	testers    map[string]*LoadTester
+	rates      map[string]LoadRate
+	retune     map[string]chan time.Duration
+	testerOpts []LoadTesterOption
+	sampler    *RequestSampler
+	rps        int
+	running    atomic.Bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
 }
 
-func NewLoadGenerator() *LoadGenerator {
+func NewLoadGenerator(opts ...LoadTesterOption) *LoadGenerator {
 	return &LoadGenerator{
-		testers: make(map[string]*LoadTester),
-		rps:     10,
+		testers:    make(map[string]*LoadTester),
+		rates:      make(map[string]LoadRate),
+		retune:     make(map[string]chan time.Duration),
+		rps:        10,
+		testerOpts: opts,
 	}
 }
 
@@ -289,8 +527,45 @@ func (lg *LoadGenerator) AddTester(endpoint *Endpoint, metrics *Metrics) {
 	lg.mu.Lock()
 	defer lg.mu.Unlock()
 
-	if _, exists := lg.testers[endpoint.URL]; !exists {
-		lg.testers[endpoint.URL] = NewLoadTester(endpoint, metrics)
+	if tester, exists := lg.testers[endpoint.URL]; exists {
+		if tester.endpoint == endpoint && tester.metrics == metrics {
+			tester.Reload()
+			return
+		}
+		tester.Stop()
+		delete(lg.testers, endpoint.URL)
+		delete(lg.retune, endpoint.URL)
+	}
+
+	opts := make([]LoadTesterOption, 0, len(lg.testerOpts)+1)
+	opts = append(opts, lg.testerOpts...)
+	opts = append(opts, WithSampler(lg.sampler))
+
+	tester := NewLoadTester(endpoint, metrics, opts...)
+	lg.testers[endpoint.URL] = tester
+
+	if lg.running.Load() {
+		lg.startGeneratorLocked(endpoint.URL, tester)
+		lg.retuneLocked()
+	}
+}
+
+func (lg *LoadGenerator) Reload(url string) {
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+
+	if tester, ok := lg.testers[url]; ok {
+		tester.Reload()
+	}
+}
+
+func (lg *LoadGenerator) SetSampler(sampler *RequestSampler) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+
+	lg.sampler = sampler
+	for _, tester := range lg.testers {
+		tester.sampler.Store(sampler)
 	}
 }
 
@@ -301,6 +576,8 @@ func (lg *LoadGenerator) RemoveTester(url string) {
 	if tester, exists := lg.testers[url]; exists {
 		tester.Stop()
 		delete(lg.testers, url)
+		delete(lg.retune, url)
+		lg.retuneLocked()
 	}
 }
 
@@ -319,33 +596,87 @@ func (lg *LoadGenerator) Start(rps int) error {
 	lg.ctx, lg.cancel = context.WithCancel(context.Background())
 	lg.running.Store(true)
 
-	for _, tester := range lg.testers {
-		if err := tester.Start(); err == nil {
-			lg.wg.Add(1)
-			go lg.runGenerator(tester)
-		}
+	for url, tester := range lg.testers {
+		lg.startGeneratorLocked(url, tester)
 	}
 
 	return nil
 }
 
-func (lg *LoadGenerator) runGenerator(tester *LoadTester) {
+func (lg *LoadGenerator) startGeneratorLocked(url string, tester *LoadTester) {
+	if err := tester.Start(); err != nil {
+		return
+	}
+	retune := make(chan time.Duration, 1)
+	lg.retune[url] = retune
+
+	lg.wg.Add(1)
+	go lg.runGenerator(tester, lg.intervalLocked(url), retune)
+}
+
+func (lg *LoadGenerator) runGenerator(tester *LoadTester, interval time.Duration, retune <-chan time.Duration) {
 	defer lg.wg.Done()
 
-	interval := time.Second / time.Duration(lg.rps)
-	lg.ticker = time.NewTicker(interval)
-	defer lg.ticker.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-lg.ctx.Done():
 			return
-		case <-lg.ticker.C:
+		case interval := <-retune:
+			ticker.Reset(interval)
+		case <-ticker.C:
+			if !tester.IsRunning() {
+				return
+			}
 			tester.SendRequest()
 		}
 	}
 }
 
+func (lg *LoadGenerator) targetLocked(url string) float64 {
+	rate := lg.rates[url]
+	if rate.RPS > 0 {
+		return rate.RPS
+	}
+	if rate.Weight <= 0 {
+		return float64(lg.rps)
+	}
+
+	total := 0.0
+	for u := range lg.testers {
+		if r := lg.rates[u]; r.RPS <= 0 && r.Weight > 0 {
+			total += r.Weight
+		}
+	}
+	if total <= 0 {
+		return float64(lg.rps)
+	}
+	return float64(lg.rps) * rate.Weight / total
+}
+
+const minSendInterval = time.Microsecond
+
+func (lg *LoadGenerator) intervalLocked(url string) time.Duration {
+	target := lg.targetLocked(url)
+	if !(target > 0) {
+		target = 1
+	}
+	return max(time.Duration(float64(time.Second)/target), minSendInterval)
+}
+
+func (lg *LoadGenerator) retuneLocked() {
+	for url, ch := range lg.retune {
+		interval := lg.intervalLocked(url)
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- interval
+	}
+}
+
 func (lg *LoadGenerator) Stop() {
 	lg.mu.Lock()
 	defer lg.mu.Unlock()
@@ -359,6 +690,7 @@ func (lg *LoadGenerator) Stop() {
 		lg.cancel()
 	}
 	lg.wg.Wait()
+	lg.retune = make(map[string]chan time.Duration)
 
 	for _, tester := range lg.testers {
 		tester.Stop()
@@ -373,22 +705,15 @@ func (lg *LoadGenerator) SetRPS(rps int) {
 		rps = 1
 	}
 	lg.rps = rps
-
-	if lg.ticker != nil {
-		interval := time.Second / time.Duration(rps)
-		lg.ticker.Reset(interval)
-	}
+	lg.retuneLocked()
 }
 
 func (lg *LoadGenerator) IncreaseRPS() {
-	lg.SetRPS(lg.rps + 5)
+	lg.SetRPS(lg.GetRPS() + 5)
 }
 
 func (lg *LoadGenerator) DecreaseRPS() {
-	lg.SetRPS(lg.rps - 5)
-	if lg.rps < 1 {
-		lg.SetRPS(1)
-	}
+	lg.SetRPS(lg.GetRPS() - 5)
 }
 
 func (lg *LoadGenerator) GetRPS() int {
@@ -397,6 +722,71 @@ func (lg *LoadGenerator) GetRPS() int {
 	return lg.rps
 }
 
+func (lg *LoadGenerator) SetRate(url string, rate LoadRate) {
+	lg.mu.Lock()
+	defer lg.mu.Unlock()
+
+	if rate.RPS < 0 {
+		rate.RPS = 0
+	}
+	if rate.Weight < 0 {
+		rate.Weight = 0
+	}
+	if rate == (LoadRate{}) {
+		delete(lg.rates, url)
+	} else {
+		lg.rates[url] = rate
+	}
+	lg.retuneLocked()
+}
+
+func (lg *LoadGenerator) GetRate(url string) LoadRate {
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+	return lg.rates[url]
+}
+
+func (lg *LoadGenerator) Rates() map[string]EndpointRate {
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+
+	rates := make(map[string]EndpointRate, len(lg.testers))
+	for url, tester := range lg.testers {
+		rate := lg.rates[url]
+		rates[url] = EndpointRate{
+			URL:      url,
+			Target:   lg.targetLocked(url),
+			Achieved: tester.AchievedRate(),
+			RPS:      rate.RPS,
+			Weight:   rate.Weight,
+		}
+	}
+	return rates
+}
+
+func (lg *LoadGenerator) Observations() map[string]Observation {
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+
+	observations := make(map[string]Observation, len(lg.testers))
+	for url, tester := range lg.testers {
+		if obs, ok := tester.Observation(); ok {
+			observations[url] = obs
+		}
+	}
+	return observations
+}
+
+func (lg *LoadGenerator) StepStats(url string) []StepStats {
+	lg.mu.RLock()
+	defer lg.mu.RUnlock()
+
+	if tester, ok := lg.testers[url]; ok {
+		return tester.StepStats()
+	}
+	return nil
+}
+
 func (lg *LoadGenerator) IsRunning() bool {
 	return lg.running.Load()
 }
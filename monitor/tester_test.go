@@ -1,8 +1,10 @@
 package monitor
 
 import (
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -259,3 +261,173 @@ func TestLoadTester_ErrorHandling(t *testing.T) {
 		t.Error("error should have been recorded")
 	}
 }
+
+func TestLoadGenerator_AddTesterWhileRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	lg := NewLoadGenerator()
+	if err := lg.Start(50); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer lg.Stop()
+
+	ep, _ := NewEndpoint(srv.URL)
+	metrics := NewMetrics(1000)
+	lg.AddTester(ep, metrics)
+
+	time.Sleep(200 * time.Millisecond)
+
+	if metrics.GetStats().TotalRequests == 0 {
+		t.Error("tester added while running sent no requests")
+	}
+}
+
+func TestLoadTester_RejectedStatusIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(srv.URL)
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(1))
+
+	if err := lt.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	lt.SendBurst(1)
+	time.Sleep(200 * time.Millisecond)
+	lt.Stop()
+
+	stats := metrics.GetStats()
+	if stats.TotalErrors != 1 || stats.StatusCode5xx != 1 {
+		t.Errorf("errors = %d, 5xx = %d, want the 503 counted as one error", stats.TotalErrors, stats.StatusCode5xx)
+	}
+	if obs, _ := lt.Observation(); obs.Status != StatusDown {
+		t.Errorf("Status = %v, want %v", obs.Status, StatusDown)
+	}
+	if ep.Status != StatusUnknown {
+		t.Errorf("endpoint Status = %v, want it left for the caller to apply", ep.Status)
+	}
+}
+
+func TestLoadGenerator_WeightedRates(t *testing.T) {
+	lg := NewLoadGenerator()
+	lg.SetRPS(100)
+
+	weights := map[string]float64{
+		"http://localhost:8080/read":   70,
+		"http://localhost:8080/search": 25,
+		"http://localhost:8080/write":  5,
+	}
+	for url, w := range weights {
+		ep, _ := NewEndpoint(url)
+		lg.AddTester(ep, NewMetrics(10))
+		lg.SetRate(url, LoadRate{Weight: w})
+	}
+	fixed, _ := NewEndpoint("http://localhost:8080/fixed")
+	lg.AddTester(fixed, NewMetrics(10))
+	lg.SetRate(fixed.URL, LoadRate{RPS: 12, Weight: 50})
+	plain, _ := NewEndpoint("http://localhost:8080/plain")
+	lg.AddTester(plain, NewMetrics(10))
+
+	rates := lg.Rates()
+	for url, w := range weights {
+		if got := rates[url].Target; math.Abs(got-w) > 0.001 {
+			t.Errorf("%s target = %.2f, want %.2f", url, got, w)
+		}
+	}
+	if got := rates[fixed.URL].Target; got != 12 {
+		t.Errorf("fixed target = %.2f, want 12", got)
+	}
+	if got := rates[plain.URL].Target; got != 100 {
+		t.Errorf("unweighted target = %.2f, want the full 100", got)
+	}
+
+	lg.RemoveTester("http://localhost:8080/write")
+	if got := lg.Rates()["http://localhost:8080/read"].Target; math.Abs(got-100*70.0/95) > 0.001 {
+		t.Errorf("read target after removal = %.2f, want %.2f", got, 100*70.0/95)
+	}
+
+	lg.SetRate(fixed.URL, LoadRate{})
+	if got := lg.GetRate(fixed.URL); got != (LoadRate{}) {
+		t.Errorf("GetRate() after reset = %+v, want zero", got)
+	}
+
+	for _, rps := range []float64{1e12, math.Inf(1), math.NaN()} {
+		lg.SetRate(fixed.URL, LoadRate{RPS: rps})
+		if got := lg.intervalLocked(fixed.URL); got <= 0 {
+			t.Errorf("interval for rps %v = %v, want a positive ticker interval", rps, got)
+		}
+	}
+}
+
+func TestLoadGenerator_SetRPSRetunesAllTesters(t *testing.T) {
+	var hits [2]atomic.Int64
+	var urls [2]string
+	for i := range hits {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i].Add(1)
+		}))
+		defer srv.Close()
+		urls[i] = srv.URL
+	}
+
+	lg := NewLoadGenerator()
+	for _, url := range urls {
+		ep, _ := NewEndpoint(url)
+		lg.AddTester(ep, NewMetrics(1000))
+	}
+	if err := lg.Start(1); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer lg.Stop()
+
+	lg.SetRPS(100)
+	time.Sleep(500 * time.Millisecond)
+
+	for i := range hits {
+		if got := hits[i].Load(); got < 20 {
+			t.Errorf("endpoint %d got %d requests in 500ms at 100 req/s, want the new rate applied", i, got)
+		}
+	}
+	for url, rate := range lg.Rates() {
+		if rate.Achieved <= 0 || rate.Target != 100 {
+			t.Errorf("%s achieved/target = %.1f/%.1f", url, rate.Achieved, rate.Target)
+		}
+	}
+}
+
+func TestLoadGenerator_ReloadWhileRunning(t *testing.T) {
+	var latest atomic.Value
+	latest.Store("")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		latest.Store(r.Header.Get("Authorization") + " " + r.Header.Get("X-Rev"))
+	}))
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(srv.URL)
+	lg := NewLoadGenerator(WithConcurrency(4))
+	lg.AddTester(ep, NewMetrics(1000))
+	if err := lg.Start(200); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer lg.Stop()
+
+	for i := range 20 {
+		rev := strconv.Itoa(i)
+		ep.Policy = CheckPolicy{SlowThreshold: time.Duration(i+1) * time.Second}
+		ep.Request = &RequestTemplate{URL: ep.URL, Headers: map[string]string{"X-Rev": rev}}
+		ep.Auth = &Auth{Type: AuthBearer, Token: "tok" + rev}
+		lg.Reload(ep.URL)
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if got := latest.Load(); got != "Bearer tok19 19" {
+		t.Errorf("latest request carried %q, want the reloaded auth and headers", got)
+	}
+}
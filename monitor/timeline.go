@@ -0,0 +1,278 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+)
+
+type StatusChange struct {
+	Time   time.Time
+	From   EndpointStatus
+	Status EndpointStatus
+}
+
+type TimelineSummary struct {
+	Status        EndpointStatus
+	Since         time.Time
+	Uptime1h      float64
+	Uptime24h     float64
+	UptimeSession float64
+	MTTR          time.Duration
+	Recoveries    int
+	Transitions   int
+}
+
+type Timeline struct {
+	mu sync.Mutex
+
+	changes   []StatusChange
+	retention time.Duration
+	now       func() time.Time
+
+	sessionUp     time.Duration
+	sessionTotal  time.Duration
+	transitions   int
+	recoveries    int
+	recoveryTotal time.Duration
+	downSince     time.Time
+}
+
+type TimelineOption func(*Timeline)
+
+func WithTimelineClock(now func() time.Time) TimelineOption {
+	return func(t *Timeline) {
+		t.now = now
+	}
+}
+
+func WithRetention(retention time.Duration) TimelineOption {
+	return func(t *Timeline) {
+		t.retention = retention
+	}
+}
+
+func NewTimeline(opts ...TimelineOption) *Timeline {
+	t := &Timeline{
+		retention: 24 * time.Hour,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func isUp(status EndpointStatus) bool {
+	return status == StatusHealthy || status == StatusSlow
+}
+
+func (t *Timeline) Record(status EndpointStatus) (EndpointStatus, bool) {
+	if status == StatusUnknown {
+		return t.Current(), false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	prev := StatusUnknown
+	if n := len(t.changes); n > 0 {
+		last := t.changes[n-1]
+		if last.Status == status {
+			return status, false
+		}
+		prev = last.Status
+
+		segment := now.Sub(last.Time)
+		t.sessionTotal += segment
+		if isUp(prev) {
+			t.sessionUp += segment
+		}
+		t.transitions++
+	}
+
+	switch {
+	case status == StatusDown:
+		t.downSince = now
+	case prev == StatusDown:
+		t.recoveries++
+		t.recoveryTotal += now.Sub(t.downSince)
+	}
+
+	t.changes = append(t.changes, StatusChange{Time: now, From: prev, Status: status})
+	t.prune(now)
+	return prev, true
+}
+
+func (t *Timeline) prune(now time.Time) {
+	cutoff := now.Add(-t.retention)
+	drop := 0
+	for drop < len(t.changes)-1 && !t.changes[drop+1].Time.After(cutoff) {
+		drop++
+	}
+	if drop > 0 {
+		t.changes = append(t.changes[:0], t.changes[drop:]...)
+	}
+}
+
+func (t *Timeline) Current() EndpointStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.changes) == 0 {
+		return StatusUnknown
+	}
+	return t.changes[len(t.changes)-1].Status
+}
+
+func (t *Timeline) Changes() []StatusChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]StatusChange(nil), t.changes...)
+}
+
+func (t *Timeline) Uptime(window time.Duration) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.uptime(window, t.now())
+}
+
+func (t *Timeline) uptime(window time.Duration, now time.Time) float64 {
+	if len(t.changes) == 0 {
+		return 0
+	}
+
+	var up, total time.Duration
+	if window <= 0 {
+		last := t.changes[len(t.changes)-1]
+		up, total = t.sessionUp, t.sessionTotal
+		total += now.Sub(last.Time)
+		if isUp(last.Status) {
+			up += now.Sub(last.Time)
+		}
+	} else {
+		from := now.Add(-window)
+		for i, c := range t.changes {
+			start, end := c.Time, now
+			if i+1 < len(t.changes) {
+				end = t.changes[i+1].Time
+			}
+			if start.Before(from) {
+				start = from
+			}
+			if !end.After(start) {
+				continue
+			}
+			total += end.Sub(start)
+			if isUp(c.Status) {
+				up += end.Sub(start)
+			}
+		}
+	}
+
+	if total <= 0 {
+		if isUp(t.changes[len(t.changes)-1].Status) {
+			return 100
+		}
+		return 0
+	}
+	return float64(up) / float64(total) * 100
+}
+
+func (t *Timeline) Transitions(window time.Duration) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	from := t.now().Add(-window)
+	count := 0
+	for _, c := range t.changes {
+		if c.From != StatusUnknown && c.Time.After(from) {
+			count++
+		}
+	}
+	return count
+}
+
+func (t *Timeline) Flapping(threshold int, window time.Duration) bool {
+	if threshold <= 0 || window <= 0 {
+		return false
+	}
+	return t.Transitions(window) > threshold
+}
+
+func (t *Timeline) MTTR() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.recoveries == 0 {
+		return 0
+	}
+	return t.recoveryTotal / time.Duration(t.recoveries)
+}
+
+func (t *Timeline) Summary() TimelineSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	s := TimelineSummary{
+		Status:        StatusUnknown,
+		Uptime1h:      t.uptime(time.Hour, now),
+		Uptime24h:     t.uptime(24*time.Hour, now),
+		UptimeSession: t.uptime(0, now),
+		Recoveries:    t.recoveries,
+		Transitions:   t.transitions,
+	}
+	if n := len(t.changes); n > 0 {
+		s.Status = t.changes[n-1].Status
+		s.Since = t.changes[n-1].Time
+	}
+	if t.recoveries > 0 {
+		s.MTTR = t.recoveryTotal / time.Duration(t.recoveries)
+	}
+	return s
+}
+
+func (t *Timeline) Strip(slots int, window time.Duration) []EndpointStatus {
+	strip := make([]EndpointStatus, slots)
+	for i := range strip {
+		strip[i] = StatusUnknown
+	}
+	if slots <= 0 || window <= 0 {
+		return strip
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	from := now.Add(-window)
+	slot := window / time.Duration(slots)
+	for i, c := range t.changes {
+		end := now
+		if i+1 < len(t.changes) {
+			end = t.changes[i+1].Time
+		}
+		if !end.After(from) {
+			continue
+		}
+
+		first := int(c.Time.Sub(from) / slot)
+		last := int((end.Sub(from) - 1) / slot)
+		if i == len(t.changes)-1 {
+			last = slots - 1
+		}
+		if first < 0 {
+			first = 0
+		}
+		if first > last {
+			first = last
+		}
+		for s := first; s <= last; s++ {
+			strip[s] = worseStatus(strip[s], c.Status)
+		}
+	}
+	return strip
+}
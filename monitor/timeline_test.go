@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestTimeline(opts ...TimelineOption) (*Timeline, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	return NewTimeline(append([]TimelineOption{WithTimelineClock(clock.Now)}, opts...)...), clock
+}
+
+func TestTimeline_Record(t *testing.T) {
+	tl, clock := newTestTimeline()
+
+	if prev, changed := tl.Record(StatusHealthy); !changed || prev != StatusUnknown {
+		t.Errorf("first Record() = %v, %v, want unknown, true", prev, changed)
+	}
+	clock.Advance(time.Second)
+	if _, changed := tl.Record(StatusHealthy); changed {
+		t.Error("same status reported as a change")
+	}
+	if _, changed := tl.Record(StatusUnknown); changed {
+		t.Error("unknown status reported as a change")
+	}
+	clock.Advance(time.Second)
+	if prev, changed := tl.Record(StatusDown); !changed || prev != StatusHealthy {
+		t.Errorf("Record(down) = %v, %v, want healthy, true", prev, changed)
+	}
+
+	changes := tl.Changes()
+	if len(changes) != 2 || changes[1].From != StatusHealthy || changes[1].Status != StatusDown {
+		t.Errorf("Changes() = %+v", changes)
+	}
+	if tl.Current() != StatusDown {
+		t.Errorf("Current() = %v, want down", tl.Current())
+	}
+}
+
+func TestTimeline_Uptime(t *testing.T) {
+	tl, clock := newTestTimeline()
+
+	tl.Record(StatusHealthy)
+	clock.Advance(90 * time.Minute)
+	tl.Record(StatusDown)
+	clock.Advance(15 * time.Minute)
+	tl.Record(StatusSlow)
+	clock.Advance(15 * time.Minute)
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		want   float64
+	}{
+		{"session", 0, 87.5},
+		{"1h", time.Hour, 75},
+		{"24h", 24 * time.Hour, 87.5},
+		{"15m", 15 * time.Minute, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tl.Uptime(tt.window); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Uptime(%v) = %.2f, want %.2f", tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeline_MTTR(t *testing.T) {
+	tl, clock := newTestTimeline()
+
+	if tl.MTTR() != 0 {
+		t.Errorf("MTTR() = %v before any recovery, want 0", tl.MTTR())
+	}
+
+	tl.Record(StatusHealthy)
+	for _, outage := range []time.Duration{10 * time.Second, 30 * time.Second} {
+		clock.Advance(time.Minute)
+		tl.Record(StatusDown)
+		clock.Advance(outage)
+		tl.Record(StatusHealthy)
+	}
+	clock.Advance(time.Minute)
+	tl.Record(StatusDown)
+
+	if got := tl.MTTR(); got != 20*time.Second {
+		t.Errorf("MTTR() = %v, want 20s", got)
+	}
+	s := tl.Summary()
+	if s.Recoveries != 2 || s.Transitions != 5 || s.Status != StatusDown {
+		t.Errorf("Summary() = %+v", s)
+	}
+}
+
+func TestTimeline_Flapping(t *testing.T) {
+	tl, clock := newTestTimeline()
+
+	tl.Record(StatusHealthy)
+	for i := 0; i < 3; i++ {
+		clock.Advance(90 * time.Second)
+		tl.Record(StatusDown)
+		clock.Advance(5 * time.Second)
+		tl.Record(StatusHealthy)
+	}
+
+	if got := tl.Transitions(5 * time.Minute); got != 6 {
+		t.Errorf("Transitions(5m) = %d, want 6", got)
+	}
+	if !tl.Flapping(4, 5*time.Minute) {
+		t.Error("Flapping() = false with 6 changes in 5m and a threshold of 4")
+	}
+	if tl.Flapping(6, 5*time.Minute) {
+		t.Error("Flapping() = true with 6 changes and a threshold of 6")
+	}
+
+	clock.Advance(10 * time.Minute)
+	if tl.Flapping(4, 5*time.Minute) {
+		t.Error("Flapping() = true after 10 stable minutes")
+	}
+}
+
+func TestTimeline_Strip(t *testing.T) {
+	tl, clock := newTestTimeline()
+
+	tl.Record(StatusHealthy)
+	clock.Advance(3 * time.Minute)
+	tl.Record(StatusDown)
+	clock.Advance(time.Minute)
+	tl.Record(StatusHealthy)
+	clock.Advance(time.Minute)
+
+	got := tl.Strip(6, 6*time.Minute)
+	want := []EndpointStatus{StatusUnknown, StatusHealthy, StatusHealthy, StatusHealthy, StatusDown, StatusHealthy}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Strip() = %v, want %v", got, want)
+		}
+	}
+
+	tl.Record(StatusSlow)
+	if got := tl.Strip(6, 6*time.Minute); got[5] != StatusSlow {
+		t.Errorf("last slot = %v after a change just now, want slow", got[5])
+	}
+}
+
+func TestTimeline_Retention(t *testing.T) {
+	tl, clock := newTestTimeline(WithRetention(time.Hour))
+
+	tl.Record(StatusHealthy)
+	clock.Advance(2 * time.Hour)
+	tl.Record(StatusDown)
+	clock.Advance(2 * time.Hour)
+	tl.Record(StatusHealthy)
+
+	changes := tl.Changes()
+	if len(changes) != 2 || changes[0].Status != StatusDown {
+		t.Errorf("Changes() = %+v, want the outage in effect at the cutoff and the recovery", changes)
+	}
+	if got := tl.Uptime(0); math.Abs(got-50) > 0.01 {
+		t.Errorf("session Uptime() = %.2f after pruning, want 50", got)
+	}
+}
@@ -0,0 +1,105 @@
+package monitor
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type TLSOptions struct {
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+	MinVersion string
+	Insecure   bool
+}
+
+func (o TLSOptions) IsZero() bool {
+	return o == TLSOptions{}
+}
+
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.Insecure,
+	}
+
+	version, err := ParseTLSVersion(o.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MinVersion = version
+
+	if o.CAFile != "" {
+		data, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA bundle %s has no PEM certificates", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	switch {
+	case o.CertFile != "" && o.KeyFile != "":
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case o.CertFile != "" || o.KeyFile != "":
+		return nil, fmt.Errorf("client certificates need both a cert file and a key file")
+	}
+	return cfg, nil
+}
+
+func ParseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "tls") {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q, want 1.0, 1.1, 1.2 or 1.3", s)
+}
+
+func UntrustedCert(err error) string {
+	var verr *tls.CertificateVerificationError
+	if err == nil || !errors.As(err, &verr) {
+		return ""
+	}
+	var hostErr x509.HostnameError
+	var authErr x509.UnknownAuthorityError
+	var certErr x509.CertificateInvalidError
+	switch {
+	case errors.As(verr.Err, &hostErr):
+		return "certificate is not valid for " + hostErr.Host
+	case errors.As(verr.Err, &authErr):
+		return "certificate signed by an unknown authority"
+	case errors.As(verr.Err, &certErr) && certErr.Reason == x509.Expired:
+		return "certificate has expired or is not yet valid"
+	}
+	return verr.Err.Error()
+}
+
+func newTLSTransport(cfg *tls.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg.Clone()
+	return transport
+}
@@ -0,0 +1,177 @@
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeServerCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeClientCert(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localpulse-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	cert, _ = x509.ParseCertificate(der)
+	return certFile, keyFile, cert
+}
+
+func TestTLSOptions_Config(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0o600)
+
+	tests := []struct {
+		name    string
+		opts    TLSOptions
+		wantErr string
+	}{
+		{"unknown version", TLSOptions{MinVersion: "1.4"}, "unknown TLS version"},
+		{"cert without key", TLSOptions{CertFile: "client.pem"}, "need both a cert file and a key file"},
+		{"missing CA file", TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}, "CA bundle"},
+		{"CA file without certificates", TLSOptions{CAFile: notPEM}, "has no PEM certificates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.opts.Config(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Config() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg, err := TLSOptions{MinVersion: "TLS1.3", ServerName: "api.local", Insecure: true}.Config()
+	if err != nil || cfg.MinVersion != tls.VersionTLS13 || cfg.ServerName != "api.local" || !cfg.InsecureSkipVerify {
+		t.Errorf("Config() = %+v, %v", cfg, err)
+	}
+}
+
+func TestCheckEndpoints_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ca := writeServerCA(t, srv)
+
+	tests := []struct {
+		name     string
+		opts     TLSOptions
+		want     EndpointStatus
+		tlsError string
+	}{
+		{"system roots", TLSOptions{}, StatusDown, "certificate signed by an unknown authority"},
+		{"custom CA", TLSOptions{CAFile: ca}, StatusHealthy, ""},
+		{"server name mismatch", TLSOptions{CAFile: ca, ServerName: "api.local"}, StatusDown, "certificate is not valid for api.local"},
+		{"insecure", TLSOptions{Insecure: true}, StatusHealthy, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep, _ := NewEndpoint(srv.URL)
+			if err := ep.SetTLS(tt.opts); err != nil {
+				t.Fatalf("SetTLS() error = %v", err)
+			}
+			last := CheckEndpoints(context.Background(), []*Endpoint{ep})[0].Last
+			if last.Status != tt.want || last.TLSError != tt.tlsError {
+				t.Errorf("status = %v, TLSError = %q, want %v and %q", last.Status, last.TLSError, tt.want, tt.tlsError)
+			}
+			if ep.Status != StatusUnknown || !ep.LastCheck.IsZero() {
+				t.Errorf("CheckEndpoints changed the endpoint: status %v, last check %v", ep.Status, ep.LastCheck)
+			}
+			ep.Apply(last)
+			if ep.Status != tt.want || ep.TLSError != tt.tlsError {
+				t.Errorf("after Apply status = %v, TLSError = %q, want %v and %q", ep.Status, ep.TLSError, tt.want, tt.tlsError)
+			}
+		})
+	}
+}
+
+func TestLoadTester_ClientCertificate(t *testing.T) {
+	certFile, keyFile, clientCert := writeClientCert(t)
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(srv.URL)
+	if err := ep.SetTLS(TLSOptions{CAFile: writeServerCA(t, srv), CertFile: certFile, KeyFile: keyFile}); err != nil {
+		t.Fatalf("SetTLS() error = %v", err)
+	}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+	lt.Start()
+	lt.SendBurst(3)
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	if got := metrics.GetStats(); got.TotalRequests != 3 || got.TotalErrors != 0 {
+		t.Errorf("requests = %d, errors = %d, want 3 clean mTLS requests", got.TotalRequests, got.TotalErrors)
+	}
+}
+
+func TestScanner_ReportUntrusted(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	port := extractPort(srv)
+
+	hidden := NewScanner(WithHost("127.0.0.1"), WithPorts([]int{port}))
+	if eps := hidden.DiscoverEndpoints(context.Background()); len(eps) != 0 {
+		t.Errorf("DiscoverEndpoints() = %d endpoints, want the untrusted server hidden by default", len(eps))
+	}
+
+	s := NewScanner(WithHost("127.0.0.1"), WithPorts([]int{port}), WithReportUntrusted(true))
+	eps := s.DiscoverEndpoints(context.Background())
+	if len(eps) != 1 || !eps[0].IsHTTPS || eps[0].TLSError != "certificate signed by an unknown authority" {
+		t.Fatalf("DiscoverEndpoints() = %+v, want the HTTPS endpoint with an untrusted certificate", eps)
+	}
+
+	if err := s.SetTLS(TLSOptions{CAFile: writeServerCA(t, srv)}); err != nil {
+		t.Fatalf("SetTLS() error = %v", err)
+	}
+	for _, ep := range s.DiscoverEndpoints(context.Background()) {
+		if ep.TLSError != "" || ep.Status == StatusDown {
+			t.Errorf("%s: status %v, TLSError %q after trusting the CA", ep.URL, ep.Status, ep.TLSError)
+		}
+	}
+}
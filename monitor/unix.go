@@ -0,0 +1,111 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+func ParseUnixURL(rawURL string) (socket, path string, err error) {
+	rest, ok := strings.CutPrefix(rawURL, "unix://")
+	if !ok {
+		return "", "", fmt.Errorf("%s is not a unix:// URL", rawURL)
+	}
+	socket, path, _ = strings.Cut(rest, ":")
+	if !filepath.IsAbs(socket) {
+		return "", "", fmt.Errorf("unix socket URL %q needs an absolute socket path, e.g. unix:///run/app.sock:/health", rawURL)
+	}
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "", "", fmt.Errorf("unix socket URL %q: HTTP path must start with /", rawURL)
+	}
+	return socket, path, nil
+}
+
+func UnixURL(socket, path string) string {
+	if path == "" {
+		path = "/"
+	}
+	return "unix://" + socket + ":" + path
+}
+
+type unixTransport struct {
+	mu      sync.Mutex
+	base    *http.Transport
+	sockets map[string]*http.Transport
+}
+
+func newUnixTransport(base *http.Transport) *unixTransport {
+	return &unixTransport{base: base, sockets: make(map[string]*http.Transport)}
+}
+
+func (t *unixTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "unix" {
+		return t.base.RoundTrip(req)
+	}
+	socket, _, _ := strings.Cut(req.URL.Path, ":")
+	_, path, _ := strings.Cut(req.URL.EscapedPath(), ":")
+	if !filepath.IsAbs(socket) {
+		return nil, fmt.Errorf("unix socket URL %q needs an absolute socket path", req.URL.String())
+	}
+	target, err := url.Parse("http://localhost" + path)
+	if err != nil {
+		return nil, err
+	}
+	target.RawQuery = req.URL.RawQuery
+
+	out := req.Clone(req.Context())
+	out.URL = target
+	out.Host = "localhost"
+	return t.forSocket(socket).RoundTrip(out)
+}
+
+func (t *unixTransport) forSocket(socket string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if transport, ok := t.sockets[socket]; ok {
+		return transport
+	}
+	transport := t.base.Clone()
+	var dialer net.Dialer
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", socket)
+	}
+	t.sockets[socket] = transport
+	return transport
+}
+
+func (t *unixTransport) CloseIdleConnections() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.base.CloseIdleConnections()
+	for _, transport := range t.sockets {
+		transport.CloseIdleConnections()
+	}
+}
+
+func FindSockets(dirs []string) []string {
+	var sockets []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSocket != 0 {
+				sockets = append(sockets, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(sockets)
+	return sockets
+}
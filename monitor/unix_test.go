@@ -0,0 +1,124 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newUnixServer(t *testing.T, dir string, handler http.Handler) string {
+	t.Helper()
+	socket := filepath.Join(dir, "app.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = ln
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return socket
+}
+
+func socketDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "lp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestParseUnixURL(t *testing.T) {
+	tests := []struct {
+		raw     string
+		socket  string
+		path    string
+		wantErr string
+	}{
+		{"unix:///var/run/docker.sock:/v1.43/info", "/var/run/docker.sock", "/v1.43/info", ""},
+		{"unix:///run/app.sock", "/run/app.sock", "/", ""},
+		{"unix:///run/app.sock:/health?full=1", "/run/app.sock", "/health?full=1", ""},
+		{"unix://run/app.sock:/health", "", "", "absolute socket path"},
+		{"unix:///run/app.sock:health", "", "", "must start with /"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			socket, path, err := ParseUnixURL(tt.raw)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseUnixURL() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || socket != tt.socket || path != tt.path {
+				t.Errorf("ParseUnixURL() = %q, %q, %v, want %q, %q", socket, path, err, tt.socket, tt.path)
+			}
+		})
+	}
+
+	ep, err := NewEndpoint("unix:///var/run/docker.sock:/_ping")
+	if err != nil || ep.Name != "docker.sock/_ping" || ep.Socket != "/var/run/docker.sock" || ep.IsHTTPS {
+		t.Errorf("NewEndpoint() = %+v, %v", ep, err)
+	}
+}
+
+func TestUnixEndpoints_CheckAndLoad(t *testing.T) {
+	socket := newUnixServer(t, socketDir(t), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" || r.URL.RawQuery != "full=1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	ep, err := NewEndpoint(UnixURL(socket, "/health?full=1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := CheckEndpoints(context.Background(), []*Endpoint{ep})
+	if results[0].Status != StatusHealthy || results[0].StatusCodes[200] != 1 {
+		t.Errorf("CheckEndpoints() = %+v, want a healthy 200 over the socket", results[0])
+	}
+
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+	lt.Start()
+	lt.SendBurst(5)
+	time.Sleep(200 * time.Millisecond)
+	lt.Stop()
+	if got := metrics.GetStats(); got.TotalRequests != 5 || got.TotalErrors != 0 {
+		t.Errorf("requests = %d, errors = %d, want 5 clean requests", got.TotalRequests, got.TotalErrors)
+	}
+
+	missing, _ := NewEndpoint(UnixURL(filepath.Join(filepath.Dir(socket), "missing.sock"), "/"))
+	if results := CheckEndpoints(context.Background(), []*Endpoint{missing}); results[0].Status != StatusDown {
+		t.Errorf("missing socket status = %v, want down", results[0].Status)
+	}
+}
+
+func TestScanner_DiscoverSockets(t *testing.T) {
+	dir := socketDir(t)
+	socket := newUnixServer(t, dir, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	os.WriteFile(filepath.Join(dir, "not-a-socket"), nil, 0o600)
+
+	if got := FindSockets([]string{dir, filepath.Join(dir, "missing")}); !slices.Equal(got, []string{socket}) {
+		t.Errorf("FindSockets() = %v, want [%s]", got, socket)
+	}
+
+	s := NewScanner(WithPorts(nil), WithSocketDirs([]string{dir}))
+	eps := s.DiscoverEndpoints(context.Background())
+	if len(eps) != 1 || eps[0].URL != UnixURL(socket, "/healthz") || eps[0].Status != StatusHealthy {
+		t.Fatalf("DiscoverEndpoints() = %+v, want the socket's /healthz", eps)
+	}
+}
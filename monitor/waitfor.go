@@ -0,0 +1,204 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type WaitTarget struct {
+	Name     string
+	URL      string
+	Host     string
+	Port     int
+	Endpoint *Endpoint
+}
+
+type WaitResult struct {
+	Target     WaitTarget
+	Status     EndpointStatus
+	Ready      bool
+	Attempts   int
+	Latency    time.Duration
+	ReadyAfter time.Duration
+	LastError  string
+}
+
+type waitOptions struct {
+	interval  time.Duration
+	timeout   time.Duration
+	allowSlow bool
+	onReady   func(WaitResult)
+}
+
+type WaitOption func(*waitOptions)
+
+func WithWaitInterval(interval time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		if interval > 0 {
+			o.interval = interval
+		}
+	}
+}
+
+func WithRequestTimeout(timeout time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+func WithAllowSlow(allow bool) WaitOption {
+	return func(o *waitOptions) {
+		o.allowSlow = allow
+	}
+}
+
+func WithOnReady(fn func(WaitResult)) WaitOption {
+	return func(o *waitOptions) {
+		o.onReady = fn
+	}
+}
+
+func ParseWaitTarget(arg, defaultHost string) (WaitTarget, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return WaitTarget{}, fmt.Errorf("empty target")
+	}
+
+	if strings.Contains(arg, "://") {
+		ep, err := NewEndpoint(arg)
+		if err != nil {
+			return WaitTarget{}, fmt.Errorf("invalid URL %q: %w", arg, err)
+		}
+		return WaitTarget{Name: ep.URL, URL: ep.URL}, nil
+	}
+
+	host, portStr := defaultHost, strings.TrimPrefix(arg, ":")
+	if strings.Contains(portStr, ":") {
+		var err error
+		host, portStr, err = net.SplitHostPort(arg)
+		if err != nil {
+			return WaitTarget{}, fmt.Errorf("invalid target %q: %w", arg, err)
+		}
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return WaitTarget{}, fmt.Errorf("invalid target %q: expected a URL, PORT or HOST:PORT", arg)
+	}
+	return WaitTarget{Name: net.JoinHostPort(host, portStr), Host: host, Port: port}, nil
+}
+
+func WaitFor(ctx context.Context, targets []WaitTarget, opts ...WaitOption) []WaitResult {
+	options := waitOptions{
+		interval: 500 * time.Millisecond,
+		timeout:  2 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	client := &http.Client{Transport: newUnixTransport(newTLSTransport(nil))}
+	results := make([]WaitResult, len(targets))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target WaitTarget) {
+			defer wg.Done()
+
+			result := waitForTarget(ctx, client, target, options, start)
+			results[i] = result
+			if result.Ready && options.onReady != nil {
+				mu.Lock()
+				options.onReady(result)
+				mu.Unlock()
+			}
+		}(i, target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func waitForTarget(ctx context.Context, client *http.Client, target WaitTarget, options waitOptions, start time.Time) WaitResult {
+	result := WaitResult{Target: target, Status: StatusUnknown}
+	ep := target.Endpoint
+	if ep == nil {
+		ep = &Endpoint{URL: target.URL, Name: target.Name}
+	}
+	policy := ep.EffectivePolicy()
+	if ep.Policy.Timeout <= 0 {
+		policy.Timeout = options.timeout
+	}
+
+	ticker := time.NewTicker(options.interval)
+	defer ticker.Stop()
+
+	for {
+		latency, err := probeTarget(ctx, client, target, ep, policy)
+		if ctx.Err() != nil {
+			return result
+		}
+
+		result.Attempts++
+		result.Status = policy.Classify(latency, err)
+		result.Latency = latency
+		if err != nil {
+			result.LastError = err.Error()
+		} else {
+			result.LastError = ""
+		}
+
+		if result.Status == StatusHealthy || (options.allowSlow && result.Status == StatusSlow) {
+			result.Ready = true
+			result.ReadyAfter = time.Since(start)
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-ticker.C:
+		}
+	}
+}
+
+func probeTarget(ctx context.Context, client *http.Client, target WaitTarget, ep *Endpoint, policy CheckPolicy) (time.Duration, error) {
+	start := time.Now()
+
+	if target.URL == "" {
+		if !CheckPort(target.Host, target.Port, policy.Timeout) {
+			return time.Since(start), fmt.Errorf("port %d not accepting connections", target.Port)
+		}
+		return time.Since(start), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout)
+	defer cancel()
+	if ep.IsWebSocket() {
+		return checkWebSocket(ctx, ep)
+	}
+	req, err := newEndpointRequest(ctx, ep, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := ep.httpClient(client).Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, err
+	}
+	resp.Body.Close()
+
+	return latency, policy.CheckStatus(resp.StatusCode)
+}
@@ -0,0 +1,206 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseWaitTarget(t *testing.T) {
+	tests := []struct {
+		arg     string
+		want    WaitTarget
+		wantErr bool
+	}{
+		{"3000", WaitTarget{Name: "localhost:3000", Host: "localhost", Port: 3000}, false},
+		{":8080", WaitTarget{Name: "localhost:8080", Host: "localhost", Port: 8080}, false},
+		{"db:5432", WaitTarget{Name: "db:5432", Host: "db", Port: 5432}, false},
+		{"http://localhost:3000/health", WaitTarget{Name: "http://localhost:3000/health", URL: "http://localhost:3000/health"}, false},
+		{"70000", WaitTarget{}, true},
+		{"web", WaitTarget{}, true},
+		{"", WaitTarget{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseWaitTarget(tt.arg, "localhost")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseWaitTarget(%q) error = %v, wantErr %v", tt.arg, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseWaitTarget(%q) = %+v, want %+v", tt.arg, got, tt.want)
+		}
+	}
+}
+
+func TestWaitFor_BecomesHealthy(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	target, _ := ParseWaitTarget(srv.URL, "localhost")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var notified atomic.Int32
+	results := WaitFor(ctx, []WaitTarget{target},
+		WithWaitInterval(10*time.Millisecond),
+		WithOnReady(func(WaitResult) { notified.Add(1) }),
+	)
+
+	if !results[0].Ready {
+		t.Fatalf("result = %+v, want ready", results[0])
+	}
+	if results[0].Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", results[0].Attempts)
+	}
+	if notified.Load() != 1 {
+		t.Errorf("onReady called %d times, want 1", notified.Load())
+	}
+}
+
+func TestWaitFor_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+
+	closedTarget, _ := ParseWaitTarget(strconv.Itoa(port), "127.0.0.1")
+	openTarget, _ := ParseWaitTarget(open.Addr().String(), "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	results := WaitFor(ctx, []WaitTarget{closedTarget, openTarget}, WithWaitInterval(20*time.Millisecond))
+
+	if results[0].Ready {
+		t.Error("closed port reported ready")
+	}
+	if results[0].Status != StatusDown || results[0].LastError == "" {
+		t.Errorf("closed port result = %+v, want down with an error", results[0])
+	}
+	if !results[1].Ready {
+		t.Errorf("open port result = %+v, want ready", results[1])
+	}
+}
+
+func TestWaitFor_SlowResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(600 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	target, _ := ParseWaitTarget(srv.URL, "localhost")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	results := WaitFor(ctx, []WaitTarget{target}, WithAllowSlow(true))
+	if !results[0].Ready || results[0].Status != StatusSlow {
+		t.Errorf("result = %+v, want ready while slow", results[0])
+	}
+}
+
+func TestWaitFor_EndpointPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/report":
+			time.Sleep(600 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	target := func(path string, policy CheckPolicy) WaitTarget {
+		wt, _ := ParseWaitTarget(srv.URL+path, "localhost")
+		wt.Endpoint, _ = NewEndpoint(wt.URL)
+		wt.Endpoint.Policy = policy
+		return wt
+	}
+	accept404, _ := ParseStatusRanges("404")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	results := WaitFor(ctx, []WaitTarget{
+		target("/missing", CheckPolicy{}),
+		target("/missing", CheckPolicy{Accept: accept404}),
+		target("/report", CheckPolicy{SlowThreshold: 2 * time.Second}),
+	}, WithWaitInterval(50*time.Millisecond))
+
+	if r := results[0]; r.Ready || r.LastError != "status 404, expected 200-399" {
+		t.Errorf("404 with default policy = %+v, want not ready", r)
+	}
+	if !results[1].Ready {
+		t.Errorf("404 with accept_status 404 = %+v, want ready", results[1])
+	}
+	if r := results[2]; !r.Ready || r.Status != StatusHealthy {
+		t.Errorf("slow report with a 2s threshold = %+v, want ready and healthy", r)
+	}
+}
+
+func TestWaitFor_EndpointTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	trusted, _ := ParseWaitTarget(srv.URL, "localhost")
+	trusted.Endpoint, _ = NewEndpoint(srv.URL)
+	if err := trusted.Endpoint.SetTLS(TLSOptions{CAFile: writeServerCA(t, srv)}); err != nil {
+		t.Fatal(err)
+	}
+	insecure, _ := ParseWaitTarget(srv.URL+"/insecure", "localhost")
+	insecure.Endpoint, _ = NewEndpoint(insecure.URL)
+	insecure.Endpoint.SetTLS(TLSOptions{Insecure: true})
+	untrusted, _ := ParseWaitTarget(srv.URL+"/untrusted", "localhost")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	results := WaitFor(ctx, []WaitTarget{trusted, insecure, untrusted}, WithWaitInterval(50*time.Millisecond))
+	if !results[0].Ready || !results[1].Ready {
+		t.Errorf("per-target TLS results = %+v, %+v, want both ready", results[0], results[1])
+	}
+	if results[2].Ready || !strings.Contains(results[2].LastError, "certificate") {
+		t.Errorf("untrusted result = %+v, want a certificate error", results[2])
+	}
+}
+
+func TestWaitFor_EndpointRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	target, _ := ParseWaitTarget(srv.URL, "localhost")
+	target.Endpoint, _ = NewEndpoint(srv.URL)
+	target.Endpoint.Request = &RequestTemplate{Method: "POST", URL: srv.URL, Body: "{}"}
+	target.Endpoint.Auth = &Auth{Type: AuthBearer, Token: "tok"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if r := WaitFor(ctx, []WaitTarget{target})[0]; !r.Ready || r.Attempts != 1 {
+		t.Errorf("WaitFor() = %+v, want ready with the endpoint's method and auth", r)
+	}
+}
@@ -0,0 +1,318 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+type WebhookEventKind string
+
+const (
+	WebhookStatus WebhookEventKind = "status"
+	WebhookAlert  WebhookEventKind = "alert"
+)
+
+type WebhookEvent struct {
+	Kind      WebhookEventKind
+	Endpoint  string
+	Name      string
+	OldStatus EndpointStatus
+	NewStatus EndpointStatus
+	Rule      string
+	State     string
+	Message   string
+	Stats     Stats
+	Time      time.Time
+}
+
+func StatusChangeEvent(ep *Endpoint, old EndpointStatus, stats Stats) WebhookEvent {
+	return WebhookEvent{
+		Kind:      WebhookStatus,
+		Endpoint:  ep.URL,
+		Name:      ep.Name,
+		OldStatus: old,
+		NewStatus: ep.Status,
+		Message:   fmt.Sprintf("%s is %s (was %s)", describeEndpoint(ep), ep.Status, old),
+		Stats:     stats,
+		Time:      time.Now(),
+	}
+}
+
+func AlertWebhookEvent(a AlertEvent, stats Stats) WebhookEvent {
+	return WebhookEvent{
+		Kind:      WebhookAlert,
+		Endpoint:  a.URL,
+		Name:      a.Name,
+		OldStatus: a.Previous,
+		NewStatus: a.Status,
+		Rule:      a.Rule,
+		State:     a.State(),
+		Message:   a.Message,
+		Stats:     stats,
+		Time:      a.Time,
+	}
+}
+
+type Webhook struct {
+	URL      string
+	Format   string
+	Template string
+	Headers  map[string]string
+	Events   []WebhookEventKind
+	Retries  int
+}
+
+func (w Webhook) wants(kind WebhookEventKind) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, k := range w.Events {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+const jsonWebhookTemplate = `{"event":{{json .Kind}},"endpoint":{{json .Endpoint}},"name":{{json .Name}},` +
+	`"old_status":{{json .OldStatus}},"new_status":{{json .NewStatus}},"rule":{{json .Rule}},"state":{{json .State}},` +
+	`"message":{{json .Message}},"timestamp":{{json (rfc3339 .Time)}},"stats":{` +
+	`"requests":{{.Stats.TotalRequests}},"errors":{{.Stats.TotalErrors}},"error_rate":{{json .Stats.ErrorRate}},` +
+	`"throughput":{{json .Stats.Throughput}},"avg_ms":{{ms .Stats.AvgLatency}},"p50_ms":{{ms .Stats.P50}},` +
+	`"p95_ms":{{ms .Stats.P95}},"p99_ms":{{ms .Stats.P99}}}}`
+
+const slackWebhookTemplate = `{"text":{{json (printf "%s %s" (icon .NewStatus) .Message)}},"blocks":[` +
+	`{"type":"section","text":{"type":"mrkdwn","text":{{json (printf "%s *%s*\n%s" (icon .NewStatus) (or .Name .Endpoint) .Message)}}}},` +
+	`{"type":"context","elements":[{"type":"mrkdwn","text":{{json (printf "%s → %s · avg %sms · p95 %sms · %.1f%% errors · %s" .OldStatus .NewStatus (ms .Stats.AvgLatency) (ms .Stats.P95) .Stats.ErrorRate (rfc3339 .Time))}}}]}]}`
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"ms": func(d time.Duration) string {
+		return fmt.Sprintf("%.1f", float64(d.Microseconds())/1000)
+	},
+	"rfc3339": func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	},
+	"icon": func(s EndpointStatus) string {
+		ep := Endpoint{Status: s}
+		return ep.StatusIcon()
+	},
+}
+
+func ParseWebhookTemplate(format, text string) (*template.Template, error) {
+	switch format {
+	case "", "json":
+		text = jsonWebhookTemplate
+	case "slack":
+		text = slackWebhookTemplate
+	case "custom":
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("custom format needs a template")
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return template.New("webhook").Funcs(webhookFuncs).Option("missingkey=error").Parse(text)
+}
+
+type webhookTarget struct {
+	hook Webhook
+	tmpl *template.Template
+}
+
+type webhookDelivery struct {
+	target *webhookTarget
+	body   []byte
+}
+
+type WebhookSender struct {
+	mu sync.Mutex
+
+	targets  []*webhookTarget
+	client   *http.Client
+	backoff  time.Duration
+	queue    chan webhookDelivery
+	failures []string
+	closed   bool
+
+	done chan struct{}
+}
+
+type WebhookOption func(*WebhookSender)
+
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(s *WebhookSender) {
+		s.client = client
+	}
+}
+
+func WithWebhookBackoff(backoff time.Duration) WebhookOption {
+	return func(s *WebhookSender) {
+		s.backoff = backoff
+	}
+}
+
+func NewWebhookSender(hooks []Webhook, opts ...WebhookOption) (*WebhookSender, error) {
+	s := &WebhookSender{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		backoff: time.Second,
+		queue:   make(chan webhookDelivery, 100),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	for _, hook := range hooks {
+		tmpl, err := ParseWebhookTemplate(hook.Format, hook.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", hook.URL, err)
+		}
+		s.targets = append(s.targets, &webhookTarget{hook: hook, tmpl: tmpl})
+	}
+
+	go s.deliver()
+	return s, nil
+}
+
+func (s *WebhookSender) Send(event WebhookEvent) {
+	for _, t := range s.targets {
+		if !t.hook.wants(event.Kind) {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := t.tmpl.Execute(&buf, event); err != nil {
+			s.fail(t.hook.URL, err)
+			continue
+		}
+
+		if !s.enqueue(webhookDelivery{target: t, body: buf.Bytes()}) {
+			s.fail(t.hook.URL, fmt.Errorf("queue full or closed, dropped %s event", event.Kind))
+		}
+	}
+}
+
+func (s *WebhookSender) enqueue(d webhookDelivery) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	select {
+	case s.queue <- d:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *WebhookSender) deliver() {
+	defer close(s.done)
+
+	for d := range s.queue {
+		if err := s.post(d); err != nil {
+			s.fail(d.target.hook.URL, err)
+		}
+	}
+}
+
+func (s *WebhookSender) post(d webhookDelivery) error {
+	attempts := d.target.hook.Retries + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := s.backoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		retry, err := s.postOnce(d)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == attempts {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	if attempts > 1 {
+		return fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
+	}
+	return lastErr
+}
+
+func (s *WebhookSender) postOnce(d webhookDelivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.client.Timeout+time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.target.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LocalPulse/1.0")
+	for k, v := range d.target.hook.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("HTTP %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+}
+
+func (s *WebhookSender) fail(url string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, url+": "+err.Error())
+}
+
+func (s *WebhookSender) DrainFailures() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.failures
+	s.failures = nil
+	return failures
+}
+
+func (s *WebhookSender) Close(timeout time.Duration) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("%d webhook deliveries still pending", len(s.queue))
+	}
+}
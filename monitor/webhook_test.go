@@ -0,0 +1,205 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type webhookRecorder struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	statuses []int
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) (*httptest.Server, *webhookRecorder) {
+	t.Helper()
+	rec := &webhookRecorder{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.bodies = append(rec.bodies, string(body))
+		rec.headers = append(rec.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(rec.statuses) > 0 {
+			status = rec.statuses[0]
+			rec.statuses = rec.statuses[1:]
+		}
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, rec
+}
+
+func (r *webhookRecorder) requests() ([]string, []http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies, r.headers
+}
+
+func sendWebhook(t *testing.T, hooks []Webhook, event WebhookEvent) *WebhookSender {
+	t.Helper()
+	s, err := NewWebhookSender(hooks, WithWebhookBackoff(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewWebhookSender() error = %v", err)
+	}
+	s.Send(event)
+	if err := s.Close(5 * time.Second); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return s
+}
+
+func testStatusEvent() WebhookEvent {
+	ep := &Endpoint{URL: "http://localhost:3000/health", Name: "api", Status: StatusDown}
+	event := StatusChangeEvent(ep, StatusHealthy, Stats{TotalRequests: 10, TotalErrors: 2, ErrorRate: 20, P95: 150 * time.Millisecond})
+	event.Time = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return event
+}
+
+func TestWebhookSender_JSONPayload(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	sendWebhook(t, []Webhook{{URL: srv.URL}}, testStatusEvent())
+
+	bodies, headers := rec.requests()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d requests, want 1", len(bodies))
+	}
+	if ct := headers[0].Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var payload struct {
+		Event     string `json:"event"`
+		Endpoint  string `json:"endpoint"`
+		OldStatus string `json:"old_status"`
+		NewStatus string `json:"new_status"`
+		Timestamp string `json:"timestamp"`
+		Stats     struct {
+			Requests int     `json:"requests"`
+			Errors   int     `json:"errors"`
+			P95      float64 `json:"p95_ms"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil {
+		t.Fatalf("payload is not valid JSON: %v\n%s", err, bodies[0])
+	}
+	if payload.Event != "status" || payload.Endpoint != "http://localhost:3000/health" {
+		t.Errorf("event/endpoint = %q/%q", payload.Event, payload.Endpoint)
+	}
+	if payload.OldStatus != "healthy" || payload.NewStatus != "down" {
+		t.Errorf("old/new = %q/%q, want healthy/down", payload.OldStatus, payload.NewStatus)
+	}
+	if payload.Timestamp != "2024-01-01T12:00:00Z" {
+		t.Errorf("timestamp = %q", payload.Timestamp)
+	}
+	if payload.Stats.Requests != 10 || payload.Stats.Errors != 2 || payload.Stats.P95 != 150 {
+		t.Errorf("stats = %+v", payload.Stats)
+	}
+}
+
+func TestWebhookSender_SlackFormat(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	sendWebhook(t, []Webhook{{URL: srv.URL, Format: "slack"}}, testStatusEvent())
+
+	bodies, _ := rec.requests()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d requests, want 1", len(bodies))
+	}
+	var payload struct {
+		Text   string            `json:"text"`
+		Blocks []json.RawMessage `json:"blocks"`
+	}
+	if err := json.Unmarshal([]byte(bodies[0]), &payload); err != nil {
+		t.Fatalf("payload is not valid JSON: %v\n%s", err, bodies[0])
+	}
+	if !strings.Contains(payload.Text, "api is down") || len(payload.Blocks) != 2 {
+		t.Errorf("payload = %+v", payload)
+	}
+}
+
+func TestWebhookSender_CustomTemplateAndHeaders(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	hook := Webhook{
+		URL:      srv.URL,
+		Format:   "custom",
+		Template: `{"msg":{{json .Message}},"p95":{{ms .Stats.P95}}}`,
+		Headers:  map[string]string{"Authorization": "Bearer secret"},
+	}
+	sendWebhook(t, []Webhook{hook}, testStatusEvent())
+
+	bodies, headers := rec.requests()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d requests, want 1", len(bodies))
+	}
+	if want := `{"msg":"api is down (was healthy)","p95":150.0}`; bodies[0] != want {
+		t.Errorf("body = %s, want %s", bodies[0], want)
+	}
+	if auth := headers[0].Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+}
+
+func TestWebhookSender_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantFailed   bool
+	}{
+		{"recovers after 5xx", []int{503, 502, 200}, 3, false},
+		{"retries 429", []int{429, 200}, 2, false},
+		{"gives up", []int{500, 500, 500, 500}, 3, true},
+		{"no retry on 4xx", []int{400}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, rec := newWebhookServer(t, tt.statuses...)
+			s := sendWebhook(t, []Webhook{{URL: srv.URL, Retries: 2}}, testStatusEvent())
+
+			bodies, _ := rec.requests()
+			if len(bodies) != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", len(bodies), tt.wantRequests)
+			}
+			if failed := len(s.DrainFailures()) > 0; failed != tt.wantFailed {
+				t.Errorf("failed = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestWebhookSender_EventFilter(t *testing.T) {
+	srv, rec := newWebhookServer(t)
+	s, err := NewWebhookSender([]Webhook{{URL: srv.URL, Events: []WebhookEventKind{WebhookAlert}}})
+	if err != nil {
+		t.Fatalf("NewWebhookSender() error = %v", err)
+	}
+	s.Send(testStatusEvent())
+	s.Send(AlertWebhookEvent(AlertEvent{Rule: "api down", URL: "http://localhost:3000/", Status: StatusDown}, Stats{}))
+	s.Close(5 * time.Second)
+
+	bodies, _ := rec.requests()
+	if len(bodies) != 1 || !strings.Contains(bodies[0], `"rule":"api down"`) {
+		t.Errorf("bodies = %v, want only the alert event", bodies)
+	}
+}
+
+func TestParseWebhookTemplate_Errors(t *testing.T) {
+	if _, err := ParseWebhookTemplate("teams", ""); err == nil {
+		t.Error("unknown format: expected error")
+	}
+	if _, err := ParseWebhookTemplate("custom", "  "); err == nil {
+		t.Error("empty custom template: expected error")
+	}
+	if _, err := ParseWebhookTemplate("custom", "{{.Missing"); err == nil {
+		t.Error("malformed template: expected error")
+	}
+}
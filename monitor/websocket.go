@@ -0,0 +1,363 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessage    = 16 << 20
+	ProtoWebSocket  = "websocket"
+	wsCloseNormal   = 1000
+	wsCloseNoStatus = 1005
+)
+
+type WebSocketScript struct {
+	Messages []string
+	Match    string
+}
+
+func DefaultWebSocketScript() *WebSocketScript {
+	return &WebSocketScript{Messages: []string{"ping"}}
+}
+
+func (s *WebSocketScript) message(seq int64, vu int) (send, match string, err error) {
+	vars := map[string]string{"seq": strconv.FormatInt(seq, 10), "vu": strconv.Itoa(vu)}
+	if send, err = expandVars(s.Messages[int((seq-1)%int64(len(s.Messages)))], vars); err != nil {
+		return "", "", err
+	}
+	if match, err = expandVars(s.Match, vars); err != nil {
+		return "", "", err
+	}
+	return send, match, nil
+}
+
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("websocket closed by server: %d %s", e.Code, e.Reason)
+	}
+	return fmt.Sprintf("websocket closed by server: %d", e.Code)
+}
+
+type wsConn struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWebSocket(ctx context.Context, ep *Endpoint, vu int) (*wsConn, error) {
+	u, err := url.Parse(ep.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	var conn net.Conn
+	if u.Scheme == "wss" {
+		cfg := &tls.Config{}
+		if ep.tlsConfig != nil {
+			cfg = ep.tlsConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		cfg.NextProtos = []string{"http/1.1"}
+		conn, err = (&tls.Dialer{NetDialer: &dialer, Config: cfg}).DialContext(ctx, "tcp", u.Host)
+		u.Scheme = "https"
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", u.Host)
+		u.Scheme = "http"
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var nonce [16]byte
+	rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if ep.Request != nil {
+		headers, err := ep.Request.handshakeHeaders(ctx, vu)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		for name := range ep.Request.Headers {
+			req.Header[http.CanonicalHeaderKey(name)] = headers.Values(name)
+		}
+	}
+	req.Header.Set("User-Agent", "LocalPulse/1.0")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := ep.Auth.apply(ctx, req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		conn.Close()
+		return nil, fmt.Errorf("websocket handshake: status %d", resp.StatusCode)
+	}
+	sum := sha1.Sum([]byte(key + wsGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		conn.Close()
+		return nil, errors.New("websocket handshake: invalid Sec-WebSocket-Accept")
+	}
+
+	conn.SetDeadline(time.Time{})
+	return &wsConn{conn: conn, br: br}, nil
+}
+
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	header[1] |= 0x80
+
+	var mask [4]byte
+	rand.Read(mask[:])
+	frame := append(header, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+func (c *wsConn) readMessage() (byte, []byte, error) {
+	var opcode byte
+	var message []byte
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			closeErr := &WebSocketCloseError{Code: wsCloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.writeFrame(wsOpClose, payload[:min(len(payload), 2)])
+			return 0, nil, closeErr
+		case wsOpContinuation:
+			if opcode == 0 {
+				return 0, nil, errors.New("websocket: unexpected continuation frame")
+			}
+		default:
+			opcode = op
+		}
+		message = append(message, payload...)
+		if len(message) > wsMaxMessage {
+			return 0, nil, errors.New("websocket: message too large")
+		}
+		if fin {
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin, opcode := head[0]&0x80 != 0, head[0]&0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > wsMaxMessage {
+		return false, 0, nil, errors.New("websocket: frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *wsConn) close() {
+	c.conn.SetDeadline(time.Now().Add(time.Second))
+	c.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, wsCloseNormal))
+	c.conn.Close()
+}
+
+type wsSession struct {
+	endpoint *Endpoint
+	vu       int
+	timeout  time.Duration
+	conn     *wsConn
+	seq      int64
+}
+
+func (s *wsSession) exchange(ctx context.Context) RequestResult {
+	result := RequestResult{Timestamp: time.Now(), Protocol: ProtoWebSocket}
+	if s.conn == nil {
+		dialCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		start := time.Now()
+		conn, err := dialWebSocket(dialCtx, s.endpoint, s.vu)
+		cancel()
+		result.ConnectTime = time.Since(start)
+		if err != nil {
+			result.IsError = true
+			result.ErrorMessage = err.Error()
+			result.Latency = result.ConnectTime
+			return result
+		}
+		s.conn = conn
+	}
+
+	script := s.endpoint.WebSocket
+	if script == nil || len(script.Messages) == 0 {
+		script = DefaultWebSocketScript()
+	}
+	s.seq++
+	send, match, err := script.message(s.seq, s.vu)
+	if err != nil {
+		result.IsError = true
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	s.conn.conn.SetDeadline(start.Add(s.timeout))
+	conn := s.conn.conn
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+	if err := s.conn.writeFrame(wsOpText, []byte(send)); err != nil {
+		return s.fail(result, start, err)
+	}
+	for {
+		opcode, payload, err := s.conn.readMessage()
+		if err != nil {
+			return s.fail(result, start, err)
+		}
+		if (opcode == wsOpText || opcode == wsOpBinary) && strings.Contains(string(payload), match) {
+			result.Latency = time.Since(start)
+			result.Size = int64(len(payload))
+			return result
+		}
+	}
+}
+
+func (s *wsSession) fail(result RequestResult, start time.Time, err error) RequestResult {
+	result.IsError = true
+	result.Latency = time.Since(start)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		result.ErrorMessage = fmt.Sprintf("no matching reply within %s", s.timeout)
+		s.conn.conn.Close()
+	} else {
+		result.ErrorMessage = err.Error()
+		result.Disconnected = true
+		s.conn.conn.Close()
+	}
+	s.conn = nil
+	return result
+}
+
+func (s *wsSession) close() {
+	if s.conn != nil {
+		s.conn.close()
+		s.conn = nil
+	}
+}
+
+func checkWebSocket(ctx context.Context, ep *Endpoint) (time.Duration, error) {
+	start := time.Now()
+	conn, err := dialWebSocket(ctx, ep, 0)
+	latency := time.Since(start)
+	if err != nil {
+		return latency, err
+	}
+	conn.close()
+	return latency, nil
+}
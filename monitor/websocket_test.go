@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newWebSocketServer(t *testing.T, closeAfter int, reply func(msg string) []string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conns.Add(1)
+		sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsGUID))
+		conn, rw, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+		rw.Flush()
+
+		ws := &wsConn{conn: conn, br: rw.Reader}
+		write := func(opcode byte, payload string) {
+			frame := append([]byte{0x80 | opcode, byte(len(payload))}, payload...)
+			conn.Write(frame)
+		}
+		for n := 1; ; n++ {
+			opcode, payload, err := ws.readMessage()
+			if err != nil || opcode != wsOpText {
+				return
+			}
+			if closeAfter > 0 && n > closeAfter {
+				write(wsOpClose, string(binary.BigEndian.AppendUint16(nil, 1001))+"restart")
+				return
+			}
+			write(wsOpPing, "hb")
+			for _, msg := range reply(string(payload)) {
+				write(wsOpText, msg)
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &conns
+}
+
+func wsURL(srv *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func TestLoadTester_WebSocket(t *testing.T) {
+	srv, conns := newWebSocketServer(t, 0, func(msg string) []string {
+		return []string{`{"event":"tick"}`, msg}
+	})
+
+	ep, err := NewEndpoint(wsURL(srv) + "/ws")
+	if err != nil || !ep.IsWebSocket() || ep.IsHTTPS {
+		t.Fatalf("NewEndpoint() = %+v, %v", ep, err)
+	}
+	ep.WebSocket = &WebSocketScript{Messages: []string{`{"id":${seq},"vu":${vu}}`}, Match: `"id":${seq}`}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+	lt.Start()
+	lt.SendBurst(10)
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	stats := metrics.GetStats()
+	if stats.TotalRequests != 10 || stats.TotalErrors != 0 || stats.Disconnects != 0 {
+		t.Errorf("messages = %d, errors = %d, disconnects = %d, want 10 clean round trips", stats.TotalRequests, stats.TotalErrors, stats.Disconnects)
+	}
+	if stats.Connects < 1 || stats.Connects > 2 || int(conns.Load()) != int(stats.Connects) || stats.AvgConnect <= 0 {
+		t.Errorf("connects = %d (server saw %d), avg connect %v, want one connection per virtual user", stats.Connects, conns.Load(), stats.AvgConnect)
+	}
+	for _, r := range metrics.GetRecentResults(10) {
+		if r.Protocol != ProtoWebSocket || r.Latency <= 0 || r.Size == 0 {
+			t.Errorf("result = %+v, want a websocket round trip", r)
+		}
+	}
+}
+
+func TestLoadTester_WebSocketDisconnects(t *testing.T) {
+	srv, conns := newWebSocketServer(t, 2, func(msg string) []string { return []string{msg} })
+
+	ep, _ := NewEndpoint(wsURL(srv))
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(1))
+	lt.Start()
+	for i := 0; i < 6; i++ {
+		lt.SendRequest()
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(100 * time.Millisecond)
+	lt.Stop()
+
+	stats := metrics.GetStats()
+	if stats.TotalRequests != 6 || stats.Disconnects != 2 || stats.TotalErrors != 2 || conns.Load() != 2 {
+		t.Errorf("messages = %d, errors = %d, disconnects = %d, connections = %d, want 6, 2, 2 and 2",
+			stats.TotalRequests, stats.TotalErrors, stats.Disconnects, conns.Load())
+	}
+	var closed bool
+	for _, r := range metrics.GetRecentResults(6) {
+		closed = closed || strings.Contains(r.ErrorMessage, "websocket closed by server: 1001 restart")
+	}
+	if !closed {
+		t.Error("no result reports the server's close frame")
+	}
+}
+
+func TestLoadTester_WebSocketNoMatch(t *testing.T) {
+	srv, _ := newWebSocketServer(t, 0, func(msg string) []string { return []string{"unrelated"} })
+
+	ep, _ := NewEndpoint(wsURL(srv))
+	ep.Policy.Timeout = 100 * time.Millisecond
+	ep.WebSocket = &WebSocketScript{Messages: []string{"hello"}, Match: "hello"}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(1))
+	lt.Start()
+	lt.SendRequest()
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	results := metrics.GetRecentResults(1)
+	if len(results) != 1 || !results[0].IsError || results[0].Disconnected || !strings.Contains(results[0].ErrorMessage, "no matching reply within 100ms") {
+		t.Errorf("results = %+v, want a timeout waiting for the matching reply", results)
+	}
+}
+
+func TestCheckEndpoints_WebSocket(t *testing.T) {
+	srv, _ := newWebSocketServer(t, 0, func(msg string) []string { return nil })
+
+	ws, _ := NewEndpoint(wsURL(srv))
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	notWS, _ := NewEndpoint(wsURL(plain))
+	results := CheckEndpoints(context.Background(), []*Endpoint{ws, notWS})
+	if results[0].Status != StatusHealthy || results[0].StatusCodes[http.StatusSwitchingProtocols] != 1 || results[0].Last.Proto != ProtoWebSocket {
+		t.Errorf("CheckEndpoints(ws) = %+v, want a healthy upgrade", results[0])
+	}
+	if results[1].Status != StatusDown || !strings.Contains(strings.Join(results[1].Failures, ""), "handshake: status 200") {
+		t.Errorf("CheckEndpoints(no upgrade) = %+v, want down without an upgrade", results[1])
+	}
+}
+
+func TestWaitFor_WebSocket(t *testing.T) {
+	srv, conns := newWebSocketServer(t, 0, func(msg string) []string { return nil })
+
+	target, err := ParseWaitTarget(wsURL(srv)+"/ws", "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	results := WaitFor(ctx, []WaitTarget{target}, WithWaitInterval(10*time.Millisecond))
+	if r := results[0]; !r.Ready || r.Attempts != 1 || conns.Load() != 1 {
+		t.Errorf("WaitFor(ws) = %+v after %d handshakes, want ready on the first attempt", r, conns.Load())
+	}
+}
+
+func TestLoadTester_WebSocketHeaderTemplate(t *testing.T) {
+	upstream, _ := newWebSocketServer(t, 0, func(msg string) []string { return []string{msg} })
+	var mu sync.Mutex
+	var sessions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sessions = append(sessions, r.Header.Get("X-Session"))
+		mu.Unlock()
+		upstream.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	ep, _ := NewEndpoint(wsURL(srv))
+	feeder, _ := NewFeeder("users", []map[string]string{{"user": "ada b"}, {"user": "bob"}}, FeedUnique)
+	ep.Request = &RequestTemplate{URL: ep.URL, Headers: map[string]string{"X-Session": "${user}-${vu}"}, Feeder: feeder}
+	metrics := NewMetrics(100)
+	lt := NewLoadTester(ep, metrics, WithConcurrency(2))
+	lt.Start()
+	lt.SendBurst(4)
+	time.Sleep(300 * time.Millisecond)
+	lt.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	slices.Sort(sessions)
+	if stats := metrics.GetStats(); stats.TotalErrors != 0 || !slices.Equal(sessions, []string{"ada b-0", "bob-1"}) {
+		t.Errorf("handshake X-Session = %q with %d errors, want one expanded header per virtual user", sessions, stats.TotalErrors)
+	}
+}
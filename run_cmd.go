@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Brattlof/localpulse/app"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func runServerCommand(args []string, opts *cliOptions) int {
+	var port int
+	var path string
+	var noRestart bool
+
+	command, err := parseFlags("localpulse run", args, opts, func(fs *flag.FlagSet) {
+		fs.IntVar(&port, "port", 0, "")
+		fs.StringVar(&path, "path", "/", "")
+		fs.BoolVar(&noRestart, "no-restart", false, "")
+	})
+	if err != nil {
+		return usageError(err)
+	}
+	if len(command) == 0 {
+		return usageError(errors.New("run needs a command, e.g. localpulse run --port 3000 -- npm run dev"))
+	}
+	if port < 1 || port > 65535 {
+		return usageError(errors.New("run needs --port with the port the server listens on"))
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	cfg, ok := loadConfig(opts)
+	if !ok {
+		return 1
+	}
+
+	runner := monitor.NewServerRunner(command,
+		monitor.WithServerPort(cfg.Host, port),
+		monitor.WithAutoRestart(!noRestart),
+	)
+	if err := runner.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	url := "http://" + net.JoinHostPort(cfg.Host, strconv.Itoa(port)) + path
+	cleanup := func() {
+		if err := runner.Stop(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Could not stop %s: %v\n", runner.Command(), err)
+		}
+	}
+	if opts.plainMode() {
+		return runPlain(cfg, opts, cleanup, app.WithPlainServer(runner, url))
+	}
+	return runTUI(cfg, cleanup, app.WithServer(runner, url))
+}
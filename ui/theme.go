@@ -2,6 +2,7 @@ package ui
 
 import (
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -59,6 +60,21 @@ var (
 	}
 )
 
+func ThemeByName(name string) Theme {
+	switch strings.ToLower(name) {
+	case "dark":
+		return DarkTheme
+	case "light":
+		return LightTheme
+	default:
+		return DetectTheme()
+	}
+}
+
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
 func DetectTheme() Theme {
 	colorterm := os.Getenv("COLORTERM")
 	term := os.Getenv("TERM")
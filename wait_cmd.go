@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Brattlof/localpulse/app"
+	"github.com/Brattlof/localpulse/monitor"
+)
+
+func runWaitFor(args []string, opts *cliOptions) int {
+	var maxWait, interval time.Duration
+	var allowSlow, quiet bool
+
+	args, err := parseFlags("localpulse wait-for", args, opts, func(fs *flag.FlagSet) {
+		fs.DurationVar(&maxWait, "max-wait", time.Minute, "")
+		fs.DurationVar(&interval, "poll", 500*time.Millisecond, "")
+		fs.BoolVar(&allowSlow, "allow-slow", false, "")
+		fs.BoolVar(&quiet, "quiet", false, "")
+		fs.BoolVar(&quiet, "q", false, "")
+	})
+	if err != nil {
+		return usageError(err)
+	}
+
+	cfg, ok := loadConfig(opts)
+	if !ok {
+		return 1
+	}
+
+	var targets []monitor.WaitTarget
+	for _, arg := range args {
+		target, err := monitor.ParseWaitTarget(arg, cfg.Host)
+		if err != nil {
+			return usageError(err)
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		for _, ep := range cfg.GetEndpoints() {
+			parsed, err := monitor.NewEndpoint(ep.URL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid endpoint URL %q in config: %v\n", ep.URL, err)
+				return 2
+			}
+			target := monitor.WaitTarget{Name: parsed.URL, URL: parsed.URL}
+			if ep.Name != "" {
+				target.Name = ep.Name + " (" + target.URL + ")"
+			}
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return usageError(errors.New("nothing to wait for: pass URLs or ports, or configure endpoints"))
+	}
+	for i, target := range targets {
+		if target.URL == "" {
+			continue
+		}
+		ep, err := monitor.NewEndpoint(target.URL)
+		if err != nil {
+			return usageError(fmt.Errorf("invalid URL %q: %w", target.URL, err))
+		}
+		if errs := app.ApplyEndpoint(cfg, ep); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", target.URL, err)
+			}
+			return 1
+		}
+		targets[i].Endpoint = ep
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), maxWait)
+	defer cancel()
+
+	results := monitor.WaitFor(ctx, targets,
+		monitor.WithWaitInterval(interval),
+		monitor.WithRequestTimeout(time.Duration(cfg.Timeout)*time.Second),
+		monitor.WithAllowSlow(allowSlow),
+		monitor.WithOnReady(func(r monitor.WaitResult) {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "✓ %s ready after %s (%s, %s)\n",
+					r.Target.Name, r.ReadyAfter.Round(time.Millisecond), r.Status, r.Latency.Round(time.Millisecond))
+			}
+		}),
+	)
+
+	var failed []monitor.WaitResult
+	for _, r := range results {
+		if !r.Ready {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Timed out after %s; %d of %d targets never came up:\n", maxWait, len(failed), len(results))
+	for _, r := range failed {
+		detail := string(r.Status)
+		if r.LastError != "" {
+			detail += ": " + r.LastError
+		} else if r.Status == monitor.StatusSlow {
+			detail += ": last response took " + r.Latency.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(os.Stderr, "✗ %s %s (%d attempts)\n", r.Target.Name, detail, r.Attempts)
+	}
+	return 1
+}